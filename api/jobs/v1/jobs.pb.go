@@ -0,0 +1,1581 @@
+// gRPC mirror of the agent's job endpoints under /v1/monitor/jobs. Messages
+// carry the same fields as the JSON bodies in api/openapi.yaml, under the
+// same names; unset timestamps mean the JSON field is absent.
+//
+// Regenerate the Go code with `make proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.5.1-go
+// source: jobs/v1/jobs.proto
+
+package jobsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StartJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	Ports         []string               `protobuf:"bytes,2,rep,name=ports,proto3" json:"ports,omitempty"`                             // instead of port; PortChannels expand to their members
+	Direction     string                 `protobuf:"bytes,3,opt,name=direction,proto3" json:"direction,omitempty"`                     // ingress|egress|both
+	SpanMethod    string                 `protobuf:"bytes,4,opt,name=span_method,json=spanMethod,proto3" json:"span_method,omitempty"` // span|erspan
+	Vlan          *int32                 `protobuf:"varint,5,opt,name=vlan,proto3,oneof" json:"vlan,omitempty"`
+	Filters       *structpb.Struct       `protobuf:"bytes,6,opt,name=filters,proto3" json:"filters,omitempty"`
+	SampleRate    int32                  `protobuf:"varint,7,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+	DurationSec   int32                  `protobuf:"varint,8,opt,name=duration_sec,json=durationSec,proto3" json:"duration_sec,omitempty"`
+	OtlpExport    bool                   `protobuf:"varint,9,opt,name=otlp_export,json=otlpExport,proto3" json:"otlp_export,omitempty"`
+	ResultDetail  string                 `protobuf:"bytes,10,opt,name=result_detail,json=resultDetail,proto3" json:"result_detail,omitempty"` // summary|flows|pcaplike
+	AllowShared   bool                   `protobuf:"varint,11,opt,name=allow_shared,json=allowShared,proto3" json:"allow_shared,omitempty"`
+	Priority      int32                  `protobuf:"varint,12,opt,name=priority,proto3" json:"priority,omitempty"` // 0-100; higher preempts lower when the gate is full
+	MaxPackets    uint64                 `protobuf:"varint,13,opt,name=max_packets,json=maxPackets,proto3" json:"max_packets,omitempty"`
+	MaxBytes      uint64                 `protobuf:"varint,14,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	MaxCpuSeconds float64                `protobuf:"fixed64,15,opt,name=max_cpu_seconds,json=maxCpuSeconds,proto3" json:"max_cpu_seconds,omitempty"`
+	Notify        *Notify                `protobuf:"bytes,16,opt,name=notify,proto3" json:"notify,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,17,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Owner         string                 `protobuf:"bytes,18,opt,name=owner,proto3" json:"owner,omitempty"`
+	Description   string                 `protobuf:"bytes,19,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartJobRequest) Reset() {
+	*x = StartJobRequest{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartJobRequest) ProtoMessage() {}
+
+func (x *StartJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartJobRequest.ProtoReflect.Descriptor instead.
+func (*StartJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StartJobRequest) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *StartJobRequest) GetPorts() []string {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+func (x *StartJobRequest) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *StartJobRequest) GetSpanMethod() string {
+	if x != nil {
+		return x.SpanMethod
+	}
+	return ""
+}
+
+func (x *StartJobRequest) GetVlan() int32 {
+	if x != nil && x.Vlan != nil {
+		return *x.Vlan
+	}
+	return 0
+}
+
+func (x *StartJobRequest) GetFilters() *structpb.Struct {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *StartJobRequest) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *StartJobRequest) GetDurationSec() int32 {
+	if x != nil {
+		return x.DurationSec
+	}
+	return 0
+}
+
+func (x *StartJobRequest) GetOtlpExport() bool {
+	if x != nil {
+		return x.OtlpExport
+	}
+	return false
+}
+
+func (x *StartJobRequest) GetResultDetail() string {
+	if x != nil {
+		return x.ResultDetail
+	}
+	return ""
+}
+
+func (x *StartJobRequest) GetAllowShared() bool {
+	if x != nil {
+		return x.AllowShared
+	}
+	return false
+}
+
+func (x *StartJobRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *StartJobRequest) GetMaxPackets() uint64 {
+	if x != nil {
+		return x.MaxPackets
+	}
+	return 0
+}
+
+func (x *StartJobRequest) GetMaxBytes() uint64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *StartJobRequest) GetMaxCpuSeconds() float64 {
+	if x != nil {
+		return x.MaxCpuSeconds
+	}
+	return 0
+}
+
+func (x *StartJobRequest) GetNotify() *Notify {
+	if x != nil {
+		return x.Notify
+	}
+	return nil
+}
+
+func (x *StartJobRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *StartJobRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *StartJobRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type Notify struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WebhookUrl    string                 `protobuf:"bytes,1,opt,name=webhook_url,json=webhookUrl,proto3" json:"webhook_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Notify) Reset() {
+	*x = Notify{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Notify) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Notify) ProtoMessage() {}
+
+func (x *Notify) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Notify.ProtoReflect.Descriptor instead.
+func (*Notify) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Notify) GetWebhookUrl() string {
+	if x != nil {
+		return x.WebhookUrl
+	}
+	return ""
+}
+
+type StartJobResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	JobId          string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Status         string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Interface      string                 `protobuf:"bytes,3,opt,name=interface,proto3" json:"interface,omitempty"`
+	QueuePosition  int32                  `protobuf:"varint,4,opt,name=queue_position,json=queuePosition,proto3" json:"queue_position,omitempty"` // set when status is "queued"
+	PreemptedJobId string                 `protobuf:"bytes,5,opt,name=preempted_job_id,json=preemptedJobId,proto3" json:"preempted_job_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StartJobResponse) Reset() {
+	*x = StartJobResponse{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartJobResponse) ProtoMessage() {}
+
+func (x *StartJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartJobResponse.ProtoReflect.Descriptor instead.
+func (*StartJobResponse) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StartJobResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *StartJobResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *StartJobResponse) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *StartJobResponse) GetQueuePosition() int32 {
+	if x != nil {
+		return x.QueuePosition
+	}
+	return 0
+}
+
+func (x *StartJobResponse) GetPreemptedJobId() string {
+	if x != nil {
+		return x.PreemptedJobId
+	}
+	return ""
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type StopJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopJobRequest) Reset() {
+	*x = StopJobRequest{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopJobRequest) ProtoMessage() {}
+
+func (x *StopJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopJobRequest.ProtoReflect.Descriptor instead.
+func (*StopJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StopJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type StopJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopJobResponse) Reset() {
+	*x = StopJobResponse{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopJobResponse) ProtoMessage() {}
+
+func (x *StopJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopJobResponse.ProtoReflect.Descriptor instead.
+func (*StopJobResponse) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StopJobResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *StopJobResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type GetResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResultsRequest) Reset() {
+	*x = GetResultsRequest{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResultsRequest) ProtoMessage() {}
+
+func (x *GetResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResultsRequest.ProtoReflect.Descriptor instead.
+func (*GetResultsRequest) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetResultsRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type WatchJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchJobRequest) Reset() {
+	*x = WatchJobRequest{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchJobRequest) ProtoMessage() {}
+
+func (x *WatchJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchJobRequest.ProtoReflect.Descriptor instead.
+func (*WatchJobRequest) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WatchJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type ListJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	States        []string               `protobuf:"bytes,1,rep,name=states,proto3" json:"states,omitempty"`
+	Port          string                 `protobuf:"bytes,2,opt,name=port,proto3" json:"port,omitempty"`
+	Owner         string                 `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // all must match
+	Since         *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=since,proto3" json:"since,omitempty"`                                                                             // inclusive, on created_at
+	Until         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=until,proto3" json:"until,omitempty"`                                                                             // exclusive, on created_at
+	Limit         int32                  `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        string                 `protobuf:"bytes,8,opt,name=cursor,proto3" json:"cursor,omitempty"` // next_cursor of the previous page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListJobsRequest) GetStates() []string {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+func (x *ListJobsRequest) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *ListJobsRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ListJobsRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *ListJobsRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *ListJobsRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *ListJobsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListJobsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*JobStatus           `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListJobsResponse) GetJobs() []*JobStatus {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+func (x *ListJobsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type JobStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // queued|starting|running|stopping|done|failed
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Port          string                 `protobuf:"bytes,6,opt,name=port,proto3" json:"port,omitempty"`
+	Interface     string                 `protobuf:"bytes,7,opt,name=interface,proto3" json:"interface,omitempty"`
+	Ports         []string               `protobuf:"bytes,8,rep,name=ports,proto3" json:"ports,omitempty"`
+	Members       []*JobMember           `protobuf:"bytes,9,rep,name=members,proto3" json:"members,omitempty"`
+	QueuePosition int32                  `protobuf:"varint,10,opt,name=queue_position,json=queuePosition,proto3" json:"queue_position,omitempty"`
+	EndedAt       *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`
+	FailureReason string                 `protobuf:"bytes,12,opt,name=failure_reason,json=failureReason,proto3" json:"failure_reason,omitempty"`
+	StepErrors    map[string]string      `protobuf:"bytes,13,rep,name=step_errors,json=stepErrors,proto3" json:"step_errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // mirror|attach|collect|teardown
+	StopReason    string                 `protobuf:"bytes,14,opt,name=stop_reason,json=stopReason,proto3" json:"stop_reason,omitempty"`                                                                           // expired|stopped|budget_exceeded|shutdown|preempted
+	SampleRate    int32                  `protobuf:"varint,15,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+	Filters       *structpb.Struct       `protobuf:"bytes,16,opt,name=filters,proto3" json:"filters,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,17,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Owner         string                 `protobuf:"bytes,18,opt,name=owner,proto3" json:"owner,omitempty"`
+	Description   string                 `protobuf:"bytes,19,opt,name=description,proto3" json:"description,omitempty"`
+	Priority      int32                  `protobuf:"varint,20,opt,name=priority,proto3" json:"priority,omitempty"`
+	PreemptedBy   string                 `protobuf:"bytes,21,opt,name=preempted_by,json=preemptedBy,proto3" json:"preempted_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobStatus) Reset() {
+	*x = JobStatus{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobStatus) ProtoMessage() {}
+
+func (x *JobStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobStatus.ProtoReflect.Descriptor instead.
+func (*JobStatus) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *JobStatus) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *JobStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *JobStatus) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *JobStatus) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *JobStatus) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *JobStatus) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *JobStatus) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *JobStatus) GetPorts() []string {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+func (x *JobStatus) GetMembers() []*JobMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *JobStatus) GetQueuePosition() int32 {
+	if x != nil {
+		return x.QueuePosition
+	}
+	return 0
+}
+
+func (x *JobStatus) GetEndedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndedAt
+	}
+	return nil
+}
+
+func (x *JobStatus) GetFailureReason() string {
+	if x != nil {
+		return x.FailureReason
+	}
+	return ""
+}
+
+func (x *JobStatus) GetStepErrors() map[string]string {
+	if x != nil {
+		return x.StepErrors
+	}
+	return nil
+}
+
+func (x *JobStatus) GetStopReason() string {
+	if x != nil {
+		return x.StopReason
+	}
+	return ""
+}
+
+func (x *JobStatus) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *JobStatus) GetFilters() *structpb.Struct {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *JobStatus) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *JobStatus) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *JobStatus) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *JobStatus) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *JobStatus) GetPreemptedBy() string {
+	if x != nil {
+		return x.PreemptedBy
+	}
+	return ""
+}
+
+type JobMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	Interface     string                 `protobuf:"bytes,2,opt,name=interface,proto3" json:"interface,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobMember) Reset() {
+	*x = JobMember{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobMember) ProtoMessage() {}
+
+func (x *JobMember) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobMember.ProtoReflect.Descriptor instead.
+func (*JobMember) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *JobMember) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *JobMember) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+type JobResults struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	WindowSec          int32                  `protobuf:"varint,1,opt,name=window_sec,json=windowSec,proto3" json:"window_sec,omitempty"`
+	PacketsTotal       uint64                 `protobuf:"varint,2,opt,name=packets_total,json=packetsTotal,proto3" json:"packets_total,omitempty"`
+	BytesTotal         uint64                 `protobuf:"varint,3,opt,name=bytes_total,json=bytesTotal,proto3" json:"bytes_total,omitempty"`
+	Errors             map[string]uint64      `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	TopFlows           []*TopFlow             `protobuf:"bytes,5,rep,name=top_flows,json=topFlows,proto3" json:"top_flows,omitempty"`
+	LatencyHistogramNs *Histogram             `protobuf:"bytes,6,opt,name=latency_histogram_ns,json=latencyHistogramNs,proto3" json:"latency_histogram_ns,omitempty"`
+	OtelExport         *OTLPInfo              `protobuf:"bytes,7,opt,name=otel_export,json=otelExport,proto3" json:"otel_export,omitempty"`
+	StopReason         string                 `protobuf:"bytes,8,opt,name=stop_reason,json=stopReason,proto3" json:"stop_reason,omitempty"`
+	CpuSeconds         float64                `protobuf:"fixed64,9,opt,name=cpu_seconds,json=cpuSeconds,proto3" json:"cpu_seconds,omitempty"`
+	Members            []*MemberResults       `protobuf:"bytes,10,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *JobResults) Reset() {
+	*x = JobResults{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobResults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobResults) ProtoMessage() {}
+
+func (x *JobResults) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobResults.ProtoReflect.Descriptor instead.
+func (*JobResults) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *JobResults) GetWindowSec() int32 {
+	if x != nil {
+		return x.WindowSec
+	}
+	return 0
+}
+
+func (x *JobResults) GetPacketsTotal() uint64 {
+	if x != nil {
+		return x.PacketsTotal
+	}
+	return 0
+}
+
+func (x *JobResults) GetBytesTotal() uint64 {
+	if x != nil {
+		return x.BytesTotal
+	}
+	return 0
+}
+
+func (x *JobResults) GetErrors() map[string]uint64 {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *JobResults) GetTopFlows() []*TopFlow {
+	if x != nil {
+		return x.TopFlows
+	}
+	return nil
+}
+
+func (x *JobResults) GetLatencyHistogramNs() *Histogram {
+	if x != nil {
+		return x.LatencyHistogramNs
+	}
+	return nil
+}
+
+func (x *JobResults) GetOtelExport() *OTLPInfo {
+	if x != nil {
+		return x.OtelExport
+	}
+	return nil
+}
+
+func (x *JobResults) GetStopReason() string {
+	if x != nil {
+		return x.StopReason
+	}
+	return ""
+}
+
+func (x *JobResults) GetCpuSeconds() float64 {
+	if x != nil {
+		return x.CpuSeconds
+	}
+	return 0
+}
+
+func (x *JobResults) GetMembers() []*MemberResults {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+type MemberResults struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	Interface     string                 `protobuf:"bytes,2,opt,name=interface,proto3" json:"interface,omitempty"`
+	PacketsTotal  uint64                 `protobuf:"varint,3,opt,name=packets_total,json=packetsTotal,proto3" json:"packets_total,omitempty"`
+	BytesTotal    uint64                 `protobuf:"varint,4,opt,name=bytes_total,json=bytesTotal,proto3" json:"bytes_total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemberResults) Reset() {
+	*x = MemberResults{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemberResults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemberResults) ProtoMessage() {}
+
+func (x *MemberResults) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemberResults.ProtoReflect.Descriptor instead.
+func (*MemberResults) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MemberResults) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *MemberResults) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *MemberResults) GetPacketsTotal() uint64 {
+	if x != nil {
+		return x.PacketsTotal
+	}
+	return 0
+}
+
+func (x *MemberResults) GetBytesTotal() uint64 {
+	if x != nil {
+		return x.BytesTotal
+	}
+	return 0
+}
+
+type TopFlow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FiveTuple     string                 `protobuf:"bytes,1,opt,name=five_tuple,json=fiveTuple,proto3" json:"five_tuple,omitempty"` // "5tuple" in JSON
+	Pkts          uint64                 `protobuf:"varint,2,opt,name=pkts,proto3" json:"pkts,omitempty"`
+	Bytes         uint64                 `protobuf:"varint,3,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopFlow) Reset() {
+	*x = TopFlow{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopFlow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopFlow) ProtoMessage() {}
+
+func (x *TopFlow) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopFlow.ProtoReflect.Descriptor instead.
+func (*TopFlow) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TopFlow) GetFiveTuple() string {
+	if x != nil {
+		return x.FiveTuple
+	}
+	return ""
+}
+
+func (x *TopFlow) GetPkts() uint64 {
+	if x != nil {
+		return x.Pkts
+	}
+	return 0
+}
+
+func (x *TopFlow) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+type Histogram struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bounds        []uint64               `protobuf:"varint,1,rep,packed,name=bounds,proto3" json:"bounds,omitempty"`
+	Counts        []uint64               `protobuf:"varint,2,rep,packed,name=counts,proto3" json:"counts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Histogram) Reset() {
+	*x = Histogram{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Histogram) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Histogram) ProtoMessage() {}
+
+func (x *Histogram) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Histogram.ProtoReflect.Descriptor instead.
+func (*Histogram) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Histogram) GetBounds() []uint64 {
+	if x != nil {
+		return x.Bounds
+	}
+	return nil
+}
+
+func (x *Histogram) GetCounts() []uint64 {
+	if x != nil {
+		return x.Counts
+	}
+	return nil
+}
+
+type OTLPInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Exported      bool                   `protobuf:"varint,1,opt,name=exported,proto3" json:"exported,omitempty"`
+	Endpoint      string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OTLPInfo) Reset() {
+	*x = OTLPInfo{}
+	mi := &file_jobs_v1_jobs_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OTLPInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OTLPInfo) ProtoMessage() {}
+
+func (x *OTLPInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_jobs_v1_jobs_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OTLPInfo.ProtoReflect.Descriptor instead.
+func (*OTLPInfo) Descriptor() ([]byte, []int) {
+	return file_jobs_v1_jobs_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *OTLPInfo) GetExported() bool {
+	if x != nil {
+		return x.Exported
+	}
+	return false
+}
+
+func (x *OTLPInfo) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+var File_jobs_v1_jobs_proto protoreflect.FileDescriptor
+
+const file_jobs_v1_jobs_proto_rawDesc = "" +
+	"\n" +
+	"\x12jobs/v1/jobs.proto\x12\x15telegen.sonic.jobs.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf4\x05\n" +
+	"\x0fStartJobRequest\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12\x14\n" +
+	"\x05ports\x18\x02 \x03(\tR\x05ports\x12\x1c\n" +
+	"\tdirection\x18\x03 \x01(\tR\tdirection\x12\x1f\n" +
+	"\vspan_method\x18\x04 \x01(\tR\n" +
+	"spanMethod\x12\x17\n" +
+	"\x04vlan\x18\x05 \x01(\x05H\x00R\x04vlan\x88\x01\x01\x121\n" +
+	"\afilters\x18\x06 \x01(\v2\x17.google.protobuf.StructR\afilters\x12\x1f\n" +
+	"\vsample_rate\x18\a \x01(\x05R\n" +
+	"sampleRate\x12!\n" +
+	"\fduration_sec\x18\b \x01(\x05R\vdurationSec\x12\x1f\n" +
+	"\votlp_export\x18\t \x01(\bR\n" +
+	"otlpExport\x12#\n" +
+	"\rresult_detail\x18\n" +
+	" \x01(\tR\fresultDetail\x12!\n" +
+	"\fallow_shared\x18\v \x01(\bR\vallowShared\x12\x1a\n" +
+	"\bpriority\x18\f \x01(\x05R\bpriority\x12\x1f\n" +
+	"\vmax_packets\x18\r \x01(\x04R\n" +
+	"maxPackets\x12\x1b\n" +
+	"\tmax_bytes\x18\x0e \x01(\x04R\bmaxBytes\x12&\n" +
+	"\x0fmax_cpu_seconds\x18\x0f \x01(\x01R\rmaxCpuSeconds\x125\n" +
+	"\x06notify\x18\x10 \x01(\v2\x1d.telegen.sonic.jobs.v1.NotifyR\x06notify\x12J\n" +
+	"\x06labels\x18\x11 \x03(\v22.telegen.sonic.jobs.v1.StartJobRequest.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05owner\x18\x12 \x01(\tR\x05owner\x12 \n" +
+	"\vdescription\x18\x13 \x01(\tR\vdescription\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\a\n" +
+	"\x05_vlan\")\n" +
+	"\x06Notify\x12\x1f\n" +
+	"\vwebhook_url\x18\x01 \x01(\tR\n" +
+	"webhookUrl\"\xb0\x01\n" +
+	"\x10StartJobResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1c\n" +
+	"\tinterface\x18\x03 \x01(\tR\tinterface\x12%\n" +
+	"\x0equeue_position\x18\x04 \x01(\x05R\rqueuePosition\x12(\n" +
+	"\x10preempted_job_id\x18\x05 \x01(\tR\x0epreemptedJobId\"&\n" +
+	"\rGetJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"'\n" +
+	"\x0eStopJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"@\n" +
+	"\x0fStopJobResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"*\n" +
+	"\x11GetResultsRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"(\n" +
+	"\x0fWatchJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\xec\x02\n" +
+	"\x0fListJobsRequest\x12\x16\n" +
+	"\x06states\x18\x01 \x03(\tR\x06states\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\tR\x04port\x12\x14\n" +
+	"\x05owner\x18\x03 \x01(\tR\x05owner\x12J\n" +
+	"\x06labels\x18\x04 \x03(\v22.telegen.sonic.jobs.v1.ListJobsRequest.LabelsEntryR\x06labels\x120\n" +
+	"\x05since\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\x12\x14\n" +
+	"\x05limit\x18\a \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\b \x01(\tR\x06cursor\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"i\n" +
+	"\x10ListJobsResponse\x124\n" +
+	"\x04jobs\x18\x01 \x03(\v2 .telegen.sonic.jobs.v1.JobStatusR\x04jobs\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"\xf3\a\n" +
+	"\tJobStatus\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"started_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x12\n" +
+	"\x04port\x18\x06 \x01(\tR\x04port\x12\x1c\n" +
+	"\tinterface\x18\a \x01(\tR\tinterface\x12\x14\n" +
+	"\x05ports\x18\b \x03(\tR\x05ports\x12:\n" +
+	"\amembers\x18\t \x03(\v2 .telegen.sonic.jobs.v1.JobMemberR\amembers\x12%\n" +
+	"\x0equeue_position\x18\n" +
+	" \x01(\x05R\rqueuePosition\x125\n" +
+	"\bended_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\aendedAt\x12%\n" +
+	"\x0efailure_reason\x18\f \x01(\tR\rfailureReason\x12Q\n" +
+	"\vstep_errors\x18\r \x03(\v20.telegen.sonic.jobs.v1.JobStatus.StepErrorsEntryR\n" +
+	"stepErrors\x12\x1f\n" +
+	"\vstop_reason\x18\x0e \x01(\tR\n" +
+	"stopReason\x12\x1f\n" +
+	"\vsample_rate\x18\x0f \x01(\x05R\n" +
+	"sampleRate\x121\n" +
+	"\afilters\x18\x10 \x01(\v2\x17.google.protobuf.StructR\afilters\x12D\n" +
+	"\x06labels\x18\x11 \x03(\v2,.telegen.sonic.jobs.v1.JobStatus.LabelsEntryR\x06labels\x12\x14\n" +
+	"\x05owner\x18\x12 \x01(\tR\x05owner\x12 \n" +
+	"\vdescription\x18\x13 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bpriority\x18\x14 \x01(\x05R\bpriority\x12!\n" +
+	"\fpreempted_by\x18\x15 \x01(\tR\vpreemptedBy\x1a=\n" +
+	"\x0fStepErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"=\n" +
+	"\tJobMember\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12\x1c\n" +
+	"\tinterface\x18\x02 \x01(\tR\tinterface\"\xc8\x04\n" +
+	"\n" +
+	"JobResults\x12\x1d\n" +
+	"\n" +
+	"window_sec\x18\x01 \x01(\x05R\twindowSec\x12#\n" +
+	"\rpackets_total\x18\x02 \x01(\x04R\fpacketsTotal\x12\x1f\n" +
+	"\vbytes_total\x18\x03 \x01(\x04R\n" +
+	"bytesTotal\x12E\n" +
+	"\x06errors\x18\x04 \x03(\v2-.telegen.sonic.jobs.v1.JobResults.ErrorsEntryR\x06errors\x12;\n" +
+	"\ttop_flows\x18\x05 \x03(\v2\x1e.telegen.sonic.jobs.v1.TopFlowR\btopFlows\x12R\n" +
+	"\x14latency_histogram_ns\x18\x06 \x01(\v2 .telegen.sonic.jobs.v1.HistogramR\x12latencyHistogramNs\x12@\n" +
+	"\votel_export\x18\a \x01(\v2\x1f.telegen.sonic.jobs.v1.OTLPInfoR\n" +
+	"otelExport\x12\x1f\n" +
+	"\vstop_reason\x18\b \x01(\tR\n" +
+	"stopReason\x12\x1f\n" +
+	"\vcpu_seconds\x18\t \x01(\x01R\n" +
+	"cpuSeconds\x12>\n" +
+	"\amembers\x18\n" +
+	" \x03(\v2$.telegen.sonic.jobs.v1.MemberResultsR\amembers\x1a9\n" +
+	"\vErrorsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x01\"\x87\x01\n" +
+	"\rMemberResults\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12\x1c\n" +
+	"\tinterface\x18\x02 \x01(\tR\tinterface\x12#\n" +
+	"\rpackets_total\x18\x03 \x01(\x04R\fpacketsTotal\x12\x1f\n" +
+	"\vbytes_total\x18\x04 \x01(\x04R\n" +
+	"bytesTotal\"R\n" +
+	"\aTopFlow\x12\x1d\n" +
+	"\n" +
+	"five_tuple\x18\x01 \x01(\tR\tfiveTuple\x12\x12\n" +
+	"\x04pkts\x18\x02 \x01(\x04R\x04pkts\x12\x14\n" +
+	"\x05bytes\x18\x03 \x01(\x04R\x05bytes\";\n" +
+	"\tHistogram\x12\x16\n" +
+	"\x06bounds\x18\x01 \x03(\x04R\x06bounds\x12\x16\n" +
+	"\x06counts\x18\x02 \x03(\x04R\x06counts\"B\n" +
+	"\bOTLPInfo\x12\x1a\n" +
+	"\bexported\x18\x01 \x01(\bR\bexported\x12\x1a\n" +
+	"\bendpoint\x18\x02 \x01(\tR\bendpoint2\x9f\x04\n" +
+	"\x04Jobs\x12[\n" +
+	"\bStartJob\x12&.telegen.sonic.jobs.v1.StartJobRequest\x1a'.telegen.sonic.jobs.v1.StartJobResponse\x12P\n" +
+	"\x06GetJob\x12$.telegen.sonic.jobs.v1.GetJobRequest\x1a .telegen.sonic.jobs.v1.JobStatus\x12X\n" +
+	"\aStopJob\x12%.telegen.sonic.jobs.v1.StopJobRequest\x1a&.telegen.sonic.jobs.v1.StopJobResponse\x12Y\n" +
+	"\n" +
+	"GetResults\x12(.telegen.sonic.jobs.v1.GetResultsRequest\x1a!.telegen.sonic.jobs.v1.JobResults\x12[\n" +
+	"\bListJobs\x12&.telegen.sonic.jobs.v1.ListJobsRequest\x1a'.telegen.sonic.jobs.v1.ListJobsResponse\x12V\n" +
+	"\bWatchJob\x12&.telegen.sonic.jobs.v1.WatchJobRequest\x1a .telegen.sonic.jobs.v1.JobStatus0\x01B<Z:github.com/platformbuilds/telegen-sonic/api/jobs/v1;jobsv1b\x06proto3"
+
+var (
+	file_jobs_v1_jobs_proto_rawDescOnce sync.Once
+	file_jobs_v1_jobs_proto_rawDescData []byte
+)
+
+func file_jobs_v1_jobs_proto_rawDescGZIP() []byte {
+	file_jobs_v1_jobs_proto_rawDescOnce.Do(func() {
+		file_jobs_v1_jobs_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_jobs_v1_jobs_proto_rawDesc), len(file_jobs_v1_jobs_proto_rawDesc)))
+	})
+	return file_jobs_v1_jobs_proto_rawDescData
+}
+
+var file_jobs_v1_jobs_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_jobs_v1_jobs_proto_goTypes = []any{
+	(*StartJobRequest)(nil),       // 0: telegen.sonic.jobs.v1.StartJobRequest
+	(*Notify)(nil),                // 1: telegen.sonic.jobs.v1.Notify
+	(*StartJobResponse)(nil),      // 2: telegen.sonic.jobs.v1.StartJobResponse
+	(*GetJobRequest)(nil),         // 3: telegen.sonic.jobs.v1.GetJobRequest
+	(*StopJobRequest)(nil),        // 4: telegen.sonic.jobs.v1.StopJobRequest
+	(*StopJobResponse)(nil),       // 5: telegen.sonic.jobs.v1.StopJobResponse
+	(*GetResultsRequest)(nil),     // 6: telegen.sonic.jobs.v1.GetResultsRequest
+	(*WatchJobRequest)(nil),       // 7: telegen.sonic.jobs.v1.WatchJobRequest
+	(*ListJobsRequest)(nil),       // 8: telegen.sonic.jobs.v1.ListJobsRequest
+	(*ListJobsResponse)(nil),      // 9: telegen.sonic.jobs.v1.ListJobsResponse
+	(*JobStatus)(nil),             // 10: telegen.sonic.jobs.v1.JobStatus
+	(*JobMember)(nil),             // 11: telegen.sonic.jobs.v1.JobMember
+	(*JobResults)(nil),            // 12: telegen.sonic.jobs.v1.JobResults
+	(*MemberResults)(nil),         // 13: telegen.sonic.jobs.v1.MemberResults
+	(*TopFlow)(nil),               // 14: telegen.sonic.jobs.v1.TopFlow
+	(*Histogram)(nil),             // 15: telegen.sonic.jobs.v1.Histogram
+	(*OTLPInfo)(nil),              // 16: telegen.sonic.jobs.v1.OTLPInfo
+	nil,                           // 17: telegen.sonic.jobs.v1.StartJobRequest.LabelsEntry
+	nil,                           // 18: telegen.sonic.jobs.v1.ListJobsRequest.LabelsEntry
+	nil,                           // 19: telegen.sonic.jobs.v1.JobStatus.StepErrorsEntry
+	nil,                           // 20: telegen.sonic.jobs.v1.JobStatus.LabelsEntry
+	nil,                           // 21: telegen.sonic.jobs.v1.JobResults.ErrorsEntry
+	(*structpb.Struct)(nil),       // 22: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil), // 23: google.protobuf.Timestamp
+}
+var file_jobs_v1_jobs_proto_depIdxs = []int32{
+	22, // 0: telegen.sonic.jobs.v1.StartJobRequest.filters:type_name -> google.protobuf.Struct
+	1,  // 1: telegen.sonic.jobs.v1.StartJobRequest.notify:type_name -> telegen.sonic.jobs.v1.Notify
+	17, // 2: telegen.sonic.jobs.v1.StartJobRequest.labels:type_name -> telegen.sonic.jobs.v1.StartJobRequest.LabelsEntry
+	18, // 3: telegen.sonic.jobs.v1.ListJobsRequest.labels:type_name -> telegen.sonic.jobs.v1.ListJobsRequest.LabelsEntry
+	23, // 4: telegen.sonic.jobs.v1.ListJobsRequest.since:type_name -> google.protobuf.Timestamp
+	23, // 5: telegen.sonic.jobs.v1.ListJobsRequest.until:type_name -> google.protobuf.Timestamp
+	10, // 6: telegen.sonic.jobs.v1.ListJobsResponse.jobs:type_name -> telegen.sonic.jobs.v1.JobStatus
+	23, // 7: telegen.sonic.jobs.v1.JobStatus.created_at:type_name -> google.protobuf.Timestamp
+	23, // 8: telegen.sonic.jobs.v1.JobStatus.started_at:type_name -> google.protobuf.Timestamp
+	23, // 9: telegen.sonic.jobs.v1.JobStatus.expires_at:type_name -> google.protobuf.Timestamp
+	11, // 10: telegen.sonic.jobs.v1.JobStatus.members:type_name -> telegen.sonic.jobs.v1.JobMember
+	23, // 11: telegen.sonic.jobs.v1.JobStatus.ended_at:type_name -> google.protobuf.Timestamp
+	19, // 12: telegen.sonic.jobs.v1.JobStatus.step_errors:type_name -> telegen.sonic.jobs.v1.JobStatus.StepErrorsEntry
+	22, // 13: telegen.sonic.jobs.v1.JobStatus.filters:type_name -> google.protobuf.Struct
+	20, // 14: telegen.sonic.jobs.v1.JobStatus.labels:type_name -> telegen.sonic.jobs.v1.JobStatus.LabelsEntry
+	21, // 15: telegen.sonic.jobs.v1.JobResults.errors:type_name -> telegen.sonic.jobs.v1.JobResults.ErrorsEntry
+	14, // 16: telegen.sonic.jobs.v1.JobResults.top_flows:type_name -> telegen.sonic.jobs.v1.TopFlow
+	15, // 17: telegen.sonic.jobs.v1.JobResults.latency_histogram_ns:type_name -> telegen.sonic.jobs.v1.Histogram
+	16, // 18: telegen.sonic.jobs.v1.JobResults.otel_export:type_name -> telegen.sonic.jobs.v1.OTLPInfo
+	13, // 19: telegen.sonic.jobs.v1.JobResults.members:type_name -> telegen.sonic.jobs.v1.MemberResults
+	0,  // 20: telegen.sonic.jobs.v1.Jobs.StartJob:input_type -> telegen.sonic.jobs.v1.StartJobRequest
+	3,  // 21: telegen.sonic.jobs.v1.Jobs.GetJob:input_type -> telegen.sonic.jobs.v1.GetJobRequest
+	4,  // 22: telegen.sonic.jobs.v1.Jobs.StopJob:input_type -> telegen.sonic.jobs.v1.StopJobRequest
+	6,  // 23: telegen.sonic.jobs.v1.Jobs.GetResults:input_type -> telegen.sonic.jobs.v1.GetResultsRequest
+	8,  // 24: telegen.sonic.jobs.v1.Jobs.ListJobs:input_type -> telegen.sonic.jobs.v1.ListJobsRequest
+	7,  // 25: telegen.sonic.jobs.v1.Jobs.WatchJob:input_type -> telegen.sonic.jobs.v1.WatchJobRequest
+	2,  // 26: telegen.sonic.jobs.v1.Jobs.StartJob:output_type -> telegen.sonic.jobs.v1.StartJobResponse
+	10, // 27: telegen.sonic.jobs.v1.Jobs.GetJob:output_type -> telegen.sonic.jobs.v1.JobStatus
+	5,  // 28: telegen.sonic.jobs.v1.Jobs.StopJob:output_type -> telegen.sonic.jobs.v1.StopJobResponse
+	12, // 29: telegen.sonic.jobs.v1.Jobs.GetResults:output_type -> telegen.sonic.jobs.v1.JobResults
+	9,  // 30: telegen.sonic.jobs.v1.Jobs.ListJobs:output_type -> telegen.sonic.jobs.v1.ListJobsResponse
+	10, // 31: telegen.sonic.jobs.v1.Jobs.WatchJob:output_type -> telegen.sonic.jobs.v1.JobStatus
+	26, // [26:32] is the sub-list for method output_type
+	20, // [20:26] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_jobs_v1_jobs_proto_init() }
+func file_jobs_v1_jobs_proto_init() {
+	if File_jobs_v1_jobs_proto != nil {
+		return
+	}
+	file_jobs_v1_jobs_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_jobs_v1_jobs_proto_rawDesc), len(file_jobs_v1_jobs_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_jobs_v1_jobs_proto_goTypes,
+		DependencyIndexes: file_jobs_v1_jobs_proto_depIdxs,
+		MessageInfos:      file_jobs_v1_jobs_proto_msgTypes,
+	}.Build()
+	File_jobs_v1_jobs_proto = out.File
+	file_jobs_v1_jobs_proto_goTypes = nil
+	file_jobs_v1_jobs_proto_depIdxs = nil
+}
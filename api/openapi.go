@@ -0,0 +1,10 @@
+// Package api embeds the OpenAPI description of the agent's REST API, so
+// the agent can serve it and check its traffic against it.
+package api
+
+import _ "embed"
+
+// OpenAPI is openapi.yaml.
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
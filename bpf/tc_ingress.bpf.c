@@ -4,6 +4,9 @@
 // Features:
 // - Per-CPU global stats by protocol: IPv4, IPv6, ICMPv6, Other
 // - Per-CPU per-interface (ifindex) stats by protocol
+// - Per-interface 5-tuple flow stats (LRU) for per-job top flows
+// - Per-interface sampling and protocol/port filters for flow tracking,
+//   updated live by userspace through the if_config map
 // - VLAN-aware Ethernet parsing (802.1Q / 802.1ad)
 // - Safe bounds checks for verifier
 // - Attach as tc clsact/ingress
@@ -33,6 +36,12 @@
 #ifndef IPPROTO_ICMPV6
 #define IPPROTO_ICMPV6  58
 #endif
+#ifndef IPPROTO_TCP
+#define IPPROTO_TCP     6
+#endif
+#ifndef IPPROTO_UDP
+#define IPPROTO_UDP     17
+#endif
 #ifndef ETH_HLEN
 #define ETH_HLEN        14
 #endif
@@ -65,6 +74,31 @@ struct if_proto_key {
     __u32 proto;   /* one of IDX_* */
 };
 
+struct flow_key {
+    __u32 ifindex;
+    __u8  family;   /* 4 or 6 */
+    __u8  l4proto;  /* IPPROTO_* */
+    __u16 pad;
+    __u8  saddr[16];
+    __u8  daddr[16];
+    __u16 sport;    /* host byte order */
+    __u16 dport;    /* host byte order */
+};
+
+#define MAX_CFG_PORTS   4
+
+/* bits of if_config.proto_mask; 0 tracks every protocol */
+#define PROTO_BIT_TCP   (1 << 0)
+#define PROTO_BIT_UDP   (1 << 1)
+#define PROTO_BIT_ICMP  (1 << 2)
+
+struct if_config {
+    __u32 sample_rate;  /* track 1 in N packets; 0/1 tracks every packet */
+    __u32 proto_mask;   /* PROTO_BIT_* */
+    __u16 sports[MAX_CFG_PORTS];  /* 0 = unused slot */
+    __u16 dports[MAX_CFG_PORTS];
+};
+
 /* ---- Maps ---- */
 /* Per-CPU global proto stats */
 struct {
@@ -82,6 +116,22 @@ struct {
     __type(value, struct proto_stats);
 } if_stats_percpu SEC(".maps");
 
+/* Per-interface 5-tuple flow stats; LRU keeps the table bounded */
+struct {
+    __uint(type, BPF_MAP_TYPE_LRU_HASH);
+    __uint(max_entries, 16384);
+    __type(key, struct flow_key);
+    __type(value, struct proto_stats);
+} flow_stats SEC(".maps");
+
+/* Per-interface job parameters; interfaces without an entry track everything */
+struct {
+    __uint(type, BPF_MAP_TYPE_HASH);
+    __uint(max_entries, 1024);
+    __type(key, __u32);
+    __type(value, struct if_config);
+} if_config SEC(".maps");
+
 /* ---- Bump helpers ---- */
 static __always_inline void bump_global(__u32 idx, __u32 bytes)
 {
@@ -114,6 +164,106 @@ static __always_inline void bump_all(__u32 ifindex, __u32 idx, __u32 bytes)
         bump_if(ifindex, idx, bytes);
 }
 
+static __always_inline void bump_flow(struct flow_key *k, __u32 bytes)
+{
+    struct proto_stats *st = bpf_map_lookup_elem(&flow_stats, k);
+    if (!st) {
+        struct proto_stats init = { .packets = 1, .bytes = bytes };
+        bpf_map_update_elem(&flow_stats, k, &init, BPF_ANY);
+        return;
+    }
+    __sync_fetch_and_add(&st->packets, 1);
+    __sync_fetch_and_add(&st->bytes, bytes);
+}
+
+/* Fill L4 ports for TCP/UDP; l4 points at the transport header */
+static __always_inline void parse_ports(struct flow_key *k, void *l4, void *data_end)
+{
+    if (k->l4proto != IPPROTO_TCP && k->l4proto != IPPROTO_UDP)
+        return;
+    if ((char *)l4 + 4 > (char *)data_end)
+        return;
+    k->sport = bpf_ntohs(*(__be16 *)l4);
+    k->dport = bpf_ntohs(*(__be16 *)((char *)l4 + 2));
+}
+
+static __always_inline int port_listed(const __u16 *ports, __u16 port)
+{
+    int any = 0;
+
+#pragma clang loop unroll(full)
+    for (int i = 0; i < MAX_CFG_PORTS; i++) {
+        if (ports[i]) {
+            any = 1;
+            if (ports[i] == port)
+                return 1;
+        }
+    }
+    return !any; /* an empty list matches every port */
+}
+
+static __always_inline int proto_bit(__u8 l4proto)
+{
+    switch (l4proto) {
+    case IPPROTO_TCP:
+        return PROTO_BIT_TCP;
+    case IPPROTO_UDP:
+        return PROTO_BIT_UDP;
+    case 1:
+    case IPPROTO_ICMPV6:
+        return PROTO_BIT_ICMP;
+    }
+    return 0;
+}
+
+/* Apply the interface's sampling and filters; counters above are never sampled */
+static __always_inline int flow_wanted(const struct flow_key *k)
+{
+    struct if_config *cfg = bpf_map_lookup_elem(&if_config, &k->ifindex);
+
+    if (!cfg)
+        return 1;
+    if (cfg->proto_mask && !(cfg->proto_mask & proto_bit(k->l4proto)))
+        return 0;
+    if (!port_listed(cfg->sports, k->sport) || !port_listed(cfg->dports, k->dport))
+        return 0;
+    if (cfg->sample_rate > 1 && bpf_get_prandom_u32() % cfg->sample_rate)
+        return 0;
+    return 1;
+}
+
+static __always_inline void track_ipv4(__u32 ifindex, void *nh, void *data_end, __u32 bytes)
+{
+    struct flow_key k = {};
+    __u8 ihl = (*(__u8 *)nh) & 0x0f;
+
+    if (ihl < 5)
+        return;
+    k.ifindex = ifindex;
+    k.family = 4;
+    k.l4proto = *(__u8 *)((char *)nh + 9);
+    __builtin_memcpy(k.saddr, (char *)nh + 12, 4);
+    __builtin_memcpy(k.daddr, (char *)nh + 16, 4);
+    parse_ports(&k, (char *)nh + (ihl * 4), data_end);
+    if (flow_wanted(&k))
+        bump_flow(&k, bytes);
+}
+
+static __always_inline void track_ipv6(__u32 ifindex, void *nh, void *data_end, __u32 bytes)
+{
+    struct flow_key k = {};
+
+    k.ifindex = ifindex;
+    k.family = 6;
+    k.l4proto = *(__u8 *)((char *)nh + 6);
+    __builtin_memcpy(k.saddr, (char *)nh + 8, 16);
+    __builtin_memcpy(k.daddr, (char *)nh + 24, 16);
+    /* extension headers are not walked; ports only for a direct TCP/UDP nexthdr */
+    parse_ports(&k, (char *)nh + 40, data_end);
+    if (flow_wanted(&k))
+        bump_flow(&k, bytes);
+}
+
 /* ---- Parse Ethernet + VLAN, return L3 proto and next header pointer ---- */
 static __always_inline int parse_ethproto(void *data, void *data_end, __u16 *eth_proto, void **nh)
 {
@@ -169,6 +319,8 @@ int tc_ingress(struct __sk_buff *skb)
             return TC_ACT_OK;
         }
         bump_all(ifidx, IDX_IPV4, pkt_len);
+        if (ifidx)
+            track_ipv4(ifidx, nh, data_end, pkt_len);
         return TC_ACT_OK;
     }
 
@@ -179,6 +331,8 @@ int tc_ingress(struct __sk_buff *skb)
             return TC_ACT_OK;
         }
         bump_all(ifidx, IDX_IPV6, pkt_len);
+        if (ifidx)
+            track_ipv6(ifidx, nh, data_end, pkt_len);
 
         /* nexthdr field is byte 6 in IPv6 header */
         __u8 nexthdr = *(__u8 *)((char *)nh + 6);
@@ -4,12 +4,27 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"io/fs"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	apispec "github.com/platformbuilds/telegen-sonic/api"
 	"github.com/platformbuilds/telegen-sonic/pkg/api"
+	"github.com/platformbuilds/telegen-sonic/pkg/config"
 	"github.com/platformbuilds/telegen-sonic/pkg/monitor"
 )
 
@@ -20,40 +35,127 @@ var (
 )
 
 func main() {
+	cfgPath := flag.String("config", getenvDefault("TELEGEN_CONFIG", config.DefaultPath), "path to agent.yaml")
+	flag.Parse()
+
+	// deferred first so it runs after every other cleanup
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
+	cfg, err := config.Load(*cfgPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("config %s not found, using defaults", *cfgPath)
+	} else if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	// Certificates and the socket and gRPC listeners are set up front so a
+	// bad one stops the agent before it touches BPF maps or mirrors.
+	var certs *api.CertReloader
+	if cfg.Security.Auth == "mtls" {
+		certs, err = api.NewCertReloader(cfg.Security.CertFile, cfg.Security.KeyFile, cfg.Security.CAFile)
+		if err != nil {
+			log.Fatalf("tls: %v", err)
+		}
+	}
+	var sockLn net.Listener
+	if sock := cfg.Server.Socket; sock.Path != "" {
+		mode, _ := sock.FileMode() // checked by config.Validate
+		sockLn, err = api.ListenUnix(api.UnixSocket{
+			Path: sock.Path, Mode: mode, Group: sock.Group,
+			AllowedUIDs: sock.AllowedUIDs, AllowedGIDs: sock.AllowedGIDs,
+		})
+		if err != nil {
+			log.Fatalf("unix socket: %v", err)
+		}
+	}
+	var grpcLn net.Listener
+	if addr := cfg.Server.GRPCListen; addr != "" {
+		grpcLn, err = net.Listen("tcp", addr)
+		if err != nil {
+			log.Fatalf("grpc listen: %v", err)
+		}
+	}
+
 	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		endpoint = cfg.Export.OTLPEndpoint
+	}
 	if endpoint == "" {
 		endpoint = "localhost:4317"
 	}
+	// the gRPC exporter wants host:port
+	endpoint = strings.TrimPrefix(strings.TrimPrefix(endpoint, "http://"), "https://")
 
 	// 1) Set up OTel metrics
 	ctx := context.Background()
+	exports := &monitor.ExportTracker{}
+	otelOpts := []monitor.OTelOption{monitor.WithExportTracker(exports)}
+	if cfg.Export.DisableOTLP {
+		// /v1/agent then reports no exporter
+		otelOpts = []monitor.OTelOption{monitor.WithoutOTLP()}
+		exports, endpoint = nil, ""
+	}
+	var promReg *prometheus.Registry
+	if cfg.Export.Prometheus.Enabled {
+		promReg = prometheus.NewRegistry()
+		promReg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		otelOpts = append(otelOpts, monitor.WithPrometheus(promReg))
+	}
 	mp, meter, err := monitor.SetupOTelMetrics(
 		ctx,
 		"telegen-sonic", // service.name
 		endpoint,
-		false,          // insecure
-		10*time.Second, // export interval
+		false, // insecure
+		time.Duration(cfg.Export.IntervalSec)*time.Second, // export interval
+		otelOpts...,
 	)
 	if err != nil {
 		log.Fatalf("otel setup failed: %v", err)
 	}
+	// runs last so the final job metrics are flushed
 	defer func() {
 		shctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		_ = mp.Shutdown(shctx)
+		if err := mp.Shutdown(shctx); err != nil {
+			log.Printf("otel shutdown: %v", err)
+		}
 	}()
 
+	// SIGINT/SIGTERM stop the scheduler and GC, then drain jobs below.
+	sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// 2) Open pinned BPF maps (ok if missing; your loader may pin them later)
+	// Each open is recorded for /v1/agent and /readyz.
 	statsMap, ifStatsMap, err := monitor.OpenPinnedMaps(monitor.DefaultPinDir)
 	if err != nil {
 		log.Printf("warning: could not open pinned maps: %v", err)
 	}
+	maps := []monitor.MapStatus{{Name: "stats_percpu", Required: true, Err: err}}
+	if ifStatsMap == nil {
+		maps = append(maps, monitor.MapStatus{Name: "if_stats_percpu", Err: errors.New("not pinned")})
+	} else {
+		maps = append(maps, monitor.MapStatus{Name: "if_stats_percpu"})
+	}
 
 	// 3) Metrics collector (runs globally in this process)
 	mc, err := monitor.NewMetricsCollector(meter, statsMap, ifStatsMap, 5*time.Second)
 	if err != nil {
 		log.Fatalf("collector init failed: %v", err)
 	}
+	// Flow map feeds per-job top flows; results still work without it.
+	flowMap, err := monitor.OpenPinnedFlowMap(monitor.DefaultPinDir)
+	if err != nil {
+		log.Printf("warning: per-job top flows disabled: %v", err)
+	} else {
+		mc.SetFlowMap(flowMap)
+	}
+	maps = append(maps, monitor.MapStatus{Name: "flow_stats", Err: err})
 	// Start the collector in the background so this single binary does API + metrics
 	go func() {
 		if err := mc.Start(ctx); err != nil && ctx.Err() == nil {
@@ -61,22 +163,232 @@ func main() {
 		}
 	}()
 
+	// Kernel run-time accounting backs max_cpu_seconds budgets.
+	if stats, err := monitor.EnableRuntimeStats(); err != nil {
+		log.Printf("warning: jobs with max_cpu_seconds will be rejected: %v", err)
+	} else {
+		defer stats.Close()
+	}
+
 	// If Supervisor needs a Collector impl, wrap the already-running metrics collector.
 	col := monitor.NewBPFCollector(mc)
+	col.SetLabelAttributes(cfg.Export.JobLabelAttributes)
 
 	// 4) Your providers (replace with real implementations if different)
 	mir := &monitor.Mirror{} // implements MirrorProvider
 	att := &monitor.TC{}     // implements AttachProvider
+	// Config map carries per-job sampling/filters; without it every packet is tracked.
+	cfgMap, err := monitor.OpenPinnedConfigMap(monitor.DefaultPinDir)
+	if err != nil {
+		log.Printf("warning: sample_rate and filters will not reach the data plane: %v", err)
+	} else {
+		att.ConfigMap = cfgMap
+	}
+	maps = append(maps, monitor.MapStatus{Name: "if_config", Err: err})
 
 	// 5) Supervisor and API wiring
-	sup := monitor.NewSupervisor(mir, att, col, 2)
-	core := &monitor.CoreAdapter{S: sup}
+	var opts []monitor.Option
+	var schedStore monitor.ScheduleStore
+	if cfg.State.Dir != "" {
+		st, err := monitor.NewFileStore(cfg.State.Dir)
+		if err != nil {
+			log.Printf("warning: job history will not be persisted: %v", err)
+		} else {
+			opts = append(opts, monitor.WithStore(st))
+			schedStore = st
+		}
+	}
+	if cfg.Limits.MaxJobsQueue > 0 {
+		ttl := time.Duration(cfg.Limits.QueueTTLSec) * time.Second
+		opts = append(opts, monitor.WithQueue(cfg.Limits.MaxJobsQueue, ttl))
+	}
+	if cfg.Limits.MaxDurationSec > 0 {
+		opts = append(opts, monitor.WithMaxDuration(time.Duration(cfg.Limits.MaxDurationSec)*time.Second))
+	}
+	if cfg.State.RetentionSec > 0 {
+		opts = append(opts, monitor.WithRetention(time.Duration(cfg.State.RetentionSec)*time.Second))
+	}
+	// The webhook reads results back through the API adapter, which needs
+	// the supervisor, so it is bound once both exist.
+	hook := &monitor.Webhook{
+		Secret:      []byte(cfg.Notify.HMACSecret),
+		Client:      &http.Client{Timeout: time.Duration(cfg.Notify.TimeoutSec) * time.Second},
+		MaxAttempts: cfg.Notify.MaxAttempts,
+		Backoff:     time.Duration(cfg.Notify.BackoffSec) * time.Second,
+	}
+	opts = append(opts, monitor.WithNotifier(hook))
+	sup := monitor.NewSupervisor(mir, att, col, cfg.Limits.MaxConcurrentJobs, opts...)
+	core := &monitor.CoreAdapter{S: sup, OTLPEndpoint: endpoint}
+	hook.Results = core.GetResults
+	// Fail jobs interrupted by a previous crash and remove their leftovers.
+	if err := sup.Reconcile(); err != nil {
+		log.Printf("warning: job reconciliation failed: %v", err)
+	}
+	go sup.RunGC(sigCtx)
 
-	h := &api.Handlers{Core: core}
+	// Scheduled runs go through the same gate as API requests.
+	sched := monitor.NewScheduler(sup, schedStore)
+	if err := sched.Load(); err != nil {
+		log.Printf("warning: could not restore schedules: %v", err)
+	}
+	go sched.Run(sigCtx)
+
+	agent := &monitor.AgentAdapter{
+		Version: version, Commit: commit, Date: date,
+		PinDir: monitor.DefaultPinDir, Maps: maps, Collector: mc,
+		Export: exports, Endpoint: endpoint, S: sup,
+	}
+	if err := agent.RegisterMetrics(meter); err != nil {
+		log.Printf("warning: agent self-metrics disabled: %v", err)
+	}
+	h := &api.Handlers{
+		Core:      core,
+		Schedules: &monitor.ScheduleAdapter{S: sched},
+		Events:    &monitor.EventAdapter{B: sup.Events()},
+		Agent:     agent,
+	}
+	// Prometheus scrapes /metrics on the API listeners, or on its own.
+	var promSrv *http.Server
+	if promReg != nil {
+		metrics := promhttp.HandlerFor(promReg, promhttp.HandlerOpts{})
+		if l := cfg.Export.Prometheus.Listen; l == "" {
+			h.Metrics = metrics
+		} else {
+			mux := http.NewServeMux()
+			mux.Handle("GET /metrics", metrics)
+			promSrv = &http.Server{Addr: l, Handler: mux}
+		}
+	}
+	if rbac := cfg.Security.RBAC; rbac.Enabled {
+		h.Policy = policyFromConfig(rbac)
+	}
+	h.MaxBodyBytes = cfg.Server.MaxBodyBytes
+	h.Limiter = api.NewRateLimiter(map[string]api.RateLimit{
+		api.ClassRead:  {Rate: cfg.Server.RateLimit.Read.Rate, Burst: cfg.Server.RateLimit.Read.Burst},
+		api.ClassWrite: {Rate: cfg.Server.RateLimit.Write.Rate, Burst: cfg.Server.RateLimit.Write.Burst},
+	})
+	if cfg.Server.ValidateOpenAPI {
+		spec, err := api.LoadSpec(apispec.OpenAPI)
+		if err != nil {
+			log.Fatalf("openapi: %v", err)
+		}
+		h.Contract = spec
+	}
 	r := api.NewRouter(h)
 
-	log.Println("listening on 127.0.0.1:8080")
-	if err := http.ListenAndServe("127.0.0.1:8080", r); err != nil {
-		log.Fatal(err)
+	srv := &http.Server{Addr: cfg.Server.Listen, Handler: r, ConnContext: api.ConnContext}
+	if certs != nil {
+		srv.TLSConfig = certs.TLSConfig()
+		// picks up rotated certificates and CA bundles
+		go certs.Watch(sigCtx, 10*time.Second)
+	}
+	serveErr := make(chan error, 4)
+	if cfg.Server.Listen != "" {
+		go func() {
+			if certs != nil {
+				log.Printf("listening on %s (mtls)", cfg.Server.Listen)
+				serveErr <- srv.ListenAndServeTLS("", "")
+				return
+			}
+			log.Printf("listening on %s", cfg.Server.Listen)
+			serveErr <- srv.ListenAndServe()
+		}()
+	}
+	if sockLn != nil {
+		go func() {
+			log.Printf("listening on %s", cfg.Server.Socket.Path)
+			serveErr <- srv.Serve(sockLn)
+		}()
+	}
+
+	// The gRPC job API shares the handlers, and with them RBAC and limits.
+	var grpcSrv *grpc.Server
+	if grpcLn != nil {
+		var opts []grpc.ServerOption
+		if certs != nil {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(certs.TLSConfig())))
+		}
+		grpcSrv = api.NewGRPCServer(h, opts...)
+		go func() {
+			log.Printf("serving gRPC on %s", grpcLn.Addr())
+			serveErr <- grpcSrv.Serve(grpcLn)
+		}()
+	}
+	if promSrv != nil {
+		go func() {
+			log.Printf("serving prometheus metrics on %s", promSrv.Addr)
+			serveErr <- promSrv.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		// fall through to the drain so mirrors and tc filters are not leaked
+		log.Printf("http server: %v", err)
+		exitCode = 1
+	case <-sigCtx.Done():
+		log.Printf("shutting down")
+	}
+
+	// One budget covers job teardown and in-flight requests. Jobs go
+	// first so event streams see them finish; new jobs get 503 meanwhile.
+	shctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeoutSec)*time.Second)
+	defer cancel()
+	if err := sup.Shutdown(shctx); err != nil {
+		log.Printf("job drain incomplete: %v", err)
+	}
+	if err := srv.Shutdown(shctx); err != nil {
+		log.Printf("http shutdown: %v", err)
+	}
+	if grpcSrv != nil {
+		// watches end with their jobs, so this is quick once they drained
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shctx.Done():
+			grpcSrv.Stop()
+		}
+	}
+	if promSrv != nil {
+		if err := promSrv.Shutdown(shctx); err != nil {
+			log.Printf("metrics shutdown: %v", err)
+		}
+	}
+}
+
+func policyFromConfig(c config.RBAC) *api.Policy {
+	p := &api.Policy{
+		Subjects:    map[string]string{},
+		UIDs:        map[uint32]string{},
+		Tokens:      map[string]string{},
+		DefaultRole: c.DefaultRole,
+	}
+	for _, t := range c.Tokens {
+		p.Tokens[t.Token] = t.Name
+	}
+	// the first binding that names a caller wins
+	for _, b := range c.Bindings {
+		for _, s := range b.Subjects {
+			if _, ok := p.Subjects[s]; !ok {
+				p.Subjects[s] = b.Role
+			}
+		}
+		for _, uid := range b.UIDs {
+			if _, ok := p.UIDs[uint32(uid)]; !ok {
+				p.UIDs[uint32(uid)] = b.Role
+			}
+		}
+	}
+	return p
+}
+
+func getenvDefault(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
 	}
+	return def
 }
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/platformbuilds/telegen-sonic/pkg/api"
+	"github.com/platformbuilds/telegen-sonic/pkg/config"
 )
 
 // ---- test double for api.Core ----
@@ -32,6 +33,16 @@ type testCore struct {
 	resultsResp api.JobResults
 	resultsCode int
 	resultsErr  error
+
+	listResp api.ListJobsResponse
+	listCode int
+	listErr  error
+
+	updateResp api.JobStatus
+	updateCode int
+	updateErr  error
+
+	validateResp api.ValidateJobResponse
 }
 
 func (t *testCore) TryStartJob(req api.StartJobRequest) (api.StartJobResponse, int, error) {
@@ -41,6 +52,10 @@ func (t *testCore) TryStartJob(req api.StartJobRequest) (api.StartJobResponse, i
 	}
 	return t.startResp, t.startCode, t.startErr
 }
+func (t *testCore) ValidateJob(req api.StartJobRequest) (api.ValidateJobResponse, int, error) {
+	return t.validateResp, http.StatusOK, nil
+}
+
 func (t *testCore) GetJob(id string) (api.JobStatus, int, error) {
 	t.getCalled = true
 	if t.getCode == 0 {
@@ -63,6 +78,20 @@ func (t *testCore) GetResults(id string) (api.JobResults, int, error) {
 	return t.resultsResp, t.resultsCode, t.resultsErr
 }
 
+func (t *testCore) ListJobs(req api.ListJobsRequest) (api.ListJobsResponse, int, error) {
+	if t.listCode == 0 {
+		t.listCode = http.StatusOK
+	}
+	return t.listResp, t.listCode, t.listErr
+}
+
+func (t *testCore) UpdateJob(id string, req api.UpdateJobRequest) (api.JobStatus, int, error) {
+	if t.updateCode == 0 {
+		t.updateCode = http.StatusOK
+	}
+	return t.updateResp, t.updateCode, t.updateErr
+}
+
 // ---- tests ----
 
 func TestAgent_Router_Success(t *testing.T) {
@@ -85,7 +114,7 @@ func TestAgent_Router_Success(t *testing.T) {
 
 	// POST /v1/monitor/jobs
 	resp, err := http.Post(srv.URL+"/v1/monitor/jobs", "application/json",
-		bytes.NewBufferString(`{"port":"Ethernet0","direction":"ingress","duration_sec":5}`))
+		bytes.NewBufferString(`{"port":"Ethernet0","direction":"ingress","span_method":"erspan","duration_sec":5}`))
 	if err != nil {
 		t.Fatalf("post: %v", err)
 	}
@@ -167,3 +196,20 @@ func TestAgent_VersionVars(t *testing.T) {
 		t.Fatalf("version/commit should be set (got %q %q)", version, commit)
 	}
 }
+
+func TestPolicyFromConfig(t *testing.T) {
+	p := policyFromConfig(config.RBAC{
+		DefaultRole: "viewer",
+		Tokens:      []config.BearerToken{{Name: "ci-bot", Token: "s3cret"}},
+		Bindings: []config.RoleBinding{
+			{Role: "admin", UIDs: []int{0}, Subjects: []string{"netops-admin"}},
+			{Role: "operator", Subjects: []string{"ci-bot", "netops-admin"}},
+		},
+	})
+	if p.Tokens["s3cret"] != "ci-bot" || p.Subjects["ci-bot"] != "operator" || p.UIDs[0] != "admin" || p.DefaultRole != "viewer" {
+		t.Fatalf("unexpected policy: %+v", p)
+	}
+	if p.Subjects["netops-admin"] != "admin" {
+		t.Fatalf("the first binding naming a subject should win, got %q", p.Subjects["netops-admin"])
+	}
+}
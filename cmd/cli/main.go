@@ -2,9 +2,12 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 )
@@ -15,46 +18,71 @@ var (
 	date    = ""
 )
 
+// The agent's API; --socket switches to its Unix socket.
+var (
+	baseURL = "http://127.0.0.1:8080"
+	client  = http.DefaultClient
+)
+
 func usage() {
-	fmt.Println("telegen-sonic start|status|results|stop ...")
+	fmt.Println("telegen-sonic [--socket PATH] start|status|results|stop ...")
 	os.Exit(1)
 }
 
 func main() {
-	if len(os.Args) < 2 {
+	flags := flag.NewFlagSet("telegen-sonic", flag.ExitOnError)
+	socket := flags.String("socket", os.Getenv("TELEGEN_SOCKET"), "agent Unix socket (default: TCP 127.0.0.1:8080)")
+	_ = flags.Parse(os.Args[1:])
+	if *socket != "" {
+		useSocket(*socket)
+	}
+	args := flags.Args()
+	if len(args) < 1 {
 		usage()
 	}
-	switch os.Args[1] {
+	switch args[0] {
 	case "start":
 		// minimal: telegen-sonic start PORT DURATION_SEC
-		if len(os.Args) < 4 {
+		if len(args) < 3 {
 			usage()
 		}
 		req := map[string]interface{}{
-			"port": os.Args[2], "direction": "ingress", "span_method": "span",
-			"duration_sec": atoi(os.Args[3]), "sample_rate": 100, "otlp_export": true, "result_detail": "summary",
+			"port": args[1], "direction": "ingress", "span_method": "span",
+			"duration_sec": atoi(args[2]), "sample_rate": 100, "otlp_export": true, "result_detail": "summary",
 		}
 		call("POST", "/v1/monitor/jobs", req)
 	case "status":
-		if len(os.Args) < 3 {
+		if len(args) < 2 {
 			usage()
 		}
-		call("GET", "/v1/monitor/jobs/"+os.Args[2], nil)
+		call("GET", "/v1/monitor/jobs/"+args[1], nil)
 	case "results":
-		if len(os.Args) < 3 {
+		if len(args) < 2 {
 			usage()
 		}
-		call("GET", "/v1/monitor/jobs/"+os.Args[2]+"/results?format=json", nil)
+		call("GET", "/v1/monitor/jobs/"+args[1]+"/results?format=json", nil)
 	case "stop":
-		if len(os.Args) < 3 {
+		if len(args) < 2 {
 			usage()
 		}
-		call("DELETE", "/v1/monitor/jobs/"+os.Args[2], nil)
+		call("DELETE", "/v1/monitor/jobs/"+args[1], nil)
 	default:
 		usage()
 	}
 }
 
+// useSocket sends every request over the Unix socket at path; the host in
+// baseURL is then only a placeholder.
+func useSocket(path string) {
+	baseURL = "http://telegen-sonic"
+	client = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
 func atoi(s string) int {
 	var n int
 	fmt.Sscanf(s, "%d", &n)
@@ -67,12 +95,11 @@ func call(method, path string, body interface{}) {
 		b, _ := json.Marshal(body)
 		rd = bytes.NewReader(b)
 	}
-	url := "http://127.0.0.1:8080" + path
-	req, _ := http.NewRequest(method, url, rd)
+	req, _ := http.NewRequest(method, baseURL+path, rd)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		fmt.Println("ERR:", err)
 		os.Exit(2)
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -67,6 +68,12 @@ func runCLI(t *testing.T, args ...string) (exitCode int, stdout string, stderr s
 	srv := &http.Server{Handler: mux}
 	defer srv.Close()
 	go func() { _ = srv.Serve(ln) }()
+	return runChild(t, args...)
+}
+
+// runChild runs the CLI with args against whatever server the test set up.
+func runChild(t *testing.T, args ...string) (exitCode int, stdout string, stderr string) {
+	t.Helper()
 
 	// Build child args: re-enter test binary and trigger helper
 	childArgs := []string{"-test.run=TestCLIMain_Helper", "--"}
@@ -77,7 +84,7 @@ func runCLI(t *testing.T, args ...string) (exitCode int, stdout string, stderr s
 	out, errOut := &strings.Builder{}, &strings.Builder{}
 	cmd.Stdout, cmd.Stderr = out, errOut
 
-	err = cmd.Run()
+	err := cmd.Run()
 	code := 0
 	if err != nil {
 		var ee *exec.ExitError
@@ -161,3 +168,26 @@ func TestCLI_Atoi(t *testing.T) {
 		t.Fatalf("atoi unexpected result")
 	}
 }
+
+func TestCLI_Socket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "api.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("listen %s: %v", sock, err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"job_id":"j1","status":"running","via":"socket"}`))
+	})}
+	defer srv.Close()
+	go func() { _ = srv.Serve(ln) }()
+
+	code, out, _ := runChild(t, "--socket", sock, "status", "j1")
+	if code != 0 || !strings.Contains(out, `"via":"socket"`) {
+		t.Fatalf("status over the socket: code=%d out=%s", code, out)
+	}
+	t.Setenv("TELEGEN_SOCKET", sock)
+	if code, out, _ := runChild(t, "status", "j1"); code != 0 || !strings.Contains(out, `"via":"socket"`) {
+		t.Fatalf("TELEGEN_SOCKET should select the socket: code=%d out=%s", code, out)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build linux
+
+package api
+
+import "net/http"
+
+// Healthz answers liveness probes: the agent is serving requests.
+func (h *Handlers) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz answers readiness probes: 200 when the agent can run and report
+// on jobs, 503 listing the reasons when it cannot.
+func (h *Handlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	if h.Agent == nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ready"})
+		return
+	}
+	info := h.Agent.AgentInfo()
+	if !info.Ready {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "not_ready", "reasons": info.NotReady})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ready"})
+}
+
+// GetAgent reports the agent's build, environment and health.
+func (h *Handlers) GetAgent(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.Agent.AgentInfo())
+}
@@ -0,0 +1,121 @@
+//go:build linux
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testAgentCore reports a fixed AgentInfo.
+type testAgentCore struct {
+	info AgentInfo
+}
+
+func (t *testAgentCore) AgentInfo() AgentInfo { return t.info }
+
+func testAgentInfo() AgentInfo {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return AgentInfo{
+		Version: "1.2.3", Commit: "abc123", BuildDate: "2026-02-01",
+		KernelRelease: "6.1.0-sonic", BTF: true, BPFObject: "/bpf/tc_ingress.bpf.o", BPFObjectFound: true,
+		PinDir: "/sys/fs/bpf/telegen-sonic",
+		Maps: []PinnedMap{
+			{Name: "stats_percpu", Pinned: true, Required: true},
+			{Name: "flow_stats", Error: "open flow_stats: no such file or directory"},
+		},
+		Collector:  CollectorStatus{Running: true, LastScrape: &at},
+		OTLPExport: ExporterStatus{Endpoint: "collector:4317", LastError: "connection refused", LastErrorAt: &at},
+		Jobs:       JobCounts{Active: 1, Queued: 2, MaxConcurrent: 2},
+		Ready:      true,
+	}
+}
+
+func TestProbes(t *testing.T) {
+	ac := &testAgentCore{info: testAgentInfo()}
+	router := NewRouter(&Handlers{Core: &testCore{}, Agent: ac, Policy: testPolicy()})
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// probes need no identity even with RBAC on
+	if rr := get("/healthz", ""); rr.Code != http.StatusOK {
+		t.Fatalf("healthz: %d", rr.Code)
+	}
+	if rr := get("/readyz", ""); rr.Code != http.StatusOK {
+		t.Fatalf("readyz: %d %s", rr.Code, rr.Body.String())
+	}
+	ac.info.Ready, ac.info.NotReady = false, []string{"pinned map stats_percpu: no such file"}
+	rr := get("/readyz", "")
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz when not ready: %d", rr.Code)
+	}
+	got := decodeBody[struct {
+		Status  string   `json:"status"`
+		Reasons []string `json:"reasons"`
+	}](t, rr)
+	if got.Status != "not_ready" || len(got.Reasons) != 1 {
+		t.Fatalf("unexpected body %+v", got)
+	}
+	if rr := get("/healthz", ""); rr.Code != http.StatusOK {
+		t.Fatalf("liveness does not depend on readiness: %d", rr.Code)
+	}
+
+	if rr := get("/v1/agent", ""); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("agent without identity: %d", rr.Code)
+	}
+	rr = get("/v1/agent", "t-view")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("agent: %d", rr.Code)
+	}
+	if info := decodeBody[AgentInfo](t, rr); info.Version != "1.2.3" || info.Ready || info.Jobs.Queued != 2 {
+		t.Fatalf("unexpected agent info %+v", info)
+	}
+}
+
+func TestProbes_WithoutAgentCore(t *testing.T) {
+	router := NewRouter(&Handlers{Core: &testCore{}})
+	for path, code := range map[string]int{"/healthz": http.StatusOK, "/readyz": http.StatusOK, "/v1/agent": http.StatusNotFound} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		if rr.Code != code {
+			t.Fatalf("%s: %d, want %d", path, rr.Code, code)
+		}
+	}
+}
+
+func TestMetricsRoute(t *testing.T) {
+	metrics := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte("bpf_packets_total 42\n"))
+	})
+	router := NewRouter(&Handlers{Core: &testCore{}, Metrics: metrics, Policy: testPolicy()})
+	for token, code := range map[string]int{"": http.StatusUnauthorized, "t-nobody": http.StatusForbidden, "t-view": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != code {
+			t.Fatalf("%q: %d, want %d", token, rr.Code, code)
+		}
+		if code == http.StatusOK && rr.Body.String() != "bpf_packets_total 42\n" {
+			t.Fatalf("body %q", rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	NewRouter(&Handlers{Core: &testCore{}}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("without a Metrics handler: %d", rr.Code)
+	}
+}
@@ -0,0 +1,99 @@
+//go:build linux
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	apispec "github.com/platformbuilds/telegen-sonic/api"
+)
+
+// OpenAPI serves the API's OpenAPI description.
+func (h *Handlers) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(apispec.OpenAPI)
+}
+
+// ContractMiddleware checks requests and responses against h.Contract.
+// Requests that break it are answered 400 listing every problem before
+// they reach a handler; responses that break it are sent anyway and
+// reported to h.ContractViolation.
+func (h *Handlers) ContractMiddleware(next http.Handler) http.Handler {
+	if h.Contract == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			var sizeErr *http.MaxBytesError
+			if errors.As(err, &sizeErr) {
+				writeProblems(w, []Problem{{Code: CodeTooLarge, Message: fmt.Sprintf("request body is larger than %d bytes", sizeErr.Limit)}})
+				return
+			}
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "bad_request", "message": err.Error()})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if ps := h.Contract.CheckRequest(r, body); len(ps) > 0 {
+			writeProblems(w, ps)
+			return
+		}
+		rec := &contractRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		if ps := h.Contract.CheckResponse(r, rec.status, w.Header(), rec.body.Bytes()); len(ps) > 0 {
+			h.contractViolation(r, rec.status, ps)
+		}
+	})
+}
+
+func (h *Handlers) contractViolation(r *http.Request, status int, ps []Problem) {
+	if h.ContractViolation != nil {
+		h.ContractViolation(r, status, ps)
+		return
+	}
+	msgs := make([]string, len(ps))
+	for i, p := range ps {
+		msgs[i] = p.Message
+	}
+	log.Printf("openapi: %d to %s %s breaks the contract: %s", status, r.Method, r.URL.Path, strings.Join(msgs, "; "))
+}
+
+// contractRecorder keeps a copy of the response for ContractMiddleware.
+// Event streams are passed through without one.
+type contractRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *contractRecorder) WriteHeader(code int) {
+	if c.status == 0 {
+		c.status = code
+	}
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *contractRecorder) Write(b []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	if !strings.HasPrefix(c.Header().Get("Content-Type"), "text/event-stream") {
+		c.body.Write(b)
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+func (c *contractRecorder) Flush() {
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
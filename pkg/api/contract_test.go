@@ -0,0 +1,263 @@
+//go:build linux
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	apispec "github.com/platformbuilds/telegen-sonic/api"
+)
+
+// contractLog collects, across one test, the operations exercised
+// and the responses that broke the OpenAPI description.
+type contractLog struct {
+	t    *testing.T
+	spec *Spec
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func newContractLog(t *testing.T) *contractLog {
+	t.Helper()
+	spec, err := LoadSpec(apispec.OpenAPI)
+	if err != nil {
+		t.Fatalf("LoadSpec: %v", err)
+	}
+	return &contractLog{t: t, spec: spec, seen: map[string]bool{}}
+}
+
+// router mounts h with the contract enforced and every response checked.
+func (c *contractLog) router(h *Handlers) http.Handler {
+	h.Contract = c.spec
+	h.ContractViolation = func(r *http.Request, status int, ps []Problem) {
+		for _, p := range ps {
+			c.t.Errorf("%s %s answered %d: %s: %s", r.Method, r.URL.Path, status, p.Field, p.Message)
+		}
+	}
+	next := NewRouter(h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if op, _ := c.spec.find(r.Method, r.URL.Path); op != nil {
+			c.mu.Lock()
+			c.seen[op.method+" "+op.path] = true
+			c.mu.Unlock()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func contractFixtures() (JobStatus, JobResults, Schedule) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ended := now.Add(time.Minute)
+	job := JobStatus{
+		JobID: "j1", Status: "done", CreatedAt: now, StartedAt: now, ExpiresAt: now.Add(time.Hour),
+		Port: "PortChannel1", Interface: "erspan0", Members: []JobMember{{Port: "Ethernet0", Interface: "erspan0"}, {Port: "Ethernet4"}},
+		EndedAt: &ended, StepErrors: map[string]string{"teardown": "busy"}, StopReason: "preempted", PreemptedBy: "j2",
+		SampleRate: 100, Filters: map[string]interface{}{"ip_proto": "tcp"}, Labels: map[string]string{"team": "netops"},
+		Owner: "alice", Description: "probe", Priority: 10,
+	}
+	res := JobResults{
+		WindowSec: 60, Packets: 10, Bytes: 1500, Errors: map[string]uint64{"drops": 1},
+		TopFlows:           []TopFlow{{FiveTuple: "10.0.0.1:443->10.0.0.2:5000/TCP", Pkts: 10, Bytes: 1500}},
+		LatencyHistogramNs: Histogram{Bounds: []uint64{1000, 10000}, Counts: []uint64{3, 6, 1}},
+		OTLPExport:         OTLPInfo{Exported: true, Endpoint: "collector:4317"},
+		StopReason:         "budget_exceeded", CPUSeconds: 0.25,
+		Members: []MemberResults{{Port: "Ethernet0", Interface: "erspan0", Packets: 10, Bytes: 1500}},
+	}
+	next := now.Add(time.Hour)
+	sched := Schedule{
+		ScheduleID: "s1", Name: "hourly", Cron: "@hourly", Policy: "skip", Enabled: true, CreatedAt: now, NextRun: &next,
+		Job:     StartJobRequest{Port: "Ethernet0", Direction: "ingress", SpanMethod: "span", DurationSec: 60},
+		History: []ScheduleRun{{At: now, JobID: "j1", Status: "started"}, {At: next, Status: "failed", Error: "no capacity"}},
+	}
+	return job, res, sched
+}
+
+// TestContract drives every operation of api/openapi.yaml through the
+// router with the contract enforced, so that the handlers and the
+// description cannot drift apart unnoticed.
+func TestContract(t *testing.T) {
+	c := newContractLog(t)
+	job, res, sched := contractFixtures()
+	tc := &testCore{}
+	sc := &testScheduleCore{}
+	ec := &testEventCore{backlog: []Event{{ID: 1, Time: job.CreatedAt, JobID: "j1", State: "done", Reason: "expired"}}}
+	router := c.router(&Handlers{Core: tc, Schedules: sc, Events: ec, Agent: &testAgentCore{info: testAgentInfo()}})
+
+	start := `{"port":"Ethernet0","direction":"ingress","span_method":"erspan","duration_sec":30,` +
+		`"filters":{"ip_proto":["tcp","udp"],"l4_dport":443,"l4_sport":null},"labels":{"team":"netops"},"notify":{"webhook_url":"https://hooks.example/x"}}`
+	schedule := `{"name":"hourly","cron":"@hourly","policy":"queue","job":` + start + `}`
+
+	tests := []struct {
+		name, method, path, body string
+		setup                    func()
+		code                     int
+	}{
+		{"list", http.MethodGet, "/v1/monitor/jobs?state=running,done&label=team=netops&since=2026-01-01T00:00:00Z&limit=10", "", func() {
+			tc.listResp = ListJobsResponse{Jobs: []JobStatus{job}, NextCursor: "abc"}
+		}, http.StatusOK},
+		{"list bad limit", http.MethodGet, "/v1/monitor/jobs?limit=0", "", nil, http.StatusBadRequest},
+		{"list bad state", http.MethodGet, "/v1/monitor/jobs?state=running,asleep", "", nil, http.StatusBadRequest},
+		{"start", http.MethodPost, "/v1/monitor/jobs", start, func() {
+			tc.tryStartResp = StartJobResponse{JobID: "j1", Status: "starting", Interface: "erspan0"}
+		}, http.StatusCreated},
+		{"start queued", http.MethodPost, "/v1/monitor/jobs", start, func() {
+			tc.tryStartResp, tc.tryStartCode = StartJobResponse{JobID: "j1", Status: "queued", QueuePosition: 2}, http.StatusAccepted
+		}, http.StatusAccepted},
+		{"start conflict", http.MethodPost, "/v1/monitor/jobs", start, func() {
+			tc.tryStartCode, tc.tryStartErr = http.StatusConflict, errors.New("port is already being monitored")
+		}, http.StatusConflict},
+		{"start busy", http.MethodPost, "/v1/monitor/jobs", start, func() { tc.tryStartCode = http.StatusTooManyRequests }, http.StatusTooManyRequests},
+		{"start shutting down", http.MethodPost, "/v1/monitor/jobs", start, func() { tc.tryStartCode = http.StatusServiceUnavailable }, http.StatusServiceUnavailable},
+		{"start failed", http.MethodPost, "/v1/monitor/jobs", start, func() { tc.tryStartCode = http.StatusInternalServerError }, http.StatusInternalServerError},
+		{"start invalid", http.MethodPost, "/v1/monitor/jobs", `{"port":"Ethernet0","direction":"sideways","span_method":"span","duration_sec":0}`, nil, http.StatusBadRequest},
+		{"start unknown field", http.MethodPost, "/v1/monitor/jobs", `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":5,"color":"red"}`, nil, http.StatusBadRequest},
+		{"start no body", http.MethodPost, "/v1/monitor/jobs", "", nil, http.StatusBadRequest},
+		{"dry run", http.MethodPost, "/v1/monitor/jobs?dry_run=true", start, func() {
+			tc.validateResp = ValidateJobResponse{Valid: true, Admission: "start", Problems: []Problem{}}
+		}, http.StatusOK},
+		{"validate", http.MethodPost, "/v1/monitor/jobs:validate", start, func() {
+			tc.validateResp = ValidateJobResponse{Admission: "reject", Problems: []Problem{{Code: "no_capacity", Message: "no free slot"}}}
+		}, http.StatusOK},
+		{"get", http.MethodGet, "/v1/monitor/jobs/j1", "", func() { tc.getJobResp = job }, http.StatusOK},
+		{"get unknown", http.MethodGet, "/v1/monitor/jobs/nope", "", func() {
+			tc.getJobCode, tc.getJobErr = http.StatusNotFound, errors.New("job not found")
+		}, http.StatusNotFound},
+		{"update", http.MethodPatch, "/v1/monitor/jobs/j1", `{"duration_sec":600,"filters":{"ip_proto":"udp"}}`, func() {
+			tc.updateResp = job
+		}, http.StatusOK},
+		{"update inactive", http.MethodPatch, "/v1/monitor/jobs/j1", `{"sample_rate":10}`, func() {
+			tc.updateCode, tc.updateErr = http.StatusConflict, errors.New("job is not active")
+		}, http.StatusConflict},
+		{"stop", http.MethodDelete, "/v1/monitor/jobs/j1", "", func() {
+			tc.stopResp = StopJobResponse{JobID: "j1", Status: "stopping"}
+		}, http.StatusOK},
+		{"stop finished", http.MethodDelete, "/v1/monitor/jobs/j1", "", func() {
+			tc.stopCode, tc.stopErr = http.StatusConflict, errors.New("job is not active")
+		}, http.StatusConflict},
+		{"results", http.MethodGet, "/v1/monitor/jobs/j1/results?format=json", "", func() { tc.resultsResp = res }, http.StatusOK},
+		{"results bad format", http.MethodGet, "/v1/monitor/jobs/j1/results?format=xml", "", nil, http.StatusBadRequest},
+		{"list schedules", http.MethodGet, "/v1/monitor/schedules", "", func() {
+			sc.listResp = ListSchedulesResponse{Schedules: []Schedule{sched}}
+		}, http.StatusOK},
+		{"create schedule", http.MethodPost, "/v1/monitor/schedules", schedule, func() { sc.resp = sched }, http.StatusCreated},
+		{"create schedule invalid", http.MethodPost, "/v1/monitor/schedules", `{"cron":"@hourly","policy":"later","job":` + start + `}`, nil, http.StatusBadRequest},
+		{"get schedule", http.MethodGet, "/v1/monitor/schedules/s1", "", nil, http.StatusOK},
+		{"replace schedule", http.MethodPut, "/v1/monitor/schedules/s1", schedule, nil, http.StatusOK},
+		{"delete schedule", http.MethodDelete, "/v1/monitor/schedules/s1", "", nil, http.StatusNoContent},
+		{"unknown schedule", http.MethodGet, "/v1/monitor/schedules/nope", "", func() {
+			sc.code, sc.err = http.StatusNotFound, errors.New("schedule not found")
+		}, http.StatusNotFound},
+		{"events", http.MethodGet, "/v1/events?job_id=j1&last_event_id=0", "", func() {
+			ec.live = make(chan Event)
+			close(ec.live)
+		}, http.StatusOK},
+		{"events bad id", http.MethodGet, "/v1/events?last_event_id=-1", "", nil, http.StatusBadRequest},
+		{"agent", http.MethodGet, "/v1/agent", "", nil, http.StatusOK},
+		{"healthz", http.MethodGet, "/healthz", "", nil, http.StatusOK},
+		{"openapi", http.MethodGet, "/v1/openapi.yaml", "", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.t = t
+			if tt.setup != nil {
+				tt.setup()
+			}
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != tt.code {
+				t.Fatalf("code=%d want=%d; body=%s", rr.Code, tt.code, rr.Body.String())
+			}
+		})
+	}
+
+	// refusals by the middleware in front of the handlers
+	c.t = t
+	limited := c.router(&Handlers{Core: &testCore{getJobResp: job}, MaxBodyBytes: 64, Policy: testPolicy(),
+		Limiter: NewRateLimiter(map[string]RateLimit{ClassRead: {Rate: 1, Burst: 1}})})
+	for _, tt := range []struct {
+		token, method, path, body string
+		code                      int
+	}{
+		{"", http.MethodGet, "/v1/monitor/jobs/j1", "", http.StatusUnauthorized},
+		{"t-view", http.MethodDelete, "/v1/monitor/jobs/j1", "", http.StatusForbidden},
+		{"t-view", http.MethodGet, "/v1/monitor/jobs/j1", "", http.StatusOK},
+		{"t-view", http.MethodGet, "/v1/monitor/jobs/j1", "", http.StatusTooManyRequests},
+		{"t-op", http.MethodPost, "/v1/monitor/jobs", start, http.StatusRequestEntityTooLarge},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rr := httptest.NewRecorder()
+		limited.ServeHTTP(rr, req)
+		if rr.Code != tt.code {
+			t.Fatalf("%s %s as %q: code=%d want=%d; body=%s", tt.method, tt.path, tt.token, rr.Code, tt.code, rr.Body.String())
+		}
+	}
+
+	for _, op := range c.spec.operations() {
+		if !c.seen[op] {
+			t.Errorf("%s is not exercised", op)
+		}
+	}
+}
+
+func TestContract_RejectsAndReports(t *testing.T) {
+	spec, err := LoadSpec(apispec.OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reported []Problem
+	tc := &testCore{getJobResp: JobStatus{JobID: "j1", Status: "sleeping"}}
+	router := NewRouter(&Handlers{Core: tc, Contract: spec, ContractViolation: func(_ *http.Request, _ int, ps []Problem) {
+		reported = append(reported, ps...)
+	}})
+
+	body := `{"ports":["Ethernet0"],"direction":"ingress","span_method":"span","duration_sec":5,"vlan":5000,"filters":{"l4_dport":[1,70000]}}`
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/monitor/jobs", bytes.NewBufferString(body)))
+	if rr.Code != http.StatusBadRequest || tc.startCalled {
+		t.Fatalf("code=%d startCalled=%v", rr.Code, tc.startCalled)
+	}
+	got := problemCodes(decodeBody[BadRequestResponse](t, rr).Problems)
+	if got["vlan"] != CodeInvalid || got["filters.l4_dport"] != CodeInvalid || len(got) != 2 {
+		t.Fatalf("problems = %v", got)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/monitor/jobs/j1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("a response that breaks the contract is still sent, got %d", rr.Code)
+	}
+	if len(reported) != 1 || reported[0].Field != "status" {
+		t.Fatalf("reported = %+v", reported)
+	}
+
+	// undocumented statuses and routes are reported too
+	reported = nil
+	tc.getJobCode, tc.getJobErr = http.StatusTeapot, errors.New("teapot")
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/monitor/jobs/j1", nil))
+	if len(reported) != 1 || !strings.Contains(reported[0].Message, "status 418 is not documented") {
+		t.Fatalf("reported = %+v", reported)
+	}
+	reported = nil
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/nowhere", nil))
+	if len(reported) != 0 {
+		t.Fatalf("404s for unknown routes are fine, got %+v", reported)
+	}
+}
+
+func TestLoadSpec_UnresolvableRef(t *testing.T) {
+	spec := "openapi: 3.1.0\npaths:\n  /x:\n    get:\n      responses:\n        '200': { $ref: '#/components/responses/Missing' }\n"
+	if _, err := LoadSpec([]byte(spec)); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+//go:build linux
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// sseKeepAlive is how often an idle stream gets a comment line so proxies
+// do not time it out.
+var sseKeepAlive = 15 * time.Second
+
+// StreamEvents serves job events as Server-Sent Events. ?job_id= limits the
+// stream to one job; a Last-Event-ID header (or ?last_event_id= for
+// clients that cannot set headers) replays the events missed since then.
+func (h *Handlers) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	var lastID uint64
+	last := r.Header.Get("Last-Event-ID")
+	if last == "" {
+		last = r.URL.Query().Get("last_event_id")
+	}
+	if last != "" {
+		n, err := strconv.ParseUint(last, 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "bad_request", "message": "invalid last event id"})
+			return
+		}
+		lastID = n
+	}
+	fl, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "stream_unsupported", "message": "response writer cannot flush"})
+		return
+	}
+
+	backlog, events, cancel := h.Events.Subscribe(r.URL.Query().Get("job_id"), lastID)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	for _, e := range backlog {
+		writeEvent(w, e)
+	}
+	fl.Flush()
+
+	ping := time.NewTicker(sseKeepAlive)
+	defer ping.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				// dropped or shutting down; the client reconnects with
+				// Last-Event-ID
+				return
+			}
+			writeEvent(w, e)
+			fl.Flush()
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+			fl.Flush()
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, e Event) {
+	b, _ := json.Marshal(e)
+	fmt.Fprintf(w, "id: %d\nevent: job\ndata: %s\n\n", e.ID, b)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEventCore replays backlog and then whatever is sent on live.
+type testEventCore struct {
+	jobID   string
+	lastID  uint64
+	backlog []Event
+	live    chan Event
+}
+
+func (t *testEventCore) Subscribe(jobID string, lastID uint64) ([]Event, <-chan Event, func()) {
+	t.jobID, t.lastID = jobID, lastID
+	return t.backlog, t.live, func() {}
+}
+
+// readEvents parses n events off an SSE stream.
+func readEvents(t *testing.T, sc *bufio.Scanner, n int) []Event {
+	t.Helper()
+	var out []Event
+	var id string
+	for len(out) < n && sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			var e Event
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e); err != nil {
+				t.Fatalf("bad data line %q: %v", line, err)
+			}
+			if id == "" {
+				t.Fatalf("event %d has no id line", e.ID)
+			}
+			out = append(out, e)
+			id = ""
+		}
+	}
+	if len(out) < n {
+		t.Fatalf("stream ended after %d events: %v", len(out), sc.Err())
+	}
+	return out
+}
+
+func TestStreamEvents_BacklogThenLive(t *testing.T) {
+	ec := &testEventCore{
+		backlog: []Event{{ID: 4, JobID: "j1", State: "running"}},
+		live:    make(chan Event, 1),
+	}
+	srv := httptest.NewServer(NewRouter(&Handlers{Core: &testCore{}, Events: ec}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/events?job_id=j1", nil)
+	req.Header.Set("Last-Event-ID", "3")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status=%d content-type=%q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if ec.jobID != "j1" || ec.lastID != 3 {
+		t.Fatalf("subscribe got job=%q last=%d", ec.jobID, ec.lastID)
+	}
+
+	sc := bufio.NewScanner(resp.Body)
+	if got := readEvents(t, sc, 1); got[0].ID != 4 || got[0].State != "running" {
+		t.Fatalf("backlog event = %+v", got[0])
+	}
+	ec.live <- Event{ID: 5, JobID: "j1", State: "done", Reason: "expired"}
+	if got := readEvents(t, sc, 1); got[0].ID != 5 || got[0].Reason != "expired" {
+		t.Fatalf("live event = %+v", got[0])
+	}
+
+	// a closed subscription ends the response
+	close(ec.live)
+	done := make(chan struct{})
+	go func() {
+		for sc.Scan() {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("stream not closed after the subscription ended")
+	}
+}
+
+func TestStreamEvents_LastEventIDQuery(t *testing.T) {
+	ec := &testEventCore{live: make(chan Event)}
+	close(ec.live)
+	srv := httptest.NewServer(NewRouter(&Handlers{Core: &testCore{}, Events: ec}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/events?last_event_id=7")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if ec.lastID != 7 || ec.jobID != "" {
+		t.Fatalf("subscribe got job=%q last=%d", ec.jobID, ec.lastID)
+	}
+
+	resp, err = http.Get(srv.URL + "/v1/events?last_event_id=abc")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad last event id, got %d", resp.StatusCode)
+	}
+}
+
+func TestStreamEvents_NotMountedWithoutCore(t *testing.T) {
+	srv := httptest.NewServer(NewRouter(&Handlers{Core: &testCore{}}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/v1/events")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 without an event source, got %d", resp.StatusCode)
+	}
+}
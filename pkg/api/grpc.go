@@ -0,0 +1,357 @@
+//go:build linux
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	jobsv1 "github.com/platformbuilds/telegen-sonic/api/jobs/v1"
+)
+
+// grpcRoutes gives each Jobs method the rate-limit class and permission of
+// the REST route it mirrors.
+var grpcRoutes = map[string]struct {
+	class string
+	perm  Permission
+}{
+	jobsv1.Jobs_StartJob_FullMethodName:   {ClassWrite, PermJobsStart},
+	jobsv1.Jobs_GetJob_FullMethodName:     {ClassRead, PermJobsRead},
+	jobsv1.Jobs_StopJob_FullMethodName:    {ClassWrite, PermJobsManage},
+	jobsv1.Jobs_GetResults_FullMethodName: {ClassRead, PermJobsRead},
+	jobsv1.Jobs_ListJobs_FullMethodName:   {ClassRead, PermJobsRead},
+	jobsv1.Jobs_WatchJob_FullMethodName:   {ClassRead, PermJobsRead},
+}
+
+// NewGRPCServer returns a gRPC server with the Jobs service of
+// api/jobs/v1, backed by h.Core and subject to h's Policy, Limiter and
+// MaxBodyBytes. WatchJob needs h.Events.
+func NewGRPCServer(h *Handlers, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(h.unaryInterceptor),
+		grpc.ChainStreamInterceptor(h.streamInterceptor),
+	)
+	if h.MaxBodyBytes > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(min(h.MaxBodyBytes, math.MaxInt32))))
+	}
+	s := grpc.NewServer(opts...)
+	jobsv1.RegisterJobsServer(s, &jobsServer{h: h})
+	return s
+}
+
+func (h *Handlers) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx, err := h.admit(ctx, info.FullMethod)
+	var resp interface{}
+	if err == nil {
+		resp, err = handler(ctx, req)
+	}
+	logRPC(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func (h *Handlers) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, err := h.admit(ss.Context(), info.FullMethod)
+	if err == nil {
+		err = handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+	}
+	logRPC(ctx, info.FullMethod, start, err)
+	return err
+}
+
+func logRPC(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	if id, ok := IdentityFromContext(ctx); ok {
+		log.Printf("grpc %s %s %s client=%q", method, code, time.Since(start), id.Name())
+		return
+	}
+	log.Printf("grpc %s %s %s", method, code, time.Since(start))
+}
+
+// identityStream carries the context admit returned to stream handlers.
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context { return s.ctx }
+
+// admit does for an RPC what IdentityMiddleware, Limit and Require do for
+// a REST request: it records the caller's identity in the returned
+// context, then checks its rate limit and permission.
+func (h *Handlers) admit(ctx context.Context, method string) (context.Context, error) {
+	route, ok := grpcRoutes[method]
+	if !ok {
+		return ctx, status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	if tok, ok := grpcBearerToken(ctx); ok && h.Policy != nil {
+		name, known := h.Policy.token(tok)
+		if !known {
+			// counted against the peer address, so guessing is limited too
+			if err := h.grpcLimit(ctx, route.class); err != nil {
+				return ctx, err
+			}
+			return ctx, status.Error(codes.Unauthenticated, "unknown bearer token")
+		}
+		ctx = context.WithValue(ctx, identityKey{}, ClientIdentity{Token: name})
+	} else if p, ok := peer.FromContext(ctx); ok {
+		if ti, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(ti.State.VerifiedChains) > 0 && len(ti.State.VerifiedChains[0]) > 0 {
+			ctx = context.WithValue(ctx, identityKey{}, certIdentity(ti.State.VerifiedChains[0][0]))
+		}
+	}
+
+	// rate limits apply before permissions so refused callers are counted too
+	if err := h.grpcLimit(ctx, route.class); err != nil {
+		return ctx, err
+	}
+	if !h.permits(ctx, route.perm) {
+		return ctx, h.grpcDenied(ctx, route.perm)
+	}
+	return ctx, nil
+}
+
+// grpcLimit is Limit for RPCs.
+func (h *Handlers) grpcLimit(ctx context.Context, class string) error {
+	if h.Limiter == nil {
+		return nil
+	}
+	if wait := h.Limiter.take(class, grpcClientKey(ctx)); wait > 0 {
+		secs := int(math.Ceil(wait.Seconds()))
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(secs)))
+		return status.Errorf(codes.ResourceExhausted, "too many %s requests; retry in %ds", class, secs)
+	}
+	return nil
+}
+
+// grpcDenied is forbid for RPCs.
+func (h *Handlers) grpcDenied(ctx context.Context, perm Permission) error {
+	code, msg := h.denial(ctx, perm)
+	return status.Error(grpcCode(code), msg)
+}
+
+// grpcBearerToken returns the token of "authorization: Bearer" metadata.
+func grpcBearerToken(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		scheme, tok, ok := strings.Cut(v, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") && tok != "" {
+			return tok, true
+		}
+	}
+	return "", false
+}
+
+// grpcClientKey is clientKey for RPCs.
+func grpcClientKey(ctx context.Context) string {
+	if id, ok := IdentityFromContext(ctx); ok {
+		return "id:" + id.Name()
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
+
+// grpcCode maps the HTTP status a Core method answered with to a gRPC code.
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
+
+// problemsError is writeProblems for RPCs: InvalidArgument with the first
+// problem as message and all of them as a BadRequest detail.
+func problemsError(ps []Problem) error {
+	br := &errdetails.BadRequest{}
+	for _, p := range ps {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field: p.Field, Reason: p.Code, Description: p.Message,
+		})
+	}
+	st, err := status.New(codes.InvalidArgument, ps[0].Message).WithDetails(br)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, ps[0].Message)
+	}
+	return st.Err()
+}
+
+// jobsServer implements jobsv1.JobsServer on top of Handlers.
+type jobsServer struct {
+	jobsv1.UnimplementedJobsServer
+	h *Handlers
+}
+
+func (s *jobsServer) StartJob(ctx context.Context, m *jobsv1.StartJobRequest) (*jobsv1.StartJobResponse, error) {
+	req := startJobRequestFromProto(m)
+	if ps := validateStartJob(req); len(ps) > 0 {
+		return nil, problemsError(ps)
+	}
+	// as on POST /v1/monitor/jobs
+	id, ok := IdentityFromContext(ctx)
+	if ok && req.Owner == "" {
+		req.Owner = id.Name()
+	}
+	if req.Owner != id.Name() && !s.h.permits(ctx, PermJobsManageAny) {
+		return nil, s.h.grpcDenied(ctx, PermJobsManageAny)
+	}
+	if req.Priority > 0 && !s.h.permits(ctx, PermJobsPreempt) {
+		return nil, s.h.grpcDenied(ctx, PermJobsPreempt)
+	}
+	resp, code, err := s.h.Core.TryStartJob(req)
+	var invalid *InvalidRequestError
+	if errors.As(err, &invalid) {
+		return nil, problemsError(invalid.Problems)
+	}
+	if err != nil {
+		return nil, status.Error(grpcCode(code), err.Error())
+	}
+	return startJobResponseToProto(resp), nil
+}
+
+func (s *jobsServer) GetJob(ctx context.Context, m *jobsv1.GetJobRequest) (*jobsv1.JobStatus, error) {
+	if m.GetJobId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
+	}
+	resp, code, err := s.h.Core.GetJob(m.GetJobId())
+	if err != nil {
+		return nil, status.Error(grpcCode(code), err.Error())
+	}
+	return jobStatusToProto(resp), nil
+}
+
+func (s *jobsServer) StopJob(ctx context.Context, m *jobsv1.StopJobRequest) (*jobsv1.StopJobResponse, error) {
+	if m.GetJobId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
+	}
+	if err := s.mayManage(ctx, m.GetJobId()); err != nil {
+		return nil, err
+	}
+	resp, code, err := s.h.Core.StopJob(m.GetJobId())
+	if err != nil {
+		return nil, status.Error(grpcCode(code), err.Error())
+	}
+	return stopJobResponseToProto(resp), nil
+}
+
+// mayManage is Handlers.mayManage for RPCs.
+func (s *jobsServer) mayManage(ctx context.Context, id string) error {
+	if s.h.permits(ctx, PermJobsManageAny) {
+		return nil
+	}
+	st, code, err := s.h.Core.GetJob(id)
+	if err != nil {
+		return status.Error(grpcCode(code), err.Error())
+	}
+	if caller, _ := IdentityFromContext(ctx); st.Owner == "" || st.Owner != caller.Name() {
+		return s.h.grpcDenied(ctx, PermJobsManageAny)
+	}
+	return nil
+}
+
+func (s *jobsServer) GetResults(ctx context.Context, m *jobsv1.GetResultsRequest) (*jobsv1.JobResults, error) {
+	if m.GetJobId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "job_id is required")
+	}
+	resp, code, err := s.h.Core.GetResults(m.GetJobId())
+	if err != nil {
+		return nil, status.Error(grpcCode(code), err.Error())
+	}
+	return jobResultsToProto(resp), nil
+}
+
+func (s *jobsServer) ListJobs(ctx context.Context, m *jobsv1.ListJobsRequest) (*jobsv1.ListJobsResponse, error) {
+	if m.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must be a positive integer")
+	}
+	for k := range m.GetLabels() {
+		if k == "" {
+			return nil, status.Error(codes.InvalidArgument, "label selectors need a key")
+		}
+	}
+	resp, code, err := s.h.Core.ListJobs(listJobsRequestFromProto(m))
+	if err != nil {
+		return nil, status.Error(grpcCode(code), err.Error())
+	}
+	return listJobsResponseToProto(resp), nil
+}
+
+// WatchJob subscribes to the job's events before reading its status, so no
+// change between the two is missed, then sends the status again after
+// every event until the job is done or failed.
+func (s *jobsServer) WatchJob(m *jobsv1.WatchJobRequest, stream jobsv1.Jobs_WatchJobServer) error {
+	if m.GetJobId() == "" {
+		return status.Error(codes.InvalidArgument, "job_id is required")
+	}
+	if s.h.Events == nil {
+		return status.Error(codes.Unimplemented, "job events are not enabled")
+	}
+	_, events, cancel := s.h.Events.Subscribe(m.GetJobId(), 0)
+	defer cancel()
+
+	send := func() (bool, error) {
+		st, code, err := s.h.Core.GetJob(m.GetJobId())
+		if err != nil {
+			return false, status.Error(grpcCode(code), err.Error())
+		}
+		if err := stream.Send(jobStatusToProto(st)); err != nil {
+			return false, err
+		}
+		return st.Status == "done" || st.Status == "failed", nil
+	}
+	if done, err := send(); done || err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case _, ok := <-events:
+			if !ok {
+				// dropped or shutting down; the client watches again
+				return status.Error(codes.Unavailable, fmt.Sprintf("events of job %s ended; watch again", m.GetJobId()))
+			}
+			if done, err := send(); done || err != nil {
+				return err
+			}
+		}
+	}
+}
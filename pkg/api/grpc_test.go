@@ -0,0 +1,266 @@
+//go:build linux
+
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	jobsv1 "github.com/platformbuilds/telegen-sonic/api/jobs/v1"
+)
+
+// dialJobs serves h over an in-memory connection and returns a client.
+func dialJobs(t *testing.T, h *Handlers) jobsv1.JobsClient {
+	t.Helper()
+	ln := bufconn.Listen(1 << 20)
+	s := NewGRPCServer(h)
+	go func() { _ = s.Serve(ln) }()
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return jobsv1.NewJobsClient(conn)
+}
+
+func withToken(tok string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+tok)
+}
+
+func TestGRPC_Jobs(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tc := &testCore{
+		tryStartResp: StartJobResponse{JobID: "j1", Status: "running", Interface: "mirror0"},
+		getJobResp: JobStatus{JobID: "j1", Status: "running", CreatedAt: created, Port: "Ethernet0",
+			Filters: map[string]interface{}{"l4_dport": 443.0}, Owner: "op"},
+		stopResp: StopJobResponse{JobID: "j1", Status: "stopping"},
+		resultsResp: JobResults{WindowSec: 5, Packets: 10, Bytes: 1500,
+			TopFlows: []TopFlow{{FiveTuple: "10.0.0.1:1->10.0.0.2:443/TCP", Pkts: 10, Bytes: 1500}}},
+		listResp: ListJobsResponse{Jobs: []JobStatus{{JobID: "j1", Status: "running"}}, NextCursor: "c2"},
+	}
+	c := dialJobs(t, &Handlers{Core: tc})
+	ctx := context.Background()
+
+	filters, _ := structpb.NewStruct(map[string]interface{}{"l4_dport": 443})
+	vlan := int32(100)
+	start, err := c.StartJob(ctx, &jobsv1.StartJobRequest{
+		Port: "Ethernet0", Direction: "ingress", SpanMethod: "span", DurationSec: 60,
+		Vlan: &vlan, Filters: filters, Labels: map[string]string{"team": "netops"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start.GetJobId() != "j1" || start.GetInterface() != "mirror0" {
+		t.Fatalf("start = %v", start)
+	}
+	if r := tc.startReq; r.Port != "Ethernet0" || r.VLAN == nil || *r.VLAN != 100 || r.Filters["l4_dport"] != 443.0 || r.Labels["team"] != "netops" {
+		t.Fatalf("core got %+v", r)
+	}
+
+	st, err := c.GetJob(ctx, &jobsv1.GetJobRequest{JobId: "j1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.GetCreatedAt().AsTime().Equal(created) || st.GetStartedAt() != nil || st.GetEndedAt() != nil {
+		t.Fatalf("times = %v %v %v", st.GetCreatedAt(), st.GetStartedAt(), st.GetEndedAt())
+	}
+	if st.GetFilters().AsMap()["l4_dport"] != 443.0 {
+		t.Fatalf("filters = %v", st.GetFilters())
+	}
+
+	res, err := c.GetResults(ctx, &jobsv1.GetResultsRequest{JobId: "j1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetPacketsTotal() != 10 || len(res.GetTopFlows()) != 1 || res.GetTopFlows()[0].GetFiveTuple() == "" {
+		t.Fatalf("results = %v", res)
+	}
+
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	list, err := c.ListJobs(ctx, &jobsv1.ListJobsRequest{States: []string{"running"}, Since: timestamppb.New(since), Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetJobs()) != 1 || list.GetNextCursor() != "c2" {
+		t.Fatalf("list = %v", list)
+	}
+	if r := tc.listReq; len(r.States) != 1 || !r.Since.Equal(since) || !r.Until.IsZero() || r.Limit != 10 {
+		t.Fatalf("core got %+v", r)
+	}
+
+	stop, err := c.StopJob(ctx, &jobsv1.StopJobRequest{JobId: "j1"})
+	if err != nil || stop.GetStatus() != "stopping" {
+		t.Fatalf("stop = %v, %v", stop, err)
+	}
+}
+
+func TestGRPC_Errors(t *testing.T) {
+	tc := &testCore{getJobCode: http.StatusNotFound, getJobErr: errors.New("job not found"),
+		tryStartCode: http.StatusTooManyRequests, tryStartErr: errors.New("all slots are busy")}
+	c := dialJobs(t, &Handlers{Core: tc})
+	ctx := context.Background()
+
+	_, err := c.StartJob(ctx, &jobsv1.StartJobRequest{Direction: "sideways"})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument || tc.startCalled {
+		t.Fatalf("invalid start: %v", err)
+	}
+	var br *errdetails.BadRequest
+	for _, d := range st.Details() {
+		if d, ok := d.(*errdetails.BadRequest); ok {
+			br = d
+		}
+	}
+	if br == nil || len(br.GetFieldViolations()) < 2 {
+		t.Fatalf("details = %v", st.Details())
+	}
+
+	_, err = c.StartJob(ctx, &jobsv1.StartJobRequest{Port: "Ethernet0", Direction: "ingress", SpanMethod: "span", DurationSec: 5})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("busy: %v", err)
+	}
+	tc.tryStartCode, tc.tryStartErr = http.StatusBadRequest, &InvalidRequestError{Problems: []Problem{
+		{Field: "owner", Code: CodeInvalid, Message: "owner is longer than 128 bytes"}}}
+	_, err = c.StartJob(ctx, &jobsv1.StartJobRequest{Port: "Ethernet0", Direction: "ingress", SpanMethod: "span", DurationSec: 5})
+	if st := status.Convert(err); st.Code() != codes.InvalidArgument || len(st.Details()) != 1 {
+		t.Fatalf("agent problems: %v", err)
+	}
+	if _, err := c.GetJob(ctx, &jobsv1.GetJobRequest{JobId: "nope"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("missing job: %v", err)
+	}
+	if _, err := c.GetJob(ctx, &jobsv1.GetJobRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("no job id: %v", err)
+	}
+	if _, err := c.ListJobs(ctx, &jobsv1.ListJobsRequest{Limit: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("negative limit: %v", err)
+	}
+}
+
+func TestGRPC_RBAC(t *testing.T) {
+	tc := &testCore{getJobResp: JobStatus{JobID: "j1", Status: "running", Owner: "someone-else"}}
+	c := dialJobs(t, &Handlers{Core: tc, Policy: testPolicy()})
+	get := &jobsv1.GetJobRequest{JobId: "j1"}
+	start := &jobsv1.StartJobRequest{Port: "Ethernet0", Direction: "ingress", SpanMethod: "span", DurationSec: 5}
+
+	if _, err := c.GetJob(context.Background(), get); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous: %v", err)
+	}
+	if _, err := c.GetJob(withToken("t-bogus"), get); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("unknown token: %v", err)
+	}
+	if _, err := c.GetJob(withToken("t-view"), get); err != nil {
+		t.Fatalf("viewer get: %v", err)
+	}
+	if _, err := c.StartJob(withToken("t-view"), start); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("viewer start: %v", err)
+	}
+
+	// operators own the jobs they start and may only stop their own
+	if _, err := c.StartJob(withToken("t-op"), start); err != nil || tc.startReq.Owner != "op" {
+		t.Fatalf("operator start: %v, owner %q", err, tc.startReq.Owner)
+	}
+	start.Priority = 50
+	if _, err := c.StartJob(withToken("t-op"), start); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("operator preempting: %v", err)
+	}
+	if _, err := c.StopJob(withToken("t-op"), &jobsv1.StopJobRequest{JobId: "j1"}); status.Code(err) != codes.PermissionDenied || tc.stopCalled {
+		t.Fatalf("operator stopping another's job: %v", err)
+	}
+	if _, err := c.StopJob(withToken("t-admin"), &jobsv1.StopJobRequest{JobId: "j1"}); err != nil || !tc.stopCalled {
+		t.Fatalf("admin stop: %v", err)
+	}
+}
+
+func TestGRPC_RateLimit(t *testing.T) {
+	lim := NewRateLimiter(map[string]RateLimit{ClassRead: {Rate: 0.001, Burst: 1}})
+	c := dialJobs(t, &Handlers{Core: &testCore{}, Limiter: lim})
+	get := &jobsv1.GetJobRequest{JobId: "j1"}
+	if _, err := c.GetJob(context.Background(), get); err != nil {
+		t.Fatal(err)
+	}
+	var md metadata.MD
+	_, err := c.GetJob(context.Background(), get, grpc.Header(&md))
+	if status.Code(err) != codes.ResourceExhausted || len(md.Get("retry-after")) != 1 {
+		t.Fatalf("over the limit: %v, header %v", err, md)
+	}
+}
+
+func TestGRPC_RateLimitUnknownTokens(t *testing.T) {
+	lim := NewRateLimiter(map[string]RateLimit{ClassRead: {Rate: 0.001, Burst: 1}})
+	c := dialJobs(t, &Handlers{Core: &testCore{}, Policy: testPolicy(), Limiter: lim})
+	get := &jobsv1.GetJobRequest{JobId: "j1"}
+	if _, err := c.GetJob(withToken("t-guess"), get); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("first guess: %v", err)
+	}
+	if _, err := c.GetJob(withToken("t-guess"), get); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("second guess: %v", err)
+	}
+}
+
+func TestGRPC_WatchJob(t *testing.T) {
+	tc := &testCore{getJobResp: JobStatus{JobID: "j1", Status: "queued"}}
+	ec := &testEventCore{live: make(chan Event)}
+	c := dialJobs(t, &Handlers{Core: tc, Events: ec})
+
+	stream, err := c.WatchJob(context.Background(), &jobsv1.WatchJobRequest{JobId: "j1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recv := func(want string) {
+		t.Helper()
+		st, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if st.GetStatus() != want {
+			t.Fatalf("status %q, want %q", st.GetStatus(), want)
+		}
+	}
+	recv("queued")
+	if ec.jobID != "j1" || ec.lastID != 0 {
+		t.Fatalf("subscribed to %q after %d", ec.jobID, ec.lastID)
+	}
+	tc.getJobResp.Status = "running"
+	ec.live <- Event{ID: 1, JobID: "j1", State: "running"}
+	recv("running")
+	tc.getJobResp.Status = "done"
+	ec.live <- Event{ID: 2, JobID: "j1", State: "done"}
+	recv("done")
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("stream should end after done, got %v", err)
+	}
+
+	// a job that is already over is sent once
+	stream, err = c.WatchJob(context.Background(), &jobsv1.WatchJobRequest{JobId: "j1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recv("done")
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("got %v", err)
+	}
+
+	c = dialJobs(t, &Handlers{Core: tc})
+	stream, _ = c.WatchJob(context.Background(), &jobsv1.WatchJobRequest{JobId: "j1"})
+	if _, err := stream.Recv(); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("without events: %v", err)
+	}
+}
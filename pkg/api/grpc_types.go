@@ -0,0 +1,161 @@
+package api
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	jobsv1 "github.com/platformbuilds/telegen-sonic/api/jobs/v1"
+)
+
+// Conversions between the gRPC messages of api/jobs/v1 and the REST types.
+// Zero times map to unset timestamps and back, as the JSON bodies omit
+// them.
+
+func startJobRequestFromProto(m *jobsv1.StartJobRequest) StartJobRequest {
+	req := StartJobRequest{
+		Port:          m.GetPort(),
+		Ports:         m.GetPorts(),
+		Direction:     m.GetDirection(),
+		SpanMethod:    m.GetSpanMethod(),
+		SampleRate:    int(m.GetSampleRate()),
+		DurationSec:   int(m.GetDurationSec()),
+		OTLPExport:    m.GetOtlpExport(),
+		ResultDetail:  m.GetResultDetail(),
+		AllowShared:   m.GetAllowShared(),
+		Priority:      int(m.GetPriority()),
+		MaxPackets:    m.GetMaxPackets(),
+		MaxBytes:      m.GetMaxBytes(),
+		MaxCPUSeconds: m.GetMaxCpuSeconds(),
+		Labels:        m.GetLabels(),
+		Owner:         m.GetOwner(),
+		Description:   m.GetDescription(),
+	}
+	if m.Vlan != nil {
+		v := int(m.GetVlan())
+		req.VLAN = &v
+	}
+	if m.GetFilters() != nil {
+		req.Filters = m.GetFilters().AsMap()
+	}
+	if m.GetNotify() != nil {
+		req.Notify = &Notify{WebhookURL: m.GetNotify().GetWebhookUrl()}
+	}
+	return req
+}
+
+func startJobResponseToProto(r StartJobResponse) *jobsv1.StartJobResponse {
+	return &jobsv1.StartJobResponse{
+		JobId:          r.JobID,
+		Status:         r.Status,
+		Interface:      r.Interface,
+		QueuePosition:  int32(r.QueuePosition),
+		PreemptedJobId: r.PreemptedJobID,
+	}
+}
+
+func listJobsRequestFromProto(m *jobsv1.ListJobsRequest) ListJobsRequest {
+	req := ListJobsRequest{
+		States: m.GetStates(),
+		Port:   m.GetPort(),
+		Owner:  m.GetOwner(),
+		Labels: m.GetLabels(),
+		Limit:  int(m.GetLimit()),
+		Cursor: m.GetCursor(),
+	}
+	if m.GetSince() != nil {
+		req.Since = m.GetSince().AsTime()
+	}
+	if m.GetUntil() != nil {
+		req.Until = m.GetUntil().AsTime()
+	}
+	return req
+}
+
+func listJobsResponseToProto(r ListJobsResponse) *jobsv1.ListJobsResponse {
+	out := &jobsv1.ListJobsResponse{NextCursor: r.NextCursor}
+	for _, j := range r.Jobs {
+		out.Jobs = append(out.Jobs, jobStatusToProto(j))
+	}
+	return out
+}
+
+func jobStatusToProto(s JobStatus) *jobsv1.JobStatus {
+	out := &jobsv1.JobStatus{
+		JobId:         s.JobID,
+		Status:        s.Status,
+		CreatedAt:     timestampProto(s.CreatedAt),
+		StartedAt:     timestampProto(s.StartedAt),
+		ExpiresAt:     timestampProto(s.ExpiresAt),
+		Port:          s.Port,
+		Interface:     s.Interface,
+		Ports:         s.Ports,
+		QueuePosition: int32(s.QueuePosition),
+		FailureReason: s.FailureReason,
+		StepErrors:    s.StepErrors,
+		StopReason:    s.StopReason,
+		SampleRate:    int32(s.SampleRate),
+		Filters:       structProto(s.Filters),
+		Labels:        s.Labels,
+		Owner:         s.Owner,
+		Description:   s.Description,
+		Priority:      int32(s.Priority),
+		PreemptedBy:   s.PreemptedBy,
+	}
+	if s.EndedAt != nil {
+		out.EndedAt = timestampProto(*s.EndedAt)
+	}
+	for _, m := range s.Members {
+		out.Members = append(out.Members, &jobsv1.JobMember{Port: m.Port, Interface: m.Interface})
+	}
+	return out
+}
+
+func stopJobResponseToProto(r StopJobResponse) *jobsv1.StopJobResponse {
+	return &jobsv1.StopJobResponse{JobId: r.JobID, Status: r.Status}
+}
+
+func jobResultsToProto(r JobResults) *jobsv1.JobResults {
+	out := &jobsv1.JobResults{
+		WindowSec:    int32(r.WindowSec),
+		PacketsTotal: r.Packets,
+		BytesTotal:   r.Bytes,
+		Errors:       r.Errors,
+		LatencyHistogramNs: &jobsv1.Histogram{
+			Bounds: r.LatencyHistogramNs.Bounds,
+			Counts: r.LatencyHistogramNs.Counts,
+		},
+		OtelExport: &jobsv1.OTLPInfo{Exported: r.OTLPExport.Exported, Endpoint: r.OTLPExport.Endpoint},
+		StopReason: r.StopReason,
+		CpuSeconds: r.CPUSeconds,
+	}
+	for _, f := range r.TopFlows {
+		out.TopFlows = append(out.TopFlows, &jobsv1.TopFlow{FiveTuple: f.FiveTuple, Pkts: f.Pkts, Bytes: f.Bytes})
+	}
+	for _, m := range r.Members {
+		out.Members = append(out.Members, &jobsv1.MemberResults{
+			Port: m.Port, Interface: m.Interface, PacketsTotal: m.Packets, BytesTotal: m.Bytes,
+		})
+	}
+	return out
+}
+
+func timestampProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// structProto converts decoded JSON; anything else is left out.
+func structProto(m map[string]interface{}) *structpb.Struct {
+	if len(m) == 0 {
+		return nil
+	}
+	s, err := structpb.NewStruct(m)
+	if err != nil {
+		return nil
+	}
+	return s
+}
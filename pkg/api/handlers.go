@@ -4,7 +4,12 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -15,20 +20,86 @@ func writeJSON(w http.ResponseWriter, code int, v interface{}) {
 	_ = json.NewEncoder(w).Encode(v)
 }
 
+// writeProblems answers a request whose body failed validation, or 413
+// one whose body was too large to read.
+func writeProblems(w http.ResponseWriter, ps []Problem) {
+	if ps[0].Code == CodeTooLarge {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "too_large", "message": ps[0].Message})
+		return
+	}
+	writeJSON(w, http.StatusBadRequest, BadRequestResponse{Error: "bad_request", Message: ps[0].Message, Problems: ps})
+}
+
+// writeFailure answers a request Core refused, listing the problems when
+// it was refused for its fields.
+func writeFailure(w http.ResponseWriter, code int, kind string, err error) {
+	var invalid *InvalidRequestError
+	if errors.As(err, &invalid) {
+		writeProblems(w, invalid.Problems)
+		return
+	}
+	writeJSON(w, code, map[string]string{"error": kind, "message": err.Error()})
+}
+
 func (h *Handlers) StartJob(w http.ResponseWriter, r *http.Request) {
 	var req StartJobRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "bad_request", "message": err.Error()})
+	if ps := decodeStrict(r.Body, &req); len(ps) > 0 {
+		writeProblems(w, ps)
+		return
+	}
+	if dry, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")); dry {
+		h.validateJob(w, req)
+		return
+	}
+	if ps := validateStartJob(req); len(ps) > 0 {
+		writeProblems(w, ps)
+		return
+	}
+	// jobs started by an authenticated client are owned by it unless the
+	// request says otherwise
+	id, ok := IdentityFromContext(r.Context())
+	if ok && req.Owner == "" {
+		req.Owner = id.Name()
+	}
+	if req.Owner != id.Name() && !h.allowed(r, PermJobsManageAny) {
+		h.forbid(w, r, PermJobsManageAny)
+		return
+	}
+	if req.Priority > 0 && !h.allowed(r, PermJobsPreempt) {
+		h.forbid(w, r, PermJobsPreempt)
 		return
 	}
 	resp, code, err := h.Core.TryStartJob(req)
 	if err != nil {
-		writeJSON(w, code, map[string]string{"error": "start_failed", "message": err.Error()})
+		writeFailure(w, code, "start_failed", err)
 		return
 	}
 	writeJSON(w, code, resp)
 }
 
+// ValidateJob checks a start request without starting anything. The answer
+// is 200 whether or not the request is valid; problems says what is wrong.
+func (h *Handlers) ValidateJob(w http.ResponseWriter, r *http.Request) {
+	var req StartJobRequest
+	if ps := decodeStrict(r.Body, &req); len(ps) > 0 {
+		writeProblems(w, ps)
+		return
+	}
+	h.validateJob(w, req)
+}
+
+func (h *Handlers) validateJob(w http.ResponseWriter, req StartJobRequest) {
+	resp, code, err := h.Core.ValidateJob(req)
+	if err != nil {
+		writeJSON(w, code, map[string]string{"error": "validate_failed", "message": err.Error()})
+		return
+	}
+	if ps := validateStartJob(req); len(ps) > 0 {
+		resp.Valid, resp.Problems = false, mergeProblems(ps, resp.Problems)
+	}
+	writeJSON(w, code, resp)
+}
+
 func (h *Handlers) GetJob(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "job_id")
 	resp, code, err := h.Core.GetJob(id)
@@ -41,6 +112,9 @@ func (h *Handlers) GetJob(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handlers) StopJob(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "job_id")
+	if !h.mayManage(w, r, id) {
+		return
+	}
 	resp, code, err := h.Core.StopJob(id)
 	if err != nil {
 		writeJSON(w, code, map[string]string{"error": "stop_failed", "message": err.Error()})
@@ -49,6 +123,28 @@ func (h *Handlers) StopJob(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, code, resp)
 }
 
+func (h *Handlers) UpdateJob(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "job_id")
+	var req UpdateJobRequest
+	if ps := decodeStrict(r.Body, &req); len(ps) > 0 {
+		writeProblems(w, ps)
+		return
+	}
+	if ps := validateUpdateJob(req); len(ps) > 0 {
+		writeProblems(w, ps)
+		return
+	}
+	if !h.mayManage(w, r, id) {
+		return
+	}
+	resp, code, err := h.Core.UpdateJob(id, req)
+	if err != nil {
+		writeJSON(w, code, map[string]string{"error": "update_failed", "message": err.Error()})
+		return
+	}
+	writeJSON(w, code, resp)
+}
+
 func (h *Handlers) GetResults(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "job_id")
 	resp, code, err := h.Core.GetResults(id)
@@ -58,3 +154,61 @@ func (h *Handlers) GetResults(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, code, resp)
 }
+
+func (h *Handlers) ListJobs(w http.ResponseWriter, r *http.Request) {
+	req, err := parseListJobs(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "bad_request", "message": err.Error()})
+		return
+	}
+	resp, code, err := h.Core.ListJobs(req)
+	if err != nil {
+		writeJSON(w, code, map[string]string{"error": "list_failed", "message": err.Error()})
+		return
+	}
+	writeJSON(w, code, resp)
+}
+
+func parseListJobs(r *http.Request) (ListJobsRequest, error) {
+	q := r.URL.Query()
+	req := ListJobsRequest{Port: q.Get("port"), Owner: q.Get("owner"), Cursor: q.Get("cursor")}
+	for _, v := range q["state"] {
+		for _, st := range strings.Split(v, ",") {
+			if st = strings.TrimSpace(st); st != "" {
+				req.States = append(req.States, st)
+			}
+		}
+	}
+	for _, v := range q["label"] {
+		for _, sel := range strings.Split(v, ",") {
+			if sel = strings.TrimSpace(sel); sel == "" {
+				continue
+			}
+			k, val, ok := strings.Cut(sel, "=")
+			if !ok || k == "" {
+				return req, fmt.Errorf("label selector %q must be key=value", sel)
+			}
+			if req.Labels == nil {
+				req.Labels = map[string]string{}
+			}
+			req.Labels[k] = val
+		}
+	}
+	var err error
+	if v := q.Get("since"); v != "" {
+		if req.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return req, fmt.Errorf("since: %w", err)
+		}
+	}
+	if v := q.Get("until"); v != "" {
+		if req.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return req, fmt.Errorf("until: %w", err)
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if req.Limit, err = strconv.Atoi(v); err != nil || req.Limit < 1 {
+			return req, fmt.Errorf("limit must be a positive integer")
+		}
+	}
+	return req, nil
+}
@@ -40,7 +40,7 @@ func TestStartJob_OK(t *testing.T) {
 	}
 	h := &Handlers{Core: tc}
 
-	body := []byte(`{"port":"Ethernet0","direction":"ingress","duration_sec":5}`)
+	body := []byte(`{"port":"Ethernet0","direction":"ingress","span_method":"erspan","duration_sec":5}`)
 	req := httptest.NewRequest(http.MethodPost, "/jobs/start", bytes.NewReader(body))
 	rr := httptest.NewRecorder()
 
@@ -195,3 +195,127 @@ func TestGetResults_Error(t *testing.T) {
 		t.Fatalf("unexpected error field: %v", got)
 	}
 }
+
+func TestListJobs_ParsesQuery(t *testing.T) {
+	tc := &testCore{
+		listResp: ListJobsResponse{Jobs: []JobStatus{{JobID: "j1", Status: "done"}}, NextCursor: "abc"},
+	}
+	h := &Handlers{Core: tc}
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/v1/monitor/jobs?state=done,failed&state=running&port=Ethernet0&since=2025-08-15T00:00:00Z&until=2025-08-16T00:00:00Z&limit=10&cursor=xyz"+
+			"&owner=alice&label=team=netops,env=prod&label=ticket=", nil)
+	rr := httptest.NewRecorder()
+
+	h.ListJobs(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("code=%d want=%d; body=%s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	got := decodeBody[ListJobsResponse](t, rr)
+	if len(got.Jobs) != 1 || got.NextCursor != "abc" {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+	q := tc.listReq
+	if len(q.States) != 3 || q.States[0] != "done" || q.States[2] != "running" {
+		t.Fatalf("states not parsed: %+v", q.States)
+	}
+	if q.Port != "Ethernet0" || q.Limit != 10 || q.Cursor != "xyz" {
+		t.Fatalf("query not parsed: %+v", q)
+	}
+	if q.Since.Day() != 15 || q.Until.Day() != 16 {
+		t.Fatalf("time range not parsed: %v - %v", q.Since, q.Until)
+	}
+	if q.Owner != "alice" || len(q.Labels) != 3 || q.Labels["team"] != "netops" || q.Labels["env"] != "prod" {
+		t.Fatalf("owner/labels not parsed: %q %v", q.Owner, q.Labels)
+	}
+	if v, ok := q.Labels["ticket"]; !ok || v != "" {
+		t.Fatalf("empty label value should be kept: %v", q.Labels)
+	}
+}
+
+func TestListJobs_BadQuery(t *testing.T) {
+	for _, query := range []string{"since=yesterday", "until=1", "limit=0", "limit=abc", "label=team", "label==x"} {
+		tc := &testCore{}
+		h := &Handlers{Core: tc}
+		rr := httptest.NewRecorder()
+		h.ListJobs(rr, httptest.NewRequest(http.MethodGet, "/v1/monitor/jobs?"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: code=%d want=%d", query, rr.Code, http.StatusBadRequest)
+		}
+		if tc.listCalled {
+			t.Fatalf("%s: Core.ListJobs should not be called", query)
+		}
+	}
+}
+
+func TestUpdateJob_OK(t *testing.T) {
+	tc := &testCore{
+		updateResp: JobStatus{JobID: "j1", Status: "running", SampleRate: 10},
+	}
+	h := &Handlers{Core: tc}
+
+	body := []byte(`{"duration_sec":600,"sample_rate":10,"filters":{"ip_proto":["tcp"]}}`)
+	req := makeReqWithRouteParam(http.MethodPatch, "/v1/monitor/jobs/j1", "job_id", "j1", body)
+	rr := httptest.NewRecorder()
+
+	h.UpdateJob(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("code=%d want=%d; body=%s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := decodeBody[JobStatus](t, rr); got.SampleRate != 10 {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+	if tc.updateID != "j1" || tc.updateReq.DurationSec != 600 || tc.updateReq.SampleRate != 10 || tc.updateReq.Filters == nil {
+		t.Fatalf("request not passed through: id=%s req=%+v", tc.updateID, tc.updateReq)
+	}
+}
+
+func TestUpdateJob_BadJSON(t *testing.T) {
+	tc := &testCore{}
+	h := &Handlers{Core: tc}
+
+	req := makeReqWithRouteParam(http.MethodPatch, "/v1/monitor/jobs/j1", "job_id", "j1", []byte(`{`))
+	rr := httptest.NewRecorder()
+
+	h.UpdateJob(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("code=%d want=%d", rr.Code, http.StatusBadRequest)
+	}
+	if tc.updateCalled {
+		t.Fatalf("Core.UpdateJob should not be called")
+	}
+}
+
+func TestValidateJob_Routes(t *testing.T) {
+	body := []byte(`{"port":"Ethernet0","direction":"ingress","span_method":"erspan","duration_sec":5}`)
+	for _, url := range []string{"/v1/monitor/jobs:validate", "/v1/monitor/jobs?dry_run=true"} {
+		tc := &testCore{validateResp: ValidateJobResponse{
+			Admission: "reject",
+			Problems:  []Problem{{Field: "port", Code: "port_not_found", Message: "port Ethernet0 not found"}},
+		}}
+		rr := httptest.NewRecorder()
+		NewRouter(&Handlers{Core: tc}).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body)))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: code=%d; body=%s", url, rr.Code, rr.Body.String())
+		}
+		if tc.startCalled {
+			t.Fatalf("%s: a dry run must not start the job", url)
+		}
+		if tc.validateReq == nil || tc.validateReq.Port != "Ethernet0" {
+			t.Fatalf("%s: request not passed on: %+v", url, tc.validateReq)
+		}
+		got := decodeBody[ValidateJobResponse](t, rr)
+		if got.Valid || len(got.Problems) != 1 || got.Problems[0].Code != "port_not_found" {
+			t.Fatalf("%s: unexpected response: %+v", url, got)
+		}
+	}
+
+	// dry_run=false is an ordinary start
+	tc := &testCore{}
+	rr := httptest.NewRecorder()
+	NewRouter(&Handlers{Core: tc}).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/monitor/jobs?dry_run=false", bytes.NewReader(body)))
+	if !tc.startCalled || tc.validateReq != nil {
+		t.Fatalf("dry_run=false should start the job")
+	}
+}
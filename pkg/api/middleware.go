@@ -3,6 +3,11 @@
 package api
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
 	"log"
 	"net/http"
 	"time"
@@ -10,19 +15,163 @@ import (
 
 type Handlers struct {
 	Core Core
+
+	// Schedules backs /v1/monitor/schedules; the routes are only mounted
+	// when it is set.
+	Schedules ScheduleCore
+
+	// Events backs /v1/events; the route is only mounted when it is set.
+	Events EventCore
+
+	// Agent backs /v1/agent and the readiness checks of /readyz; the
+	// former is only mounted when it is set.
+	Agent AgentCore
+
+	// Metrics serves /metrics for Prometheus to scrape; the route is only
+	// mounted when it is set.
+	Metrics http.Handler
+
+	// Policy maps callers to roles; without one every caller may do
+	// anything.
+	Policy *Policy
+
+	// Limiter rate-limits each client per route class; nil disables it.
+	Limiter *RateLimiter
+
+	// MaxBodyBytes caps request bodies; 0 leaves them unlimited.
+	MaxBodyBytes int64
+
+	// Contract, when set, checks requests and responses against the
+	// OpenAPI description; see ContractMiddleware.
+	Contract *Spec
+
+	// ContractViolation is told about responses that break the Contract;
+	// nil logs them.
+	ContractViolation func(r *http.Request, status int, ps []Problem)
 }
 
 type Core interface {
 	TryStartJob(StartJobRequest) (StartJobResponse, int, error)
+	ValidateJob(StartJobRequest) (ValidateJobResponse, int, error)
 	GetJob(id string) (JobStatus, int, error)
 	StopJob(id string) (StopJobResponse, int, error)
 	GetResults(id string) (JobResults, int, error)
+	ListJobs(ListJobsRequest) (ListJobsResponse, int, error)
+	UpdateJob(id string, req UpdateJobRequest) (JobStatus, int, error)
+}
+
+type ScheduleCore interface {
+	CreateSchedule(ScheduleRequest) (Schedule, int, error)
+	ListSchedules() (ListSchedulesResponse, int, error)
+	GetSchedule(id string) (Schedule, int, error)
+	UpdateSchedule(id string, req ScheduleRequest) (Schedule, int, error)
+	DeleteSchedule(id string) (int, error)
+}
+
+// AgentCore reports on the agent itself.
+type AgentCore interface {
+	AgentInfo() AgentInfo
+}
+
+// EventCore streams job events. Subscribe returns the buffered events
+// after lastID and a channel of live ones, both limited to jobID unless it
+// is empty. The channel is closed when the subscriber is dropped.
+type EventCore interface {
+	Subscribe(jobID string, lastID uint64) (backlog []Event, events <-chan Event, cancel func())
 }
 
 func (h *Handlers) LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		next.ServeHTTP(w, r)
+		if id, ok := IdentityFromContext(r.Context()); ok {
+			log.Printf("%s %s %s client=%q", r.Method, r.URL.Path, time.Since(start), id.Name())
+			return
+		}
 		log.Printf("%s %s %s", r.Method, r.URL.Path, time.Since(start))
 	})
 }
+
+// ClientIdentity is who made a request: the bearer token it carried, the
+// client certificate verified during the TLS handshake, or the peer of a
+// Unix socket connection.
+type ClientIdentity struct {
+	Token       string    // name of the bearer token
+	Subject     string    // common name
+	SANs        []string  // DNS names, URIs and email addresses
+	Fingerprint string    // hex SHA-256 of the certificate
+	Peer        *PeerCred // set for Unix socket clients instead of the above
+}
+
+// Name is the token name, or the common name (the first SAN of a
+// certificate without one). Unix socket peers are named by their user, or
+// uid:<n> without one.
+func (id ClientIdentity) Name() string {
+	if id.Token != "" {
+		return id.Token
+	}
+	if id.Peer != nil {
+		if id.Peer.User != "" {
+			return id.Peer.User
+		}
+		return fmt.Sprintf("uid:%d", id.Peer.UID)
+	}
+	if id.Subject == "" && len(id.SANs) > 0 {
+		return id.SANs[0]
+	}
+	return id.Subject
+}
+
+type identityKey struct{}
+
+// IdentityFromContext returns the identity IdentityMiddleware stored for
+// the request, if the client was authenticated.
+func IdentityFromContext(ctx context.Context) (ClientIdentity, bool) {
+	id, ok := ctx.Value(identityKey{}).(ClientIdentity)
+	return id, ok
+}
+
+// IdentityMiddleware records who made a request in its context: the
+// bearer token it carries if the Policy knows it, else the verified client
+// certificate of a TLS request or the peer credentials of a Unix socket
+// one. Plain HTTP requests pass through without an identity; a token the
+// Policy does not know is refused, and counted against the caller's
+// address so guessing tokens is rate-limited like any other request.
+func (h *Handlers) IdentityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tok, ok := bearerToken(r); ok && h.Policy != nil {
+			name, known := h.Policy.token(tok)
+			if !known {
+				if h.overLimit(w, r, methodClass(r)) {
+					return
+				}
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized", "message": "unknown bearer token"})
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, ClientIdentity{Token: name})))
+			return
+		}
+		if cred, ok := r.Context().Value(peerKey{}).(PeerCred); ok {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, ClientIdentity{Peer: &cred})))
+			return
+		}
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id := certIdentity(r.TLS.VerifiedChains[0][0])
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+	})
+}
+
+// certIdentity identifies the holder of a verified client certificate.
+func certIdentity(leaf *x509.Certificate) ClientIdentity {
+	sum := sha256.Sum256(leaf.Raw)
+	id := ClientIdentity{Subject: leaf.Subject.CommonName, Fingerprint: hex.EncodeToString(sum[:])}
+	id.SANs = append(id.SANs, leaf.DNSNames...)
+	for _, u := range leaf.URIs {
+		id.SANs = append(id.SANs, u.String())
+	}
+	id.SANs = append(id.SANs, leaf.EmailAddresses...)
+	return id
+}
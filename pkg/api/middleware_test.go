@@ -4,9 +4,13 @@ package api
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -47,3 +51,34 @@ func TestLoggingMiddleware_PassesThroughAndLogs(t *testing.T) {
 		t.Logf("log did not contain a duration token; got: %q", logged)
 	}
 }
+
+func TestIdentityMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(prev)
+
+	var got ClientIdentity
+	var ok bool
+	h := &Handlers{}
+	mw := h.IdentityMiddleware(h.LoggingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok = IdentityFromContext(r.Context())
+	})))
+
+	mw.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/monitor/jobs", nil))
+	if ok {
+		t.Fatalf("a plain HTTP request has no identity, got %+v", got)
+	}
+
+	spiffe, _ := url.Parse("spiffe://example.org/netops")
+	leaf := &x509.Certificate{Raw: []byte("der"), Subject: pkix.Name{}, DNSNames: []string{"bot.example.org"}, URIs: []*url.URL{spiffe}}
+	req := httptest.NewRequest(http.MethodGet, "/v1/monitor/jobs", nil)
+	req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{leaf}}}
+	mw.ServeHTTP(httptest.NewRecorder(), req)
+	if !ok || got.Name() != "bot.example.org" || len(got.SANs) != 2 || got.SANs[1] != "spiffe://example.org/netops" || len(got.Fingerprint) != 64 {
+		t.Fatalf("identity = %+v (ok=%v)", got, ok)
+	}
+	if !strings.Contains(buf.String(), `client="bot.example.org"`) {
+		t.Fatalf("expected the client in the access log, got %q", buf.String())
+	}
+}
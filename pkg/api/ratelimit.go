@@ -0,0 +1,150 @@
+//go:build linux
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Route classes, each with its own rate limit.
+const (
+	ClassRead  = "read"  // GETs, including the event stream
+	ClassWrite = "write" // everything that changes jobs or schedules
+)
+
+// RateLimit is the token bucket each client gets for a route class: Burst
+// requests at once, refilled at Rate per second. A zero Rate disables it.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiter keeps a token bucket per client and route class. Clients are
+// told apart by their identity, or by remote address without one.
+type RateLimiter struct {
+	limits map[string]RateLimit
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+}
+
+type bucketKey struct{ class, client string }
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewRateLimiter(limits map[string]RateLimit) *RateLimiter {
+	return &RateLimiter{limits: limits, now: time.Now, buckets: map[bucketKey]*bucket{}}
+}
+
+// take spends a token of client's bucket for class. If the bucket is
+// empty it returns how long until the next token.
+func (l *RateLimiter) take(class, client string) time.Duration {
+	lim := l.limits[class]
+	if lim.Rate <= 0 {
+		return 0
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	key := bucketKey{class, client}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(lim.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(lim.Burst), b.tokens+now.Sub(b.last).Seconds()*lim.Rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / lim.Rate * float64(time.Second))
+}
+
+// sweep forgets, once a minute, the buckets that have filled up again: a
+// new one is the same.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		lim := l.limits[key.class]
+		if b.tokens+now.Sub(b.last).Seconds()*lim.Rate >= float64(lim.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// clientKey names the caller of r for rate limiting.
+func clientKey(r *http.Request) string {
+	if id, ok := IdentityFromContext(r.Context()); ok {
+		return "id:" + id.Name()
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// Limit rate-limits the route as part of class. Requests over the limit get
+// 429 with a Retry-After header.
+func (h *Handlers) Limit(class string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if h.overLimit(w, r, class) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// overLimit spends a token of r's client for class, and answers 429 when
+// there is none left.
+func (h *Handlers) overLimit(w http.ResponseWriter, r *http.Request, class string) bool {
+	if h.Limiter == nil {
+		return false
+	}
+	wait := h.Limiter.take(class, clientKey(r))
+	if wait <= 0 {
+		return false
+	}
+	secs := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	writeJSON(w, http.StatusTooManyRequests, map[string]string{
+		"error": "rate_limited", "message": fmt.Sprintf("too many %s requests; retry in %ds", class, secs),
+	})
+	return true
+}
+
+// methodClass is the route class of a request before it is routed.
+func methodClass(r *http.Request) string {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return ClassRead
+	}
+	return ClassWrite
+}
+
+// BodyLimitMiddleware caps request bodies at MaxBodyBytes; decoding a
+// larger body fails with 413.
+func (h *Handlers) BodyLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.MaxBodyBytes > 0 && r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, h.MaxBodyBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
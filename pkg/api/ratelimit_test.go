@@ -0,0 +1,107 @@
+//go:build linux
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := NewRateLimiter(map[string]RateLimit{ClassWrite: {Rate: 0.5, Burst: 2}})
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if wait := l.take(ClassWrite, "a"); wait != 0 {
+			t.Fatalf("request %d within the burst had to wait %v", i, wait)
+		}
+	}
+	if wait := l.take(ClassWrite, "a"); wait != 2*time.Second {
+		t.Fatalf("wait = %v, want 2s", wait)
+	}
+	if wait := l.take(ClassWrite, "b"); wait != 0 {
+		t.Fatalf("another client has its own bucket, waited %v", wait)
+	}
+	if wait := l.take(ClassRead, "a"); wait != 0 {
+		t.Fatalf("a class without a limit should not wait, waited %v", wait)
+	}
+	now = now.Add(2 * time.Second)
+	if wait := l.take(ClassWrite, "a"); wait != 0 {
+		t.Fatalf("a token should have been refilled, waited %v", wait)
+	}
+
+	// idle buckets are forgotten once full again
+	now = now.Add(time.Hour)
+	l.take(ClassWrite, "c")
+	if len(l.buckets) != 1 {
+		t.Fatalf("expected only the new bucket to remain, have %d", len(l.buckets))
+	}
+}
+
+func TestHandlers_RateLimitAndBodyLimit(t *testing.T) {
+	tc := &testCore{}
+	h := &Handlers{Core: tc, MaxBodyBytes: 256, Limiter: NewRateLimiter(map[string]RateLimit{
+		ClassRead:  {Rate: 1, Burst: 1},
+		ClassWrite: {Rate: 1, Burst: 5},
+	})}
+	router := NewRouter(h)
+
+	get := func(remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/monitor/jobs", nil)
+		req.RemoteAddr = remote
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	if rr := get("192.0.2.1:4000"); rr.Code != http.StatusOK {
+		t.Fatalf("first request: %d", rr.Code)
+	}
+	rr := get("192.0.2.1:4001")
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "1" {
+		t.Fatalf("second request: code=%d Retry-After=%q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+	if got := decodeBody[map[string]string](t, rr); got["error"] != "rate_limited" {
+		t.Fatalf("unexpected body %v", got)
+	}
+	if rr := get("192.0.2.2:4000"); rr.Code != http.StatusOK {
+		t.Fatalf("another address should not be limited: %d", rr.Code)
+	}
+
+	big := `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":5,"description":"` + strings.Repeat("x", 300) + `"}`
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/monitor/jobs", bytes.NewBufferString(big)))
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized body: code=%d body=%s", rr.Code, rr.Body.String())
+	}
+	if tc.startCalled {
+		t.Fatalf("an oversized request must not reach the agent")
+	}
+}
+
+func TestHandlers_RateLimitUnknownTokens(t *testing.T) {
+	h := &Handlers{Core: &testCore{}, Policy: testPolicy(), Limiter: NewRateLimiter(map[string]RateLimit{
+		ClassRead: {Rate: 0.001, Burst: 2},
+	})}
+	router := NewRouter(h)
+	get := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/monitor/jobs", nil)
+		req.RemoteAddr = "192.0.2.1:4000"
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		if code := get("t-guess"); code != want {
+			t.Fatalf("guess %d: code %d, want %d", i, code, want)
+		}
+	}
+	if code := get("t-view"); code != http.StatusOK {
+		t.Fatalf("a known token has its own bucket, got %d", code)
+	}
+}
@@ -0,0 +1,151 @@
+//go:build linux
+
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Permission is what a route requires of the caller's role.
+type Permission string
+
+const (
+	PermJobsRead       Permission = "jobs:read"       // list and get jobs and results, follow events
+	PermJobsStart      Permission = "jobs:start"      // start and validate jobs
+	PermJobsManage     Permission = "jobs:manage"     // stop and modify the caller's own jobs
+	PermJobsManageAny  Permission = "jobs:manage_any" // stop and modify anyone's jobs, start jobs for others
+	PermJobsPreempt    Permission = "jobs:preempt"    // start jobs with a priority, which may preempt others
+	PermSchedulesRead  Permission = "schedules:read"
+	PermSchedulesWrite Permission = "schedules:write"
+	PermAgentRead      Permission = "agent:read" // build, kernel, BPF and exporter diagnostics; /metrics
+)
+
+// Roles lists the permissions of each role.
+var Roles = map[string][]Permission{
+	"viewer":   {PermJobsRead, PermSchedulesRead, PermAgentRead},
+	"operator": {PermJobsRead, PermSchedulesRead, PermAgentRead, PermJobsStart, PermJobsManage},
+	"admin": {PermJobsRead, PermSchedulesRead, PermAgentRead, PermJobsStart, PermJobsManage,
+		PermJobsManageAny, PermJobsPreempt, PermSchedulesWrite},
+}
+
+// Policy maps callers to roles. Subjects are matched against a client
+// certificate's common name and SANs, the name of a bearer token and the
+// user name of a Unix socket peer; UIDs against Unix socket peers.
+type Policy struct {
+	Subjects    map[string]string // subject -> role
+	UIDs        map[uint32]string // peer uid -> role
+	Tokens      map[string]string // bearer token -> caller name
+	DefaultRole string            // role of identified callers without a binding; "" refuses them
+}
+
+// role returns the role of id, or "" if it has none.
+func (p *Policy) role(id ClientIdentity) string {
+	if id.Peer != nil {
+		if r, ok := p.UIDs[id.Peer.UID]; ok {
+			return r
+		}
+	}
+	for _, name := range append([]string{id.Name()}, id.SANs...) {
+		if r, ok := p.Subjects[name]; ok {
+			return r
+		}
+	}
+	return p.DefaultRole
+}
+
+// token returns the caller name of a bearer token, comparing every
+// configured token in constant time.
+func (p *Policy) token(tok string) (string, bool) {
+	var name string
+	found := false
+	for t, n := range p.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(tok)) == 1 {
+			name, found = n, true
+		}
+	}
+	return name, found
+}
+
+// allowed reports whether the caller of r may use perm. Without a Policy
+// every caller may.
+func (h *Handlers) allowed(r *http.Request, perm Permission) bool {
+	return h.permits(r.Context(), perm)
+}
+
+// permits reports whether the caller identified in ctx may use perm.
+func (h *Handlers) permits(ctx context.Context, perm Permission) bool {
+	if h.Policy == nil {
+		return true
+	}
+	id, ok := IdentityFromContext(ctx)
+	return ok && slices.Contains(Roles[h.Policy.role(id)], perm)
+}
+
+// denial explains why the caller identified in ctx may not use perm:
+// 401 if it is not identified at all, 403 otherwise.
+func (h *Handlers) denial(ctx context.Context, perm Permission) (int, string) {
+	id, ok := IdentityFromContext(ctx)
+	if !ok {
+		return http.StatusUnauthorized, "a client certificate, bearer token or Unix socket is required"
+	}
+	if role := h.Policy.role(id); role != "" {
+		return http.StatusForbidden, fmt.Sprintf("role %s of %s lacks %s", role, id.Name(), perm)
+	}
+	return http.StatusForbidden, fmt.Sprintf("%s has no role", id.Name())
+}
+
+// forbid answers a caller that lacks perm: 401 if it is not identified
+// at all, 403 naming the missing permission otherwise.
+func (h *Handlers) forbid(w http.ResponseWriter, r *http.Request, perm Permission) {
+	code, msg := h.denial(r.Context(), perm)
+	if code == http.StatusUnauthorized {
+		writeJSON(w, code, map[string]string{"error": "unauthorized", "message": msg})
+		return
+	}
+	writeJSON(w, code, map[string]string{"error": "forbidden", "message": msg, "permission": string(perm)})
+}
+
+// Require lets only callers with perm through to the route.
+func (h *Handlers) Require(perm Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !h.allowed(r, perm) {
+				h.forbid(w, r, perm)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// mayManage checks that the caller may stop or modify job id: any job
+// with jobs:manage_any, its own (by owner) otherwise.
+func (h *Handlers) mayManage(w http.ResponseWriter, r *http.Request, id string) bool {
+	if h.allowed(r, PermJobsManageAny) {
+		return true
+	}
+	st, code, err := h.Core.GetJob(id)
+	if err != nil {
+		writeJSON(w, code, map[string]string{"error": "not_found", "message": err.Error()})
+		return false
+	}
+	if caller, _ := IdentityFromContext(r.Context()); st.Owner == "" || st.Owner != caller.Name() {
+		h.forbid(w, r, PermJobsManageAny)
+		return false
+	}
+	return true
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, tok, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || tok == "" {
+		return "", false
+	}
+	return tok, true
+}
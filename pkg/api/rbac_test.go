@@ -0,0 +1,102 @@
+//go:build linux
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testPolicy() *Policy {
+	return &Policy{
+		Subjects: map[string]string{"viewer-bot": "viewer", "op": "operator", "root-bot": "admin"},
+		UIDs:     map[uint32]string{0: "admin"},
+		Tokens:   map[string]string{"t-view": "viewer-bot", "t-op": "op", "t-admin": "root-bot", "t-nobody": "nobody"},
+	}
+}
+
+func TestRBAC_Routes(t *testing.T) {
+	tc := &testCore{getJobResp: JobStatus{JobID: "j1", Owner: "someone-else"}}
+	sc := &testScheduleCore{}
+	router := NewRouter(&Handlers{Core: tc, Schedules: sc, Policy: testPolicy()})
+	start := `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":5`
+
+	tests := []struct {
+		name, token, method, path, body string
+		code                            int
+		perm                            Permission
+	}{
+		{"anonymous", "", http.MethodGet, "/v1/monitor/jobs", "", http.StatusUnauthorized, ""},
+		{"unknown token", "t-bogus", http.MethodGet, "/v1/monitor/jobs", "", http.StatusUnauthorized, ""},
+		{"no role", "t-nobody", http.MethodGet, "/v1/monitor/jobs", "", http.StatusForbidden, PermJobsRead},
+		{"viewer lists", "t-view", http.MethodGet, "/v1/monitor/jobs", "", http.StatusOK, ""},
+		{"viewer reads results", "t-view", http.MethodGet, "/v1/monitor/jobs/j1/results", "", http.StatusOK, ""},
+		{"viewer starts", "t-view", http.MethodPost, "/v1/monitor/jobs", start + `}`, http.StatusForbidden, PermJobsStart},
+		{"viewer stops", "t-view", http.MethodDelete, "/v1/monitor/jobs/j1", "", http.StatusForbidden, PermJobsManage},
+		{"operator starts", "t-op", http.MethodPost, "/v1/monitor/jobs", start + `}`, http.StatusCreated, ""},
+		{"operator validates", "t-op", http.MethodPost, "/v1/monitor/jobs:validate", start + `}`, http.StatusOK, ""},
+		{"operator preempts", "t-op", http.MethodPost, "/v1/monitor/jobs", start + `,"priority":10}`, http.StatusForbidden, PermJobsPreempt},
+		{"operator starts for another", "t-op", http.MethodPost, "/v1/monitor/jobs", start + `,"owner":"alice"}`, http.StatusForbidden, PermJobsManageAny},
+		{"operator stops another's job", "t-op", http.MethodDelete, "/v1/monitor/jobs/j1", "", http.StatusForbidden, PermJobsManageAny},
+		{"operator modifies another's job", "t-op", http.MethodPatch, "/v1/monitor/jobs/j1", `{"duration_sec":60}`, http.StatusForbidden, PermJobsManageAny},
+		{"operator schedules", "t-op", http.MethodPost, "/v1/monitor/schedules", `{"cron":"@hourly","job":` + start + `}}`, http.StatusForbidden, PermSchedulesWrite},
+		{"admin stops another's job", "t-admin", http.MethodDelete, "/v1/monitor/jobs/j1", "", http.StatusOK, ""},
+		{"admin preempts", "t-admin", http.MethodPost, "/v1/monitor/jobs", start + `,"priority":10}`, http.StatusCreated, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != tt.code {
+				t.Fatalf("code=%d want=%d; body=%s", rr.Code, tt.code, rr.Body.String())
+			}
+			if tt.perm != "" {
+				if got := decodeBody[map[string]string](t, rr); got["error"] != "forbidden" || got["permission"] != string(tt.perm) {
+					t.Fatalf("unexpected body %v", got)
+				}
+			}
+		})
+	}
+	if tc.startReq.Owner != "root-bot" {
+		t.Fatalf("the last job should be owned by its starter, got %q", tc.startReq.Owner)
+	}
+
+	// operators manage their own jobs
+	tc.getJobResp.Owner = "op"
+	for _, method := range []string{http.MethodDelete, http.MethodPatch} {
+		req := httptest.NewRequest(method, "/v1/monitor/jobs/j1", bytes.NewBufferString(`{"duration_sec":60}`))
+		req.Header.Set("Authorization", "Bearer t-op")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s own job: code=%d; body=%s", method, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestPolicy_Role(t *testing.T) {
+	p := testPolicy()
+	p.Subjects["spiffe://example.org/ops"] = "operator"
+	p.DefaultRole = "viewer"
+	for _, c := range []struct {
+		id   ClientIdentity
+		want string
+	}{
+		{ClientIdentity{Subject: "op"}, "operator"},
+		{ClientIdentity{Subject: "bot", SANs: []string{"spiffe://example.org/ops"}}, "operator"},
+		{ClientIdentity{Peer: &PeerCred{UID: 0, User: "root"}}, "admin"},
+		{ClientIdentity{Peer: &PeerCred{UID: 1000, User: "root-bot"}}, "admin"},
+		{ClientIdentity{Peer: &PeerCred{UID: 1000}}, "viewer"},
+		{ClientIdentity{Token: "somebody"}, "viewer"},
+	} {
+		if got := p.role(c.id); got != c.want {
+			t.Fatalf("role(%+v) = %q, want %q", c.id, got, c.want)
+		}
+	}
+}
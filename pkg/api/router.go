@@ -10,16 +10,54 @@ import (
 
 func NewRouter(h *Handlers) http.Handler {
 	r := chi.NewRouter()
+	r.Use(h.IdentityMiddleware)
 	r.Use(h.LoggingMiddleware)
+	r.Use(h.BodyLimitMiddleware)
+	r.Use(h.ContractMiddleware)
+	// rate limits apply before permissions so refused callers are counted
+	// too; IdentityMiddleware counts unknown tokens itself
+	limRead, limWrite := h.Limit(ClassRead), h.Limit(ClassWrite)
+	read := chi.Chain(limRead, h.Require(PermJobsRead))
+	start := chi.Chain(limWrite, h.Require(PermJobsStart))
+	manage := chi.Chain(limWrite, h.Require(PermJobsManage))
+	// probes are neither rate-limited nor subject to RBAC
+	r.Get("/healthz", h.Healthz)
+	r.Get("/readyz", h.Readyz)
+	if h.Metrics != nil {
+		r.With(limRead, h.Require(PermAgentRead)).Method(http.MethodGet, "/metrics", h.Metrics)
+	}
 	r.Route("/v1", func(r chi.Router) {
+		r.With(limRead).Get("/openapi.yaml", h.OpenAPI)
+		r.With(start...).Post("/monitor/jobs:validate", h.ValidateJob)
 		r.Route("/monitor/jobs", func(r chi.Router) {
-			r.Post("/", h.StartJob)
+			r.With(read...).Get("/", h.ListJobs)
+			r.With(start...).Post("/", h.StartJob)
 			r.Route("/{job_id}", func(r chi.Router) {
-				r.Get("/", h.GetJob)
-				r.Delete("/", h.StopJob)
-				r.Get("/results", h.GetResults)
+				r.With(read...).Get("/", h.GetJob)
+				r.With(manage...).Patch("/", h.UpdateJob)
+				r.With(manage...).Delete("/", h.StopJob)
+				r.With(read...).Get("/results", h.GetResults)
 			})
 		})
+		if h.Schedules != nil {
+			r.Route("/monitor/schedules", func(r chi.Router) {
+				sread := chi.Chain(limRead, h.Require(PermSchedulesRead))
+				swrite := chi.Chain(limWrite, h.Require(PermSchedulesWrite))
+				r.With(sread...).Get("/", h.ListSchedules)
+				r.With(swrite...).Post("/", h.CreateSchedule)
+				r.Route("/{schedule_id}", func(r chi.Router) {
+					r.With(sread...).Get("/", h.GetSchedule)
+					r.With(swrite...).Put("/", h.UpdateSchedule)
+					r.With(swrite...).Delete("/", h.DeleteSchedule)
+				})
+			})
+		}
+		if h.Events != nil {
+			r.With(read...).Get("/events", h.StreamEvents)
+		}
+		if h.Agent != nil {
+			r.With(limRead, h.Require(PermAgentRead)).Get("/agent", h.GetAgent)
+		}
 	})
 	return r
 }
@@ -33,7 +33,7 @@ func TestRouter_SuccessPaths(t *testing.T) {
 	defer srv.Close()
 
 	// StartJob
-	body := `{"port":"Ethernet0","direction":"ingress","sample_rate":1}`
+	body := `{"port":"Ethernet0","direction":"ingress","span_method":"erspan","duration_sec":60,"sample_rate":1}`
 	resp, err := http.Post(srv.URL+"/v1/monitor/jobs", "application/json", bytes.NewBufferString(body))
 	if err != nil {
 		t.Fatalf("post: %v", err)
@@ -45,6 +45,15 @@ func TestRouter_SuccessPaths(t *testing.T) {
 		t.Fatalf("expected TryStartJob called")
 	}
 
+	// ListJobs
+	resp, _ = http.Get(srv.URL + "/v1/monitor/jobs?state=running")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("ListJobs wrong status: %d", resp.StatusCode)
+	}
+	if !tc.listCalled {
+		t.Fatalf("expected ListJobs called")
+	}
+
 	// GetJob
 	resp, _ = http.Get(srv.URL + "/v1/monitor/jobs/j123")
 	if resp.StatusCode != http.StatusOK {
@@ -54,8 +63,21 @@ func TestRouter_SuccessPaths(t *testing.T) {
 		t.Fatalf("expected GetJob called")
 	}
 
+	// UpdateJob
+	req, _ := http.NewRequest(http.MethodPatch, srv.URL+"/v1/monitor/jobs/j123", bytes.NewBufferString(`{"duration_sec":300}`))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("UpdateJob wrong status: %d", resp.StatusCode)
+	}
+	if !tc.updateCalled || tc.updateID != "j123" {
+		t.Fatalf("expected UpdateJob called for j123")
+	}
+
 	// StopJob
-	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/v1/monitor/jobs/j123", nil)
+	req, _ = http.NewRequest(http.MethodDelete, srv.URL+"/v1/monitor/jobs/j123", nil)
 	resp, err = http.DefaultClient.Do(req)
 	if err != nil {
 		t.Fatalf("delete: %v", err)
@@ -79,7 +101,7 @@ func TestRouter_SuccessPaths(t *testing.T) {
 
 func TestRouter_ErrorPaths(t *testing.T) {
 	tc := &testCore{
-		tryStartCode: http.StatusBadRequest,
+		tryStartCode: http.StatusConflict,
 		tryStartErr:  errors.New("fail-start"),
 		getJobCode:   http.StatusNotFound,
 		getJobErr:    errors.New("fail-get"),
@@ -87,6 +109,8 @@ func TestRouter_ErrorPaths(t *testing.T) {
 		stopErr:      errors.New("fail-stop"),
 		resultsCode:  http.StatusInternalServerError,
 		resultsErr:   errors.New("fail-results"),
+		updateCode:   http.StatusConflict,
+		updateErr:    errors.New("fail-update"),
 	}
 	h := &Handlers{Core: tc}
 	srv := httptest.NewServer(NewRouter(h))
@@ -95,18 +119,21 @@ func TestRouter_ErrorPaths(t *testing.T) {
 	tests := []struct {
 		method string
 		path   string
+		body   string
 		code   int
 	}{
-		{http.MethodPost, "/v1/monitor/jobs", http.StatusBadRequest},
-		{http.MethodGet, "/v1/monitor/jobs/xx", http.StatusNotFound},
-		{http.MethodDelete, "/v1/monitor/jobs/xx", http.StatusConflict},
-		{http.MethodGet, "/v1/monitor/jobs/xx/results", http.StatusInternalServerError},
+		{http.MethodPost, "/v1/monitor/jobs", `{}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/monitor/jobs", `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":5}`, http.StatusConflict},
+		{http.MethodGet, "/v1/monitor/jobs/xx", "", http.StatusNotFound},
+		{http.MethodDelete, "/v1/monitor/jobs/xx", "", http.StatusConflict},
+		{http.MethodGet, "/v1/monitor/jobs/xx/results", "", http.StatusInternalServerError},
+		{http.MethodPatch, "/v1/monitor/jobs/xx", `{}`, http.StatusConflict},
 	}
 
 	for _, tc := range tests {
 		var req *http.Request
-		if tc.method == http.MethodPost {
-			req, _ = http.NewRequest(tc.method, srv.URL+tc.path, bytes.NewBufferString(`{}`))
+		if tc.body != "" {
+			req, _ = http.NewRequest(tc.method, srv.URL+tc.path, bytes.NewBufferString(tc.body))
 		} else {
 			req, _ = http.NewRequest(tc.method, srv.URL+tc.path, nil)
 		}
@@ -0,0 +1,75 @@
+//go:build linux
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handlers) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	var req ScheduleRequest
+	if ps := decodeStrict(r.Body, &req); len(ps) > 0 {
+		writeProblems(w, ps)
+		return
+	}
+	if ps := validateSchedule(req); len(ps) > 0 {
+		writeProblems(w, ps)
+		return
+	}
+	resp, code, err := h.Schedules.CreateSchedule(req)
+	if err != nil {
+		writeFailure(w, code, "schedule_failed", err)
+		return
+	}
+	writeJSON(w, code, resp)
+}
+
+func (h *Handlers) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	resp, code, err := h.Schedules.ListSchedules()
+	if err != nil {
+		writeJSON(w, code, map[string]string{"error": "list_failed", "message": err.Error()})
+		return
+	}
+	writeJSON(w, code, resp)
+}
+
+func (h *Handlers) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "schedule_id")
+	resp, code, err := h.Schedules.GetSchedule(id)
+	if err != nil {
+		writeJSON(w, code, map[string]string{"error": "not_found", "message": err.Error()})
+		return
+	}
+	writeJSON(w, code, resp)
+}
+
+func (h *Handlers) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "schedule_id")
+	var req ScheduleRequest
+	if ps := decodeStrict(r.Body, &req); len(ps) > 0 {
+		writeProblems(w, ps)
+		return
+	}
+	if ps := validateSchedule(req); len(ps) > 0 {
+		writeProblems(w, ps)
+		return
+	}
+	resp, code, err := h.Schedules.UpdateSchedule(id, req)
+	if err != nil {
+		writeFailure(w, code, "schedule_failed", err)
+		return
+	}
+	writeJSON(w, code, resp)
+}
+
+func (h *Handlers) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "schedule_id")
+	code, err := h.Schedules.DeleteSchedule(id)
+	if err != nil {
+		writeJSON(w, code, map[string]string{"error": "delete_failed", "message": err.Error()})
+		return
+	}
+	w.WriteHeader(code)
+}
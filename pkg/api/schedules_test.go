@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testScheduleCore is a scripted ScheduleCore.
+type testScheduleCore struct {
+	createReq  ScheduleRequest
+	updateID   string
+	deletedID  string
+	resp       Schedule
+	code       int
+	err        error
+	listResp   ListSchedulesResponse
+	deleteCode int
+}
+
+func (t *testScheduleCore) reply() (Schedule, int, error) {
+	code := t.code
+	if code == 0 {
+		code = http.StatusOK
+	}
+	return t.resp, code, t.err
+}
+
+func (t *testScheduleCore) CreateSchedule(req ScheduleRequest) (Schedule, int, error) {
+	t.createReq = req
+	if t.code == 0 && t.err == nil {
+		return t.resp, http.StatusCreated, nil
+	}
+	return t.reply()
+}
+
+func (t *testScheduleCore) ListSchedules() (ListSchedulesResponse, int, error) {
+	return t.listResp, http.StatusOK, nil
+}
+
+func (t *testScheduleCore) GetSchedule(id string) (Schedule, int, error) {
+	return t.reply()
+}
+
+func (t *testScheduleCore) UpdateSchedule(id string, req ScheduleRequest) (Schedule, int, error) {
+	t.updateID = id
+	return t.reply()
+}
+
+func (t *testScheduleCore) DeleteSchedule(id string) (int, error) {
+	t.deletedID = id
+	if t.deleteCode == 0 {
+		return http.StatusNoContent, nil
+	}
+	return t.deleteCode, t.err
+}
+
+func TestSchedules_Routes(t *testing.T) {
+	sc := &testScheduleCore{
+		resp:     Schedule{ScheduleID: "s1", Cron: "0 * * * *", Policy: "skip", Enabled: true},
+		listResp: ListSchedulesResponse{Schedules: []Schedule{{ScheduleID: "s1"}}},
+	}
+	srv := httptest.NewServer(NewRouter(&Handlers{Core: &testCore{}, Schedules: sc}))
+	defer srv.Close()
+
+	body := `{"name":"hourly","cron":"0 * * * *","job":{"port":"Ethernet16","direction":"both","span_method":"erspan","duration_sec":60}}`
+	resp, err := http.Post(srv.URL+"/v1/monitor/schedules", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("CreateSchedule wrong status: %d", resp.StatusCode)
+	}
+	if sc.createReq.Cron != "0 * * * *" || sc.createReq.Job.Port != "Ethernet16" || sc.createReq.Job.DurationSec != 60 {
+		t.Fatalf("request not passed through: %+v", sc.createReq)
+	}
+
+	for _, tc := range []struct {
+		method, path, body string
+		code               int
+	}{
+		{http.MethodGet, "/v1/monitor/schedules", "", http.StatusOK},
+		{http.MethodGet, "/v1/monitor/schedules/s1", "", http.StatusOK},
+		{http.MethodPut, "/v1/monitor/schedules/s1", body, http.StatusOK},
+		{http.MethodPut, "/v1/monitor/schedules/s1", "{", http.StatusBadRequest},
+		{http.MethodDelete, "/v1/monitor/schedules/s1", "", http.StatusNoContent},
+	} {
+		req, _ := http.NewRequest(tc.method, srv.URL+tc.path, bytes.NewBufferString(tc.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.method, tc.path, err)
+		}
+		if resp.StatusCode != tc.code {
+			t.Fatalf("%s %s: got %d, want %d", tc.method, tc.path, resp.StatusCode, tc.code)
+		}
+	}
+	if sc.updateID != "s1" || sc.deletedID != "s1" {
+		t.Fatalf("ids not passed through: update=%q delete=%q", sc.updateID, sc.deletedID)
+	}
+}
+
+func TestSchedules_Errors(t *testing.T) {
+	sc := &testScheduleCore{code: http.StatusNotFound, err: errors.New("schedule not found"), deleteCode: http.StatusNotFound}
+	h := &Handlers{Core: &testCore{}, Schedules: sc}
+
+	rr := httptest.NewRecorder()
+	h.GetSchedule(rr, makeReqWithRouteParam(http.MethodGet, "/v1/monitor/schedules/x", "schedule_id", "x", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("GetSchedule code=%d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	h.DeleteSchedule(rr, makeReqWithRouteParam(http.MethodDelete, "/v1/monitor/schedules/x", "schedule_id", "x", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("DeleteSchedule code=%d", rr.Code)
+	}
+}
+
+func TestSchedules_NotMountedWithoutCore(t *testing.T) {
+	srv := httptest.NewServer(NewRouter(&Handlers{Core: &testCore{}}))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/v1/monitor/schedules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 without a ScheduleCore, got %d", resp.StatusCode)
+	}
+}
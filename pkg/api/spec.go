@@ -0,0 +1,535 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the part of an OpenAPI 3.1 description needed to check requests
+// and responses against it: paths, operations, parameters, bodies, and the
+// JSON Schema keywords api/openapi.yaml uses (type, enum, ranges, lengths,
+// properties, required, items, additionalProperties, oneOf, $ref and the
+// date-time and uri formats).
+type Spec struct {
+	doc   map[string]interface{}
+	base  string // path of the first server URL, e.g. /v1
+	paths []specPath
+}
+
+type specPath struct {
+	template string   // e.g. /monitor/jobs/{job_id}
+	segments []string // template split on "/"
+	item     map[string]interface{}
+}
+
+// operation is the operation of a Spec a request is for.
+type operation struct {
+	method, path string // upper-case method and path template
+	op           map[string]interface{}
+	params       []map[string]interface{} // path item and operation parameters
+	vars         map[string]string        // path parameter values
+}
+
+var specMethods = []string{"get", "put", "post", "delete", "patch", "head", "options"}
+
+// LoadSpec parses an OpenAPI description and checks that every $ref in it
+// resolves.
+func LoadSpec(data []byte) (*Spec, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse openapi: %w", err)
+	}
+	s := &Spec{doc: doc}
+	if servers, _ := doc["servers"].([]interface{}); len(servers) > 0 {
+		if srv, ok := servers[0].(map[string]interface{}); ok {
+			u, err := url.Parse(fmt.Sprint(srv["url"]))
+			if err != nil {
+				return nil, fmt.Errorf("openapi: server url: %w", err)
+			}
+			s.base = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+	paths, ok := doc["paths"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("openapi: no paths")
+	}
+	for _, tmpl := range sortedKeys(paths) {
+		item, ok := paths[tmpl].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("openapi: path %s is not an object", tmpl)
+		}
+		s.paths = append(s.paths, specPath{template: tmpl, segments: strings.Split(strings.Trim(tmpl, "/"), "/"), item: item})
+	}
+	if err := s.checkRefs(doc); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Spec) checkRefs(v interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			if _, err := s.lookup(ref); err != nil {
+				return err
+			}
+		}
+		for _, k := range sortedKeys(v) {
+			if err := s.checkRefs(v[k]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			if err := s.checkRefs(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// lookup resolves a local reference such as #/components/schemas/Error.
+func (s *Spec) lookup(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("openapi: only local references are supported: %s", ref)
+	}
+	var cur interface{} = s.doc
+	for _, part := range strings.Split(ref[2:], "/") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("openapi: unresolvable reference %s", ref)
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		if cur, ok = m[part]; !ok {
+			return nil, fmt.Errorf("openapi: unresolvable reference %s", ref)
+		}
+	}
+	m, ok := cur.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("openapi: reference %s is not an object", ref)
+	}
+	return m, nil
+}
+
+// resolve follows v's $ref, if any. LoadSpec has checked that it resolves.
+func (s *Spec) resolve(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	for m != nil {
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			break
+		}
+		m, _ = s.lookup(ref)
+	}
+	return m
+}
+
+// find returns the operation for method on urlPath. inBase reports
+// whether urlPath is under the Spec's server path at all; op is nil if the
+// Spec has no such path or the path has no such method.
+func (s *Spec) find(method, urlPath string) (op *operation, inBase bool) {
+	rest, ok := strings.CutPrefix(urlPath, s.base)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return nil, false
+	}
+	segs := strings.Split(strings.Trim(rest, "/"), "/")
+	var best *specPath
+	var bestVars map[string]string
+	bestTemplated := -1
+	for i := range s.paths {
+		p := &s.paths[i]
+		vars, templated, ok := p.match(segs)
+		if ok && (best == nil || templated < bestTemplated) {
+			best, bestVars, bestTemplated = p, vars, templated
+		}
+	}
+	if best == nil {
+		return nil, true
+	}
+	o, ok := best.item[strings.ToLower(method)].(map[string]interface{})
+	if !ok {
+		return nil, true
+	}
+	op = &operation{method: strings.ToUpper(method), path: best.template, op: o, vars: bestVars}
+	for _, list := range []interface{}{best.item["parameters"], o["parameters"]} {
+		ps, _ := list.([]interface{})
+		for _, p := range ps {
+			if p := s.resolve(p); p != nil {
+				op.params = append(op.params, p)
+			}
+		}
+	}
+	return op, true
+}
+
+// match reports whether segs fit the path template, with the values of
+// its parameters and how many segments were templated.
+func (p *specPath) match(segs []string) (map[string]string, int, bool) {
+	if len(segs) != len(p.segments) {
+		return nil, 0, false
+	}
+	vars := map[string]string{}
+	templated := 0
+	for i, seg := range p.segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if segs[i] == "" {
+				return nil, 0, false
+			}
+			vars[seg[1:len(seg)-1]] = segs[i]
+			templated++
+			continue
+		}
+		if seg != segs[i] {
+			return nil, 0, false
+		}
+	}
+	return vars, templated, true
+}
+
+// CheckRequest checks the parameters and body of r against the Spec.
+// Requests for paths or methods the Spec does not describe pass; the
+// router answers them.
+func (s *Spec) CheckRequest(r *http.Request, body []byte) []Problem {
+	op, _ := s.find(r.Method, r.URL.Path)
+	if op == nil {
+		return nil
+	}
+	var ps problems
+	query := r.URL.Query()
+	for _, p := range op.params {
+		name, _ := p["name"].(string)
+		var values []string
+		switch p["in"] {
+		case "path":
+			if v, ok := op.vars[name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = query[name]
+		case "header":
+			values = r.Header.Values(name)
+		default:
+			continue
+		}
+		if len(values) == 0 {
+			if req, _ := p["required"].(bool); req {
+				ps.add(name, CodeRequired, "%s is required", name)
+			}
+			continue
+		}
+		s.checkParam(name, s.resolve(p["schema"]), values, &ps)
+	}
+
+	rb := s.resolve(op.op["requestBody"])
+	if rb == nil {
+		return ps
+	}
+	if len(body) == 0 {
+		if req, _ := rb["required"].(bool); req {
+			ps.add("", CodeInvalidJSON, "request body is empty")
+		}
+		return ps
+	}
+	content, _ := rb["content"].(map[string]interface{})
+	media := s.resolve(content["application/json"])
+	if media == nil {
+		return ps
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		ps.add("", CodeInvalidJSON, "%v", err)
+		return ps
+	}
+	s.checkSchema(media["schema"], v, "", &ps)
+	return ps
+}
+
+// checkParam checks the values of a parameter; arrays take every value,
+// comma-separated or repeated, the other types the first.
+func (s *Spec) checkParam(name string, schema map[string]interface{}, values []string, ps *problems) {
+	if schema == nil {
+		return
+	}
+	if schemaTypes(schema)["array"] {
+		items := s.resolve(schema["items"])
+		var list []interface{}
+		for _, v := range values {
+			for _, e := range strings.Split(v, ",") {
+				list = append(list, paramValue(items, e))
+			}
+		}
+		s.checkSchema(schema, list, name, ps)
+		return
+	}
+	s.checkSchema(schema, paramValue(schema, values[0]), name, ps)
+}
+
+// paramValue converts a parameter value to the JSON value its schema
+// expects, leaving it a string if it does not parse.
+func paramValue(schema map[string]interface{}, v string) interface{} {
+	types := schemaTypes(schema)
+	switch {
+	case types["integer"] || types["number"]:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	case types["boolean"]:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+// CheckResponse checks a response to r against the Spec: that its status
+// is documented for the operation and its JSON body fits the schema.
+// Answers for paths the Spec does not describe are only accepted as 404
+// or 405.
+func (s *Spec) CheckResponse(r *http.Request, status int, header http.Header, body []byte) []Problem {
+	op, inBase := s.find(r.Method, r.URL.Path)
+	if !inBase {
+		return nil
+	}
+	var ps problems
+	if op == nil {
+		if status != http.StatusNotFound && status != http.StatusMethodNotAllowed {
+			ps.add("", CodeInvalid, "%s %s is not described, yet answered %d", r.Method, r.URL.Path, status)
+		}
+		return ps
+	}
+	responses, _ := op.op["responses"].(map[string]interface{})
+	var resp map[string]interface{}
+	for _, key := range []string{strconv.Itoa(status), fmt.Sprintf("%dXX", status/100), "default"} {
+		if resp = s.resolve(responses[key]); resp != nil {
+			break
+		}
+	}
+	if resp == nil {
+		ps.add("", CodeInvalid, "status %d is not documented for %s %s", status, op.method, op.path)
+		return ps
+	}
+	content, _ := resp["content"].(map[string]interface{})
+	if len(content) == 0 {
+		if len(body) > 0 {
+			ps.add("", CodeInvalid, "%d to %s %s has a body but none is documented", status, op.method, op.path)
+		}
+		return ps
+	}
+	mt, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	media := s.resolve(content[mt])
+	if media == nil {
+		ps.add("", CodeInvalid, "content type %q is not documented for %d to %s %s", mt, status, op.method, op.path)
+		return ps
+	}
+	if mt != "application/json" {
+		return ps
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		ps.add("", CodeInvalidJSON, "%v", err)
+		return ps
+	}
+	s.checkSchema(media["schema"], v, "", &ps)
+	return ps
+}
+
+// checkSchema checks a decoded JSON value against a schema. JSON numbers
+// arrive as float64.
+func (s *Spec) checkSchema(schema interface{}, v interface{}, field string, ps *problems) {
+	sch := s.resolve(schema)
+	if sch == nil {
+		return
+	}
+	name := field
+	if name == "" {
+		name = "body"
+	}
+	if alts, ok := sch["oneOf"].([]interface{}); ok {
+		n := 0
+		for _, alt := range alts {
+			var sub problems
+			s.checkSchema(alt, v, field, &sub)
+			if len(sub) == 0 {
+				n++
+			}
+		}
+		if n != 1 {
+			ps.add(field, CodeInvalid, "%s must match exactly one of %d schemas, matches %d", name, len(alts), n)
+			return
+		}
+	}
+	if types := schemaTypes(sch); len(types) > 0 && !types[jsonType(v)] && !(types["number"] && jsonType(v) == "integer") {
+		ps.add(field, CodeInvalidType, "%s must be a JSON %s", name, strings.Join(sortedKeys(types), " or "))
+		return
+	}
+	if enum, ok := sch["enum"].([]interface{}); ok && !inEnum(enum, v) {
+		allowed := make([]string, len(enum))
+		for i, e := range enum {
+			allowed[i] = fmt.Sprintf("%q", fmt.Sprint(e))
+		}
+		ps.add(field, CodeInvalid, "%s must be one of %s", name, strings.Join(allowed, ", "))
+		return
+	}
+
+	switch v := v.(type) {
+	case float64:
+		if min, ok := number(sch["minimum"]); ok && v < min {
+			ps.add(field, CodeInvalid, "%s must be at least %v", name, min)
+		}
+		if max, ok := number(sch["maximum"]); ok && v > max {
+			ps.add(field, CodeInvalid, "%s must be at most %v", name, max)
+		}
+		if min, ok := number(sch["exclusiveMinimum"]); ok && v <= min {
+			ps.add(field, CodeInvalid, "%s must be more than %v", name, min)
+		}
+	case string:
+		if n, ok := number(sch["minLength"]); ok && float64(utf8.RuneCountInString(v)) < n {
+			ps.add(field, CodeInvalid, "%s must be at least %v characters", name, n)
+		}
+		if n, ok := number(sch["maxLength"]); ok && float64(utf8.RuneCountInString(v)) > n {
+			ps.add(field, CodeInvalid, "%s must be at most %v characters", name, n)
+		}
+		switch sch["format"] {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				ps.add(field, CodeInvalid, "%s must be an RFC 3339 date-time", name)
+			}
+		case "uri":
+			if u, err := url.Parse(v); err != nil || !u.IsAbs() {
+				ps.add(field, CodeInvalid, "%s must be an absolute URI", name)
+			}
+		}
+	case []interface{}:
+		if n, ok := number(sch["maxItems"]); ok && float64(len(v)) > n {
+			ps.add(field, CodeInvalid, "%s must have at most %v items", name, n)
+		}
+		for i, e := range v {
+			s.checkSchema(sch["items"], e, fmt.Sprintf("%s[%d]", field, i), ps)
+		}
+	case map[string]interface{}:
+		s.checkObject(sch, v, field, ps)
+	}
+}
+
+func (s *Spec) checkObject(sch map[string]interface{}, v map[string]interface{}, field string, ps *problems) {
+	join := func(k string) string {
+		if field == "" {
+			return k
+		}
+		return field + "." + k
+	}
+	required, _ := sch["required"].([]interface{})
+	for _, k := range required {
+		if _, ok := v[fmt.Sprint(k)]; !ok {
+			ps.add(join(fmt.Sprint(k)), CodeRequired, "%s is required", join(fmt.Sprint(k)))
+		}
+	}
+	if n, ok := number(sch["maxProperties"]); ok && float64(len(v)) > n {
+		ps.add(field, CodeInvalid, "%s must have at most %v properties", field, n)
+	}
+	props, _ := sch["properties"].(map[string]interface{})
+	for _, k := range sortedKeys(v) {
+		if prop, ok := props[k]; ok {
+			s.checkSchema(prop, v[k], join(k), ps)
+			continue
+		}
+		switch extra := sch["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				ps.add(join(k), CodeUnknownField, "unknown field %q", join(k))
+			}
+		case map[string]interface{}:
+			s.checkSchema(extra, v[k], join(k), ps)
+		}
+	}
+}
+
+// schemaTypes returns the types a schema allows; "type" may be one type or
+// a list such as [string, "null"].
+func schemaTypes(sch map[string]interface{}) map[string]bool {
+	types := map[string]bool{}
+	switch t := sch["type"].(type) {
+	case string:
+		types[t] = true
+	case []interface{}:
+		for _, e := range t {
+			types[fmt.Sprint(e)] = true
+		}
+	}
+	return types
+}
+
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func inEnum(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		if n, ok := number(e); ok {
+			if f, isNum := v.(float64); isNum && f == n {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// number converts a YAML number to float64.
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// operations lists the operations of the Spec as "METHOD /path".
+func (s *Spec) operations() []string {
+	var out []string
+	for _, p := range s.paths {
+		for _, m := range specMethods {
+			if _, ok := p.item[m]; ok {
+				out = append(out, strings.ToUpper(m)+" "+p.template)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -9,9 +9,16 @@ import (
 type testCore struct {
 	// capture
 	startCalled   bool
+	startReq      StartJobRequest
+	validateReq   *StartJobRequest
 	getCalled     bool
 	stopCalled    bool
 	resultsCalled bool
+	listCalled    bool
+	listReq       ListJobsRequest
+	updateCalled  bool
+	updateID      string
+	updateReq     UpdateJobRequest
 
 	// scripted returns
 	tryStartResp StartJobResponse
@@ -29,10 +36,21 @@ type testCore struct {
 	resultsResp JobResults
 	resultsCode int
 	resultsErr  error
+
+	listResp ListJobsResponse
+	listCode int
+	listErr  error
+
+	updateResp JobStatus
+	updateCode int
+	updateErr  error
+
+	validateResp ValidateJobResponse
 }
 
 func (t *testCore) TryStartJob(req StartJobRequest) (StartJobResponse, int, error) {
 	t.startCalled = true
+	t.startReq = req
 	code := t.tryStartCode
 	if code == 0 {
 		code = http.StatusCreated
@@ -40,6 +58,11 @@ func (t *testCore) TryStartJob(req StartJobRequest) (StartJobResponse, int, erro
 	return t.tryStartResp, code, t.tryStartErr
 }
 
+func (t *testCore) ValidateJob(req StartJobRequest) (ValidateJobResponse, int, error) {
+	t.validateReq = &req
+	return t.validateResp, http.StatusOK, nil
+}
+
 func (t *testCore) GetJob(id string) (JobStatus, int, error) {
 	t.getCalled = true
 	code := t.getJobCode
@@ -66,3 +89,24 @@ func (t *testCore) GetResults(id string) (JobResults, int, error) {
 	}
 	return t.resultsResp, code, t.resultsErr
 }
+
+func (t *testCore) ListJobs(req ListJobsRequest) (ListJobsResponse, int, error) {
+	t.listCalled = true
+	t.listReq = req
+	code := t.listCode
+	if code == 0 {
+		code = http.StatusOK
+	}
+	return t.listResp, code, t.listErr
+}
+
+func (t *testCore) UpdateJob(id string, req UpdateJobRequest) (JobStatus, int, error) {
+	t.updateCalled = true
+	t.updateID = id
+	t.updateReq = req
+	code := t.updateCode
+	if code == 0 {
+		code = http.StatusOK
+	}
+	return t.updateResp, code, t.updateErr
+}
@@ -0,0 +1,110 @@
+//go:build linux
+
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader serves the API's certificate and the CA bundle client
+// certificates are verified against, and loads them again when their files
+// change so they can be rotated without restarting the agent.
+type CertReloader struct {
+	certFile, keyFile, caFile string
+
+	mu    sync.RWMutex
+	cert  *tls.Certificate
+	pool  *x509.CertPool
+	stamp string // size and mtime of the files at the last load
+}
+
+// NewCertReloader loads the server certificate and key and the client CA
+// bundle, all PEM.
+func NewCertReloader(certFile, keyFile, caFile string) (*CertReloader, error) {
+	c := &CertReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if _, err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload loads the files again if any of them changed since the last load
+// and reports whether it did. If loading fails, the previous certificate
+// and CAs stay in use.
+func (c *CertReloader) Reload() (bool, error) {
+	stamp := ""
+	for _, f := range []string{c.certFile, c.keyFile, c.caFile} {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return false, err
+		}
+		stamp += fmt.Sprintf("%d/%d;", fi.Size(), fi.ModTime().UnixNano())
+	}
+	c.mu.RLock()
+	same := stamp == c.stamp
+	c.mu.RUnlock()
+	if same {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("load server certificate: %w", err)
+	}
+	pem, err := os.ReadFile(c.caFile)
+	if err != nil {
+		return false, fmt.Errorf("read client CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return false, fmt.Errorf("no certificates in %s", c.caFile)
+	}
+	c.mu.Lock()
+	c.cert, c.pool, c.stamp = &cert, pool, stamp
+	c.mu.Unlock()
+	return true, nil
+}
+
+// TLSConfig returns a server config that requires a client certificate
+// signed by one of the CAs. Every handshake uses the files as last loaded.
+func (c *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*c.cert},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    c.pool,
+			}, nil
+		},
+	}
+}
+
+// Watch checks the files for changes every interval until ctx is done.
+func (c *CertReloader) Watch(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			changed, err := c.Reload()
+			if err != nil {
+				log.Printf("tls: keeping the current certificates: %v", err)
+			} else if changed {
+				log.Printf("tls: reloaded %s and %s", c.certFile, c.caFile)
+			}
+		}
+	}
+}
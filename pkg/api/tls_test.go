@@ -0,0 +1,200 @@
+//go:build linux
+
+package api
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for the TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for name, usable by a server on
+// 127.0.0.1 or by a client.
+func (ca *testCA) issue(t *testing.T, name string, serial int64) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kb, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb})
+}
+
+func writeFile(t *testing.T, path string, b []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tlsClient trusts ca for the server and presents the given certificate,
+// if any.
+func tlsClient(t *testing.T, ca *testCA, certPEM, keyPEM []byte) *http.Client {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	cfg := &tls.Config{RootCAs: roots}
+	if certPEM != nil {
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}, Timeout: 5 * time.Second}
+}
+
+func TestCertReloader_RequiresClientCertificate(t *testing.T) {
+	ca := newTestCA(t, "test-ca")
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt")
+	srvCert, srvKey := ca.issue(t, "agent", 2)
+	writeFile(t, certFile, srvCert)
+	writeFile(t, keyFile, srvKey)
+	writeFile(t, caFile, ca.pem)
+
+	certs, err := NewCertReloader(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatalf("NewCertReloader: %v", err)
+	}
+	tc := &testCore{}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: NewRouter(&Handlers{Core: tc}), TLSConfig: certs.TLSConfig()}
+	go func() { _ = srv.ServeTLS(ln, "", "") }()
+	defer srv.Close()
+	base := "https://" + ln.Addr().String()
+	body := `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":5}`
+
+	if _, err := tlsClient(t, ca, nil, nil).Get(base + "/v1/monitor/jobs"); err == nil {
+		t.Fatalf("a client without a certificate should be refused")
+	}
+	other := newTestCA(t, "other-ca")
+	strangerCert, strangerKey := other.issue(t, "stranger", 3)
+	if _, err := tlsClient(t, ca, strangerCert, strangerKey).Get(base + "/v1/monitor/jobs"); err == nil {
+		t.Fatalf("a certificate from another CA should be refused")
+	}
+
+	opsCert, opsKey := ca.issue(t, "netops-bot", 4)
+	resp, err := tlsClient(t, ca, opsCert, opsKey).Post(base+"/v1/monitor/jobs", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if tc.startReq.Owner != "netops-bot" {
+		t.Fatalf("job owner = %q, want the client's common name", tc.startReq.Owner)
+	}
+
+	// rotate to a new CA: clients of the old one are refused from then on
+	newCA := newTestCA(t, "new-ca")
+	srvCert, srvKey = newCA.issue(t, "agent", 5)
+	writeFile(t, certFile, srvCert)
+	writeFile(t, keyFile, srvKey)
+	writeFile(t, caFile, newCA.pem)
+	if changed, err := certs.Reload(); err != nil || !changed {
+		t.Fatalf("Reload: changed=%v err=%v", changed, err)
+	}
+	if changed, err := certs.Reload(); err != nil || changed {
+		t.Fatalf("unchanged files should not be reloaded: changed=%v err=%v", changed, err)
+	}
+	if _, err := tlsClient(t, ca, opsCert, opsKey).Get(base + "/v1/monitor/jobs"); err == nil {
+		t.Fatalf("the old CA should no longer be trusted")
+	}
+	newCert, newKey := newCA.issue(t, "netops-bot", 6)
+	resp, err = tlsClient(t, newCA, newCert, newKey).Get(base + "/v1/monitor/jobs")
+	if err != nil {
+		t.Fatalf("get with the rotated certificates: %v", err)
+	}
+	resp.Body.Close()
+
+	// a broken file keeps the last good certificates in use
+	writeFile(t, certFile, []byte("not a certificate"))
+	if _, err := certs.Reload(); err == nil {
+		t.Fatalf("expected an error for a broken certificate")
+	}
+	resp, err = tlsClient(t, newCA, newCert, newKey).Get(base + "/v1/monitor/jobs")
+	if err != nil {
+		t.Fatalf("get after a failed reload: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewCertReloader_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewCertReloader(filepath.Join(dir, "a"), filepath.Join(dir, "b"), filepath.Join(dir, "c")); err == nil {
+		t.Fatalf("expected an error for missing files")
+	}
+	ca := newTestCA(t, "test-ca")
+	certPEM, keyPEM := ca.issue(t, "agent", 2)
+	writeFile(t, filepath.Join(dir, "server.crt"), certPEM)
+	writeFile(t, filepath.Join(dir, "server.key"), keyPEM)
+	writeFile(t, filepath.Join(dir, "ca.crt"), []byte("garbage"))
+	if _, err := NewCertReloader(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt")); err == nil {
+		t.Fatalf("expected an error for a CA bundle without certificates")
+	}
+}
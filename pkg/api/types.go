@@ -3,30 +3,139 @@ package api
 import "time"
 
 type StartJobRequest struct {
-	Port        string                 `json:"port"`
-	Direction   string                 `json:"direction"` // ingress|egress|both
-	SpanMethod  string                 `json:"span_method"` // span|erspan
-	VLAN        *int                   `json:"vlan,omitempty"`
-	Filters     map[string]interface{} `json:"filters,omitempty"`
-	SampleRate  int                    `json:"sample_rate"`
-	DurationSec int                    `json:"duration_sec"`
-	OTLPExport  bool                   `json:"otlp_export"`
-	ResultDetail string                `json:"result_detail"` // summary|flows|pcaplike
+	Port         string                 `json:"port"`
+	Ports        []string               `json:"ports,omitempty"` // instead of port; PortChannels expand to their members
+	Direction    string                 `json:"direction"`       // ingress|egress|both
+	SpanMethod   string                 `json:"span_method"`     // span|erspan
+	VLAN         *int                   `json:"vlan,omitempty"`
+	Filters      map[string]interface{} `json:"filters,omitempty"`
+	SampleRate   int                    `json:"sample_rate"`
+	DurationSec  int                    `json:"duration_sec"`
+	OTLPExport   bool                   `json:"otlp_export"`
+	ResultDetail string                 `json:"result_detail"` // summary|flows|pcaplike
+	AllowShared  bool                   `json:"allow_shared,omitempty"`
+	Priority     int                    `json:"priority,omitempty"` // 0-100; higher preempts lower when the gate is full
+
+	// Optional budgets; the job stops early once any is used up.
+	MaxPackets    uint64  `json:"max_packets,omitempty"`
+	MaxBytes      uint64  `json:"max_bytes,omitempty"`
+	MaxCPUSeconds float64 `json:"max_cpu_seconds,omitempty"` // data-plane CPU time
+
+	Notify *Notify `json:"notify,omitempty"`
+
+	Labels      map[string]string `json:"labels,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// Notify asks the agent to POST the job's final JobResults to WebhookURL
+// once it is done or failed.
+type Notify struct {
+	WebhookURL string `json:"webhook_url"`
 }
 
 type StartJobResponse struct {
-	JobID    string `json:"job_id"`
-	Status   string `json:"status"`
-	Interface string `json:"interface"`
+	JobID         string `json:"job_id"`
+	Status        string `json:"status"`
+	Interface     string `json:"interface"`
+	QueuePosition int    `json:"queue_position,omitempty"` // set when status is "queued"
+
+	// PreemptedJobID is the lower-priority job stopped to make room; the
+	// new job is queued until that job has been torn down.
+	PreemptedJobID string `json:"preempted_job_id,omitempty"`
+}
+
+// ValidateJobResponse is the answer to POST /v1/monitor/jobs:validate (or
+// POST /v1/monitor/jobs?dry_run=true): the problems the agent found with a
+// StartJobRequest and what it would do with the job right now.
+type ValidateJobResponse struct {
+	Valid        bool      `json:"valid"`
+	Admission    string    `json:"admission"`                // start|queue|preempt|reject
+	PreemptJobID string    `json:"preempt_job_id,omitempty"` // set when admission is "preempt"
+	Problems     []Problem `json:"problems"`
+}
+
+// BadRequestResponse is the 400 answer to a request body that fails
+// validation; Message repeats the first of the problems.
+type BadRequestResponse struct {
+	Error    string    `json:"error"` // always "bad_request"
+	Message  string    `json:"message"`
+	Problems []Problem `json:"problems"`
+}
+
+// InvalidRequestError is returned by Core methods that reject a request for
+// reasons tied to its fields, such as the agent's limits; handlers report
+// it like a body that failed their own validation.
+type InvalidRequestError struct {
+	Problems []Problem
+}
+
+func (e *InvalidRequestError) Error() string { return e.Problems[0].Message }
+
+// Problem is one reason a job request would fail. Field is the request
+// field at fault, empty for problems with the agent itself.
+type Problem struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
 
 type JobStatus struct {
 	JobID     string    `json:"job_id"`
 	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
 	StartedAt time.Time `json:"started_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 	Port      string    `json:"port"`
 	Interface string    `json:"interface"`
+
+	// Ports echoes the request's ports; Members lists every port the job
+	// covers once PortChannels are expanded, for jobs with ports or a
+	// PortChannel.
+	Ports   []string    `json:"ports,omitempty"`
+	Members []JobMember `json:"members,omitempty"`
+
+	QueuePosition int `json:"queue_position,omitempty"` // 1-based, while queued
+
+	EndedAt       *time.Time        `json:"ended_at,omitempty"`
+	FailureReason string            `json:"failure_reason,omitempty"`
+	StepErrors    map[string]string `json:"step_errors,omitempty"` // mirror|attach|collect|teardown
+	StopReason    string            `json:"stop_reason,omitempty"` // expired|stopped|budget_exceeded|shutdown|preempted
+
+	SampleRate int                    `json:"sample_rate,omitempty"`
+	Filters    map[string]interface{} `json:"filters,omitempty"`
+
+	Labels      map[string]string `json:"labels,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Description string            `json:"description,omitempty"`
+
+	Priority    int    `json:"priority,omitempty"`
+	PreemptedBy string `json:"preempted_by,omitempty"` // set when stop_reason is "preempted"
+}
+
+// UpdateJobRequest is the body of PATCH /v1/monitor/jobs/{job_id}; omitted
+// fields are left unchanged.
+type UpdateJobRequest struct {
+	DurationSec int                    `json:"duration_sec,omitempty"` // new total duration from start
+	SampleRate  int                    `json:"sample_rate,omitempty"`
+	Filters     map[string]interface{} `json:"filters,omitempty"`
+}
+
+// ListJobsRequest carries the query of GET /v1/monitor/jobs.
+type ListJobsRequest struct {
+	States []string          // state=running,done
+	Port   string            // port=Ethernet0
+	Owner  string            // owner=alice
+	Labels map[string]string // label=team=netops (repeatable or comma-separated; all must match)
+	Since  time.Time         // since=RFC3339 (inclusive, on created_at)
+	Until  time.Time         // until=RFC3339 (exclusive, on created_at)
+	Limit  int               // limit=50
+	Cursor string            // cursor=<next_cursor of the previous page>
+}
+
+type ListJobsResponse struct {
+	Jobs       []JobStatus `json:"jobs"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
 
 type StopJobResponse struct {
@@ -34,14 +143,36 @@ type StopJobResponse struct {
 	Status string `json:"status"`
 }
 
+// JobMember is one port of a multi-port job and its mirror interface,
+// which is empty until the job has started.
+type JobMember struct {
+	Port      string `json:"port"`
+	Interface string `json:"interface,omitempty"`
+}
+
 type JobResults struct {
-	WindowSec int            `json:"window_sec"`
-	Packets   uint64         `json:"packets_total"`
-	Bytes     uint64         `json:"bytes_total"`
-	Errors    map[string]uint64 `json:"errors"`
-	TopFlows  []TopFlow      `json:"top_flows"`
-	LatencyHistogramNs Histogram `json:"latency_histogram_ns"`
-	OTLPExport OTLPInfo      `json:"otel_export"`
+	WindowSec          int               `json:"window_sec"`
+	Packets            uint64            `json:"packets_total"`
+	Bytes              uint64            `json:"bytes_total"`
+	Errors             map[string]uint64 `json:"errors"`
+	TopFlows           []TopFlow         `json:"top_flows"`
+	LatencyHistogramNs Histogram         `json:"latency_histogram_ns"`
+	OTLPExport         OTLPInfo          `json:"otel_export"`
+	StopReason         string            `json:"stop_reason,omitempty"`
+	CPUSeconds         float64           `json:"cpu_seconds,omitempty"` // only measured with max_cpu_seconds
+
+	// Members breaks the totals down by member port for jobs with ports
+	// or a PortChannel.
+	Members []MemberResults `json:"members,omitempty"`
+}
+
+// MemberResults is one member port's share of a job's totals. Members
+// whose mirrors share an interface report that interface's totals.
+type MemberResults struct {
+	Port      string `json:"port"`
+	Interface string `json:"interface"`
+	Packets   uint64 `json:"packets_total"`
+	Bytes     uint64 `json:"bytes_total"`
 }
 
 type TopFlow struct {
@@ -59,3 +190,101 @@ type OTLPInfo struct {
 	Exported bool   `json:"exported"`
 	Endpoint string `json:"endpoint"`
 }
+
+// ScheduleRequest creates or replaces a recurring job.
+type ScheduleRequest struct {
+	Name    string          `json:"name,omitempty"`
+	Cron    string          `json:"cron"`              // "0 * * * *" or @hourly, agent local time
+	Policy  string          `json:"policy,omitempty"`  // skip|queue when all slots are busy
+	Enabled *bool           `json:"enabled,omitempty"` // defaults to true
+	Job     StartJobRequest `json:"job"`
+}
+
+type Schedule struct {
+	ScheduleID string          `json:"schedule_id"`
+	Name       string          `json:"name,omitempty"`
+	Cron       string          `json:"cron"`
+	Policy     string          `json:"policy"`
+	Enabled    bool            `json:"enabled"`
+	Job        StartJobRequest `json:"job"`
+	CreatedAt  time.Time       `json:"created_at"`
+	NextRun    *time.Time      `json:"next_run,omitempty"`
+	History    []ScheduleRun   `json:"history"` // oldest first
+}
+
+// ScheduleRun is one firing of a schedule and the job it started, if any.
+type ScheduleRun struct {
+	At     time.Time `json:"at"`
+	JobID  string    `json:"job_id,omitempty"`
+	Status string    `json:"status"` // started|queued|skipped|failed
+	Error  string    `json:"error,omitempty"`
+}
+
+type ListSchedulesResponse struct {
+	Schedules []Schedule `json:"schedules"`
+}
+
+// Event is a job state change streamed on /v1/events.
+type Event struct {
+	ID     uint64    `json:"id"`
+	Time   time.Time `json:"time"`
+	JobID  string    `json:"job_id"`
+	State  string    `json:"state"`            // queued|starting|running|stopping|done|failed
+	Reason string    `json:"reason,omitempty"` // failure_reason or stop_reason
+}
+
+// AgentInfo is the answer to GET /v1/agent: the build, the kernel and BPF
+// pieces the agent depends on, how collection and export are going, and
+// whether the agent is ready to run jobs.
+type AgentInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date,omitempty"`
+
+	KernelRelease  string      `json:"kernel_release"`
+	BTF            bool        `json:"btf"` // /sys/kernel/btf/vmlinux is present
+	BPFObject      string      `json:"bpf_object"`
+	BPFObjectFound bool        `json:"bpf_object_found"`
+	PinDir         string      `json:"pin_dir"`
+	Maps           []PinnedMap `json:"maps"`
+
+	Collector  CollectorStatus `json:"collector"`
+	OTLPExport ExporterStatus  `json:"otlp_export"`
+	Jobs       JobCounts       `json:"jobs"`
+
+	Ready    bool     `json:"ready"`
+	NotReady []string `json:"not_ready,omitempty"` // why, when Ready is false
+}
+
+// PinnedMap is one of the BPF maps the agent opens from the pin directory.
+// Without a required map the agent is not ready.
+type PinnedMap struct {
+	Name     string `json:"name"`
+	Pinned   bool   `json:"pinned"`
+	Required bool   `json:"required"`
+	Error    string `json:"error,omitempty"`
+}
+
+// CollectorStatus is how the metrics collector's scrapes of the BPF maps
+// are going.
+type CollectorStatus struct {
+	Running    bool       `json:"running"`
+	LastScrape *time.Time `json:"last_scrape,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+}
+
+// ExporterStatus is how the OTLP exporter's pushes are going. LastError is
+// the error of the last push, empty once a push succeeds again.
+type ExporterStatus struct {
+	Endpoint    string     `json:"endpoint"`
+	LastExport  *time.Time `json:"last_export,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// JobCounts are the jobs holding a slot and waiting for one.
+type JobCounts struct {
+	Active        int `json:"active"`
+	Queued        int `json:"queued"`
+	MaxConcurrent int `json:"max_concurrent"`
+}
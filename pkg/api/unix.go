@@ -0,0 +1,154 @@
+//go:build linux
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// PeerCred is the process on the other end of a Unix socket connection, as
+// the kernel reported it (SO_PEERCRED) when it connected.
+type PeerCred struct {
+	PID  int32
+	UID  uint32
+	GID  uint32 // primary group; supplementary groups are not reported
+	User string // name of UID, empty if it has none
+}
+
+// UnixSocket configures ListenUnix.
+type UnixSocket struct {
+	Path        string
+	Mode        os.FileMode
+	Group       string // group name or gid given the socket file; "" keeps the agent's
+	AllowedUIDs []int  // with either list set, only root and listed peers are accepted
+	AllowedGIDs []int
+}
+
+// ListenUnix listens on a Unix socket, replacing one left behind by a
+// previous run. Connections from peers the allow lists do not cover are
+// closed as soon as they are accepted.
+func ListenUnix(s UnixSocket) (net.Listener, error) {
+	gid := -1
+	if s.Group != "" {
+		g, err := user.LookupGroup(s.Group)
+		if err != nil {
+			g, err = user.LookupGroupId(s.Group)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("socket group %s: %w", s.Group, err)
+		}
+		gid, _ = strconv.Atoi(g.Gid)
+	}
+	if fi, err := os.Lstat(s.Path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", s.Path)
+		}
+		if err := os.Remove(s.Path); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", s.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(s.Path, s.Mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	if gid >= 0 {
+		if err := os.Chown(s.Path, -1, gid); err != nil {
+			ln.Close()
+			return nil, err
+		}
+	}
+	return &peerListener{Listener: ln, uids: s.AllowedUIDs, gids: s.AllowedGIDs}, nil
+}
+
+type peerListener struct {
+	net.Listener
+	uids, gids []int
+}
+
+// peerConn carries the credentials checked on accept to ConnContext.
+type peerConn struct {
+	net.Conn
+	cred PeerCred
+}
+
+func (l *peerListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		cred, err := peerCred(c)
+		if err != nil {
+			log.Printf("unix socket: %v", err)
+			c.Close()
+			continue
+		}
+		if !l.allows(cred) {
+			log.Printf("unix socket: refused uid %d gid %d (pid %d)", cred.UID, cred.GID, cred.PID)
+			c.Close()
+			continue
+		}
+		return &peerConn{Conn: c, cred: cred}, nil
+	}
+}
+
+func (l *peerListener) allows(c PeerCred) bool {
+	if len(l.uids) == 0 && len(l.gids) == 0 {
+		return true
+	}
+	return c.UID == 0 || slices.Contains(l.uids, int(c.UID)) || slices.Contains(l.gids, int(c.GID))
+}
+
+func peerCred(c net.Conn) (PeerCred, error) {
+	uc, ok := c.(*net.UnixConn)
+	if !ok {
+		return PeerCred{}, fmt.Errorf("not a unix connection: %T", c)
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return PeerCred{}, err
+	}
+	var ucred *unix.Ucred
+	var serr error
+	if err := raw.Control(func(fd uintptr) {
+		ucred, serr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return PeerCred{}, err
+	}
+	if serr != nil {
+		return PeerCred{}, fmt.Errorf("SO_PEERCRED: %w", serr)
+	}
+	cred := PeerCred{PID: ucred.Pid, UID: ucred.Uid, GID: ucred.Gid}
+	if u, err := user.LookupId(strconv.FormatUint(uint64(cred.UID), 10)); err == nil {
+		cred.User = u.Username
+	}
+	return cred, nil
+}
+
+type peerKey struct{}
+
+// ConnContext is the http.Server hook that makes the peer credentials of a
+// Unix socket connection available to IdentityMiddleware.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	if pc, ok := c.(*peerConn); ok {
+		return context.WithValue(ctx, peerKey{}, pc.cred)
+	}
+	return ctx
+}
@@ -0,0 +1,101 @@
+//go:build linux
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func unixClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
+func TestListenUnix_ServesPeersWithTheirCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "api.sock")
+	// a socket left behind by a previous run is replaced
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err := ListenUnix(UnixSocket{Path: path, Mode: 0o600, Group: fmt.Sprint(os.Getgid())})
+	if err != nil {
+		t.Fatalf("ListenUnix: %v", err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil || fi.Mode().Perm() != 0o600 {
+		t.Fatalf("socket mode = %v (%v)", fi.Mode(), err)
+	}
+
+	tc := &testCore{}
+	srv := &http.Server{Handler: NewRouter(&Handlers{Core: tc}), ConnContext: ConnContext}
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Close()
+
+	body := `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":5}`
+	resp, err := unixClient(path).Post("http://agent/v1/monitor/jobs", "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	want := fmt.Sprintf("uid:%d", os.Getuid())
+	if u, err := user.LookupId(strconv.Itoa(os.Getuid())); err == nil {
+		want = u.Username
+	}
+	if tc.startReq.Owner != want {
+		t.Fatalf("job owner = %q, want the peer's user %q", tc.startReq.Owner, want)
+	}
+}
+
+func TestListenUnix_RefusesNonSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+	if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ListenUnix(UnixSocket{Path: path, Mode: 0o660}); err == nil {
+		t.Fatalf("a regular file must not be replaced")
+	}
+}
+
+func TestPeerListener_Allows(t *testing.T) {
+	open := &peerListener{}
+	if !open.allows(PeerCred{UID: 1000, GID: 1000}) {
+		t.Fatalf("without allow lists every peer is served")
+	}
+	l := &peerListener{uids: []int{1001}, gids: []int{2000}}
+	for _, c := range []struct {
+		cred PeerCred
+		want bool
+	}{
+		{PeerCred{UID: 0, GID: 0}, true},
+		{PeerCred{UID: 1001, GID: 1001}, true},
+		{PeerCred{UID: 1002, GID: 2000}, true},
+		{PeerCred{UID: 1002, GID: 1002}, false},
+	} {
+		if got := l.allows(c.cred); got != c.want {
+			t.Fatalf("allows(%+v) = %v", c.cred, got)
+		}
+	}
+}
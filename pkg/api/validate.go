@@ -0,0 +1,253 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Codes of the problems reported when a request body fails validation.
+const (
+	CodeRequired     = "required"      // a mandatory field is missing
+	CodeInvalid      = "invalid"       // a field is out of range or not one of the allowed values
+	CodeInvalidType  = "invalid_type"  // a field has the wrong JSON type
+	CodeUnknownField = "unknown_field" // the API has no such field
+	CodeInvalidJSON  = "invalid_json"  // the body is not a JSON object
+	CodeTooLarge     = "too_large"     // the body is over the agent's size limit
+)
+
+// problems collects validation failures.
+type problems []Problem
+
+func (ps *problems) add(field, code, format string, args ...interface{}) {
+	*ps = append(*ps, Problem{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// oneOf checks an optional enum field; empty values are left to required.
+func (ps *problems) oneOf(field, v string, allowed ...string) {
+	if v == "" {
+		return
+	}
+	for _, a := range allowed {
+		if v == a {
+			return
+		}
+	}
+	ps.add(field, CodeInvalid, "%s must be one of %s", field, strings.Join(allowed, ", "))
+}
+
+// decodeStrict decodes a JSON body into v, rejecting fields v does not
+// have, and describes what went wrong if it fails.
+func decodeStrict(body io.Reader, v interface{}) []Problem {
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil {
+		return nil
+	}
+	var ps problems
+	var typeErr *json.UnmarshalTypeError
+	var sizeErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &sizeErr):
+		ps.add("", CodeTooLarge, "request body is larger than %d bytes", sizeErr.Limit)
+	case errors.As(err, &typeErr):
+		ps.add(typeErr.Field, CodeInvalidType, "%s cannot be a JSON %s", typeErr.Field, typeErr.Value)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		ps.add(field, CodeUnknownField, "unknown field %q", field)
+	case errors.Is(err, io.EOF):
+		ps.add("", CodeInvalidJSON, "request body is empty")
+	default:
+		ps.add("", CodeInvalidJSON, "%v", err)
+	}
+	return ps
+}
+
+func validateStartJob(r StartJobRequest) []Problem {
+	var ps problems
+	r.validate("", &ps)
+	return ps
+}
+
+// validate checks r against the StartJobRequest schema; prefix is
+// prepended to field names when r is nested in another request. Limits
+// such as the number of ports or labels, the length of owner and
+// description and the priority range are the agent's, which reports them
+// through Core.
+func (r StartJobRequest) validate(prefix string, ps *problems) {
+	f := func(name string) string { return prefix + name }
+
+	switch {
+	case r.Port == "" && len(r.Ports) == 0:
+		ps.add(f("port"), CodeRequired, "one of port or ports is required")
+	case r.Port != "" && len(r.Ports) > 0:
+		ps.add(f("ports"), CodeInvalid, "set either port or ports, not both")
+	}
+	seen := map[string]bool{}
+	for i, p := range r.Ports {
+		if p == "" || seen[p] {
+			ps.add(fmt.Sprintf("%s[%d]", f("ports"), i), CodeInvalid, "ports must be distinct and non-empty")
+		}
+		seen[p] = true
+	}
+
+	if r.Direction == "" {
+		ps.add(f("direction"), CodeRequired, "direction is required")
+	}
+	ps.oneOf(f("direction"), r.Direction, "ingress", "egress", "both")
+	if r.SpanMethod == "" {
+		ps.add(f("span_method"), CodeRequired, "span_method is required")
+	}
+	ps.oneOf(f("span_method"), r.SpanMethod, "span", "erspan")
+	ps.oneOf(f("result_detail"), r.ResultDetail, "summary", "flows", "pcaplike")
+
+	switch {
+	case r.DurationSec == 0:
+		ps.add(f("duration_sec"), CodeRequired, "duration_sec is required")
+	case r.DurationSec < 0:
+		ps.add(f("duration_sec"), CodeInvalid, "duration_sec must be at least 1")
+	}
+	if r.SampleRate < 0 {
+		ps.add(f("sample_rate"), CodeInvalid, "sample_rate must not be negative")
+	}
+	if r.VLAN != nil && (*r.VLAN < 1 || *r.VLAN > 4094) {
+		ps.add(f("vlan"), CodeInvalid, "vlan must be 1-4094")
+	}
+	if r.MaxCPUSeconds < 0 {
+		ps.add(f("max_cpu_seconds"), CodeInvalid, "max_cpu_seconds must not be negative")
+	}
+	validateFilters(f("filters"), r.Filters, ps)
+
+	if r.Notify != nil {
+		u, err := url.Parse(r.Notify.WebhookURL)
+		switch {
+		case r.Notify.WebhookURL == "":
+			ps.add(f("notify.webhook_url"), CodeRequired, "notify.webhook_url is required")
+		case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+			ps.add(f("notify.webhook_url"), CodeInvalid, "notify.webhook_url must be an absolute http(s) URL")
+		}
+	}
+}
+
+// filterChecks validates the value of each filter the data plane can
+// program. JSON numbers arrive as float64.
+var filterChecks = map[string]func(v interface{}) error{
+	"ip_proto": eachValue(func(v interface{}) error {
+		s, ok := v.(string)
+		if !ok {
+			return errors.New("must be a protocol name or a list of them")
+		}
+		switch strings.ToLower(s) {
+		case "tcp", "udp", "icmp", "icmpv6":
+			return nil
+		}
+		return fmt.Errorf("unsupported protocol %q; use tcp, udp, icmp or icmpv6", s)
+	}),
+	"l4_sport": eachValue(intIn(1, 65535, "a port")),
+	"l4_dport": eachValue(intIn(1, 65535, "a port")),
+}
+
+func validateFilters(field string, filters map[string]interface{}, ps *problems) {
+	for _, k := range sortedKeys(filters) {
+		check, ok := filterChecks[k]
+		if !ok {
+			ps.add(field+"."+k, CodeUnknownField, "unknown filter %q; use ip_proto, l4_sport or l4_dport", k)
+			continue
+		}
+		if filters[k] == nil {
+			continue
+		}
+		if err := check(filters[k]); err != nil {
+			ps.add(field+"."+k, CodeInvalid, "%s.%s %v", field, k, err)
+		}
+	}
+}
+
+// eachValue applies check to v, or to every element if v is a list.
+func eachValue(check func(interface{}) error) func(interface{}) error {
+	return func(v interface{}) error {
+		list, ok := v.([]interface{})
+		if !ok {
+			return check(v)
+		}
+		for _, e := range list {
+			if err := check(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func intIn(min, max int, what string) func(interface{}) error {
+	return func(v interface{}) error {
+		n, ok := v.(float64)
+		if !ok || n != float64(int(n)) || int(n) < min || int(n) > max {
+			return fmt.Errorf("must be %s (%d-%d) or a list of them", what, min, max)
+		}
+		return nil
+	}
+}
+
+// mergeProblems adds to ours what the agent found about fields we did not
+// already report on, e.g. its limits, the port or the host. The agent names
+// only the top-level field, so "filters" is taken as covered by
+// "filters.l4_dport".
+func mergeProblems(ours, agent []Problem) []Problem {
+	covered := map[string]bool{}
+	for _, p := range ours {
+		covered[p.Field] = true
+		covered[fieldRoot(p.Field)] = true
+	}
+	for _, p := range agent {
+		if p.Field == "" || !covered[p.Field] {
+			ours = append(ours, p)
+		}
+	}
+	return ours
+}
+
+// fieldRoot is the top-level field of a problem's field name.
+func fieldRoot(field string) string {
+	if i := strings.IndexAny(field, ".["); i >= 0 {
+		return field[:i]
+	}
+	return field
+}
+
+func validateUpdateJob(r UpdateJobRequest) []Problem {
+	var ps problems
+	if r.DurationSec < 0 {
+		ps.add("duration_sec", CodeInvalid, "duration_sec must not be negative")
+	}
+	if r.SampleRate < 0 {
+		ps.add("sample_rate", CodeInvalid, "sample_rate must not be negative")
+	}
+	validateFilters("filters", r.Filters, &ps)
+	return ps
+}
+
+func validateSchedule(r ScheduleRequest) []Problem {
+	var ps problems
+	if r.Cron == "" {
+		ps.add("cron", CodeRequired, "cron is required")
+	}
+	ps.oneOf("policy", r.Policy, "skip", "queue")
+	r.Job.validate("job.", &ps)
+	return ps
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
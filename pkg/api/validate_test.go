@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func problemCodes(ps []Problem) map[string]string {
+	out := map[string]string{}
+	for _, p := range ps {
+		out[p.Field] = p.Code
+	}
+	return out
+}
+
+func TestValidateStartJob(t *testing.T) {
+	valid := `"port":"Ethernet0","direction":"ingress","span_method":"erspan","duration_sec":30`
+	tests := []struct {
+		name  string
+		body  string
+		field string
+		code  string
+	}{
+		{"missing port", `{"direction":"ingress","span_method":"span","duration_sec":30}`, "port", CodeRequired},
+		{"port and ports", `{` + valid + `,"ports":["Ethernet4"]}`, "ports", CodeInvalid},
+		{"duplicate ports", `{"ports":["Ethernet0","Ethernet0"],"direction":"ingress","span_method":"span","duration_sec":30}`, "ports[1]", CodeInvalid},
+		{"missing direction", `{"port":"Ethernet0","span_method":"span","duration_sec":30}`, "direction", CodeRequired},
+		{"unknown direction", `{"port":"Ethernet0","direction":"sideways","span_method":"span","duration_sec":30}`, "direction", CodeInvalid},
+		{"unknown span_method", `{"port":"Ethernet0","direction":"ingress","span_method":"rspan","duration_sec":30}`, "span_method", CodeInvalid},
+		{"zero duration", `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":0}`, "duration_sec", CodeRequired},
+		{"negative duration", `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":-5}`, "duration_sec", CodeInvalid},
+		{"negative sample_rate", `{` + valid + `,"sample_rate":-1}`, "sample_rate", CodeInvalid},
+		{"vlan out of range", `{` + valid + `,"vlan":4095}`, "vlan", CodeInvalid},
+		{"unknown result_detail", `{` + valid + `,"result_detail":"everything"}`, "result_detail", CodeInvalid},
+		{"relative webhook", `{` + valid + `,"notify":{"webhook_url":"/hook"}}`, "notify.webhook_url", CodeInvalid},
+		{"unknown filter", `{` + valid + `,"filters":{"vni":5}}`, "filters.vni", CodeUnknownField},
+		{"bad protocol", `{` + valid + `,"filters":{"ip_proto":["tcp","sctp"]}}`, "filters.ip_proto", CodeInvalid},
+		{"port out of range", `{` + valid + `,"filters":{"l4_dport":[443,70000]}}`, "filters.l4_dport", CodeInvalid},
+		{"port as string", `{` + valid + `,"filters":{"l4_sport":"443"}}`, "filters.l4_sport", CodeInvalid},
+		{"unsupported filter", `{` + valid + `,"filters":{"dscp":46}}`, "filters.dscp", CodeUnknownField},
+		{"unsupported cidr", `{` + valid + `,"filters":{"src_cidr":"10.0.0.0/8"}}`, "filters.src_cidr", CodeUnknownField},
+		{"unknown field", `{` + valid + `,"durration_sec":5}`, "durration_sec", CodeUnknownField},
+		{"wrong type", `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":"30"}`, "duration_sec", CodeInvalidType},
+		{"not json", `port=Ethernet0`, "", CodeInvalidJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req StartJobRequest
+			ps := decodeStrict(strings.NewReader(tt.body), &req)
+			if len(ps) == 0 {
+				ps = validateStartJob(req)
+			}
+			got := problemCodes(ps)
+			if code, ok := got[tt.field]; !ok || code != tt.code {
+				t.Fatalf("want %s=%s, got %+v", tt.field, tt.code, ps)
+			}
+		})
+	}
+
+	var req StartJobRequest
+	body := `{` + valid + `,"sample_rate":10,"vlan":100,"filters":{"ip_proto":"tcp","l4_dport":[443,8443],"l4_sport":null}}`
+	if ps := decodeStrict(strings.NewReader(body), &req); len(ps) != 0 {
+		t.Fatalf("decode: %+v", ps)
+	}
+	if ps := validateStartJob(req); len(ps) != 0 {
+		t.Fatalf("expected a valid request, got %+v", ps)
+	}
+}
+
+func TestValidateStartJob_ListsEveryProblem(t *testing.T) {
+	ps := validateStartJob(StartJobRequest{Direction: "sideways", SampleRate: -1})
+	got := problemCodes(ps)
+	for f, code := range map[string]string{
+		"port": CodeRequired, "direction": CodeInvalid, "span_method": CodeRequired,
+		"duration_sec": CodeRequired, "sample_rate": CodeInvalid,
+	} {
+		if got[f] != code {
+			t.Fatalf("%s: code %q, want %q (all: %+v)", f, got[f], code, ps)
+		}
+	}
+}
+
+func TestValidateUpdateJob_RejectsNegatives(t *testing.T) {
+	ps := validateUpdateJob(UpdateJobRequest{DurationSec: -1, SampleRate: -1})
+	want := map[string]string{
+		"duration_sec": "duration_sec must not be negative",
+		"sample_rate":  "sample_rate must not be negative",
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("expected %d problems, got %+v", len(want), ps)
+	}
+	for _, p := range ps {
+		if p.Code != CodeInvalid || p.Message != want[p.Field] {
+			t.Fatalf("unexpected problem %+v", p)
+		}
+	}
+	if ps := validateUpdateJob(UpdateJobRequest{}); len(ps) != 0 {
+		t.Fatalf("zero fields leave the job unchanged, got %+v", ps)
+	}
+}
+
+func TestValidateSchedule_PrefixesJobFields(t *testing.T) {
+	got := problemCodes(validateSchedule(ScheduleRequest{Policy: "drop", Job: StartJobRequest{Port: "Ethernet0"}}))
+	if got["cron"] != CodeRequired || got["policy"] != CodeInvalid || got["job.direction"] != CodeRequired {
+		t.Fatalf("unexpected problems: %v", got)
+	}
+}
+
+func TestHandlers_RejectInvalidBodies(t *testing.T) {
+	tc := &testCore{}
+	sc := &testScheduleCore{}
+	router := NewRouter(&Handlers{Core: tc, Schedules: sc})
+	bad := `{"port":"Ethernet0","direction":"sideways","span_method":"span","duration_sec":30}`
+
+	for _, r := range []struct{ method, path, body string }{
+		{http.MethodPost, "/v1/monitor/jobs", bad},
+		{http.MethodPatch, "/v1/monitor/jobs/j1", `{"filters":{"l4_dport":0}}`},
+		{http.MethodPatch, "/v1/monitor/jobs/j1", `{"direction":"egress"}`},
+		{http.MethodPatch, "/v1/monitor/jobs/j1", `{"filters":{"dst_cidr":"10.0.0.0/8"}}`},
+		{http.MethodPost, "/v1/monitor/schedules", `{"cron":"@hourly","job":` + bad + `}`},
+		{http.MethodPut, "/v1/monitor/schedules/s1", `{"cron":"@hourly","job":` + bad + `}`},
+	} {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(r.method, r.path, bytes.NewBufferString(r.body)))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s %s: code=%d; body=%s", r.method, r.path, rr.Code, rr.Body.String())
+		}
+		got := decodeBody[BadRequestResponse](t, rr)
+		if got.Error != "bad_request" || len(got.Problems) == 0 || got.Message != got.Problems[0].Message {
+			t.Fatalf("%s %s: unexpected body %+v", r.method, r.path, got)
+		}
+	}
+	if tc.startCalled || tc.updateCalled || sc.createReq.Cron != "" || sc.updateID != "" {
+		t.Fatalf("invalid requests must not reach the agent")
+	}
+
+	// a dry run lists these problems alongside what the agent found
+	tc.validateResp = ValidateJobResponse{Valid: false, Admission: "start", Problems: []Problem{
+		{Field: "direction", Code: CodeInvalid, Message: "invalid job request: direction must be ingress, egress or both"},
+		{Code: "bpf_object_missing", Message: "missing BPF object"},
+	}}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/monitor/jobs?dry_run=true", bytes.NewBufferString(bad)))
+	got := decodeBody[ValidateJobResponse](t, rr)
+	if rr.Code != http.StatusOK || got.Valid || got.Admission != "start" || len(got.Problems) != 2 {
+		t.Fatalf("dry run: code=%d body=%+v", rr.Code, got)
+	}
+	if got.Problems[0].Field != "direction" || got.Problems[0].Message != "direction must be one of ingress, egress, both" || got.Problems[1].Code != "bpf_object_missing" {
+		t.Fatalf("dry run: unexpected problems %+v", got.Problems)
+	}
+}
+
+func TestHandlers_AgentProblems(t *testing.T) {
+	tc := &testCore{tryStartCode: http.StatusBadRequest, tryStartErr: &InvalidRequestError{Problems: []Problem{
+		{Field: "labels", Code: CodeInvalid, Message: "invalid job metadata: at most 32 labels are allowed"},
+		{Field: "owner", Code: CodeInvalid, Message: "invalid job metadata: owner is longer than 128 bytes"},
+	}}}
+	router := NewRouter(&Handlers{Core: tc})
+	body := `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":30}`
+
+	// limits are the agent's; its problems are reported like ours
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/monitor/jobs", bytes.NewBufferString(body)))
+	got := decodeBody[BadRequestResponse](t, rr)
+	if rr.Code != http.StatusBadRequest || got.Error != "bad_request" || len(got.Problems) != 2 || got.Message != got.Problems[0].Message {
+		t.Fatalf("code=%d body=%+v", rr.Code, got)
+	}
+
+	// a dry run keeps the agent's problems on fields we did not report on
+	tc.validateResp = ValidateJobResponse{Admission: "start", Problems: []Problem{
+		{Field: "filters", Code: CodeInvalid, Message: "invalid job request: filters.l4_dport: ..."},
+		{Field: "owner", Code: CodeInvalid, Message: "invalid job metadata: owner is longer than 128 bytes"},
+		{Code: "bpf_object_missing", Message: "missing BPF object"},
+	}}
+	bad := `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":30,"filters":{"l4_dport":0}}`
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/monitor/jobs:validate", bytes.NewBufferString(bad)))
+	v := decodeBody[ValidateJobResponse](t, rr)
+	fields := []string{}
+	for _, p := range v.Problems {
+		fields = append(fields, p.Field+"/"+p.Code)
+	}
+	if v.Valid || strings.Join(fields, ",") != "filters.l4_dport/invalid,owner/invalid,/bpf_object_missing" {
+		t.Fatalf("problems = %v", fields)
+	}
+}
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is where the agent looks for its config file when neither
+// -config nor TELEGEN_CONFIG is set.
+const DefaultPath = "/etc/telegen-sonic/agent.yaml"
+
+// Config mirrors configs/agent.yaml.
+type Config struct {
+	Server   Server   `yaml:"server"`
+	Limits   Limits   `yaml:"limits"`
+	Export   Export   `yaml:"export"`
+	Security Security `yaml:"security"`
+	State    State    `yaml:"state"`
+	Notify   Notify   `yaml:"notify"`
+}
+
+type Server struct {
+	Listen             string     `yaml:"listen"`               // TCP address; "" serves the socket only
+	ShutdownTimeoutSec int        `yaml:"shutdown_timeout_sec"` // how long SIGTERM waits for jobs to tear down
+	Socket             Socket     `yaml:"socket"`
+	MaxBodyBytes       int64      `yaml:"max_body_bytes"` // larger request bodies get 413; 0 is unlimited
+	RateLimit          RateLimits `yaml:"rate_limit"`
+	ValidateOpenAPI    bool       `yaml:"validate_openapi"` // check requests and responses against api/openapi.yaml
+	GRPCListen         string     `yaml:"grpc_listen"`      // TCP address of the gRPC job API; "" disables it
+}
+
+// RateLimits are the API rate limits of each client, per route class.
+type RateLimits struct {
+	Read  RateLimit `yaml:"read"`  // GETs and the event stream
+	Write RateLimit `yaml:"write"` // starting, stopping and modifying jobs and schedules
+}
+
+// RateLimit is a token bucket per client: Burst requests at once, refilled
+// at Rate per second. A zero Rate disables it.
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// Socket is an optional Unix socket the API is also served on. Peers are
+// identified by their SO_PEERCRED credentials; when AllowedUIDs or
+// AllowedGIDs are set, only root and the listed users and primary groups
+// are served. Otherwise the socket file's mode and group decide who may
+// connect.
+type Socket struct {
+	Path        string `yaml:"path"`  // "" disables the socket
+	Mode        string `yaml:"mode"`  // octal file mode, e.g. "0660"
+	Group       string `yaml:"group"` // group name or gid owning the socket file; "" keeps the agent's
+	AllowedUIDs []int  `yaml:"allowed_uids"`
+	AllowedGIDs []int  `yaml:"allowed_gids"`
+}
+
+// FileMode parses Mode.
+func (s Socket) FileMode() (os.FileMode, error) {
+	m, err := strconv.ParseUint(s.Mode, 8, 32)
+	if err != nil || m > 0o777 {
+		return 0, fmt.Errorf("server.socket.mode must be an octal file mode like \"0660\" (got %q)", s.Mode)
+	}
+	return os.FileMode(m), nil
+}
+
+type Limits struct {
+	MaxConcurrentJobs  int `yaml:"max_concurrent_jobs"`
+	DefaultDurationSec int `yaml:"default_duration_sec"`
+	DefaultSampleRate  int `yaml:"default_sample_rate"`
+	TopKFlows          int `yaml:"topk_flows"`
+	MaxJobsQueue       int `yaml:"max_jobs_queue"`   // 0 disables queueing (429 when full)
+	QueueTTLSec        int `yaml:"queue_ttl_sec"`    // how long a job may wait in the queue
+	MaxDurationSec     int `yaml:"max_duration_sec"` // cap for starting or extending a job; 0 is unbounded
+}
+
+type Export struct {
+	OTLPEndpoint string `yaml:"otlp_endpoint"`
+	IntervalSec  int    `yaml:"interval_sec"`
+	// JobLabelAttributes lists the job label keys attached to job metrics.
+	JobLabelAttributes []string `yaml:"job_label_attributes"`
+	// DisableOTLP turns off the OTLP push, for switches that are only
+	// scraped through Prometheus.
+	DisableOTLP bool       `yaml:"disable_otlp"`
+	Prometheus  Prometheus `yaml:"prometheus"`
+}
+
+// Prometheus exposes the agent's metrics at /metrics for scraping. With an
+// empty Listen they are served on the API listeners, behind the API's
+// authentication and the agent:read permission; otherwise on a separate
+// plain-HTTP listener with neither.
+type Prometheus struct {
+	Enabled bool   `yaml:"enabled"`
+	Listen  string `yaml:"listen"` // e.g. ":9464"
+}
+
+// Security controls how API clients are authenticated. With Auth "mtls"
+// the API is served over TLS and every client must present a certificate
+// signed by a CA in CAFile; an empty Auth serves plain HTTP. The files are
+// reloaded when they change.
+type Security struct {
+	Auth     string `yaml:"auth"`      // "mtls" | ""
+	CAFile   string `yaml:"ca_file"`   // PEM bundle of the CAs that sign client certificates
+	CertFile string `yaml:"cert_file"` // server certificate chain (PEM)
+	KeyFile  string `yaml:"key_file"`
+	RBAC     RBAC   `yaml:"rbac"`
+}
+
+// RBAC maps API callers to the viewer, operator and admin roles. Callers
+// are identified by a bearer token, their client certificate or their
+// Unix socket peer credentials.
+type RBAC struct {
+	Enabled     bool          `yaml:"enabled"`
+	DefaultRole string        `yaml:"default_role"` // role of identified callers without a binding; "" refuses them
+	Tokens      []BearerToken `yaml:"tokens"`
+	Bindings    []RoleBinding `yaml:"bindings"`
+}
+
+// BearerToken names the caller presenting Token in an Authorization header.
+type BearerToken struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
+}
+
+type RoleBinding struct {
+	Role     string   `yaml:"role"`     // viewer | operator | admin
+	Subjects []string `yaml:"subjects"` // certificate common names or SANs, token names, Unix user names
+	UIDs     []int    `yaml:"uids"`     // Unix socket peers
+}
+
+// State controls where job history is persisted. An empty Dir keeps jobs
+// in memory only. Finished jobs are evicted after RetentionSec (0 keeps
+// them forever).
+type State struct {
+	Dir          string `yaml:"dir"`
+	RetentionSec int    `yaml:"retention_sec"`
+}
+
+// Notify controls delivery of job results to notify.webhook_url. Payloads
+// are signed with HMACSecret when it is set.
+type Notify struct {
+	HMACSecret  string `yaml:"hmac_secret"`
+	MaxAttempts int    `yaml:"max_attempts"`
+	BackoffSec  int    `yaml:"backoff_sec"` // delay before the first retry, doubled after each one
+	TimeoutSec  int    `yaml:"timeout_sec"` // per attempt
+}
+
+// Default returns the built-in defaults documented in the Wiki.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Listen:             "127.0.0.1:8080",
+			ShutdownTimeoutSec: 30,
+			Socket:             Socket{Mode: "0660"},
+			MaxBodyBytes:       64 << 10,
+			RateLimit: RateLimits{
+				Read:  RateLimit{Rate: 20, Burst: 40},
+				Write: RateLimit{Rate: 2, Burst: 10},
+			},
+		},
+		Limits: Limits{
+			MaxConcurrentJobs:  2,
+			DefaultDurationSec: 120,
+			DefaultSampleRate:  100,
+			TopKFlows:          1024,
+			QueueTTLSec:        300,
+			MaxDurationSec:     3600,
+		},
+		Export: Export{IntervalSec: 10},
+		Security: Security{
+			CAFile:   "/etc/telegen-sonic/tls/ca.crt",
+			CertFile: "/etc/telegen-sonic/tls/server.crt",
+			KeyFile:  "/etc/telegen-sonic/tls/server.key",
+		},
+		State:  State{Dir: "/var/lib/telegen-sonic/jobs", RetentionSec: 86400},
+		Notify: Notify{MaxAttempts: 5, BackoffSec: 2, TimeoutSec: 10},
+	}
+}
+
+// Load reads path on top of Default. A missing file is reported as an
+// error wrapping fs.ErrNotExist so callers can fall back to defaults.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("read config: %w", err)
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c Config) Validate() error {
+	if c.Limits.MaxConcurrentJobs < 1 {
+		return fmt.Errorf("limits.max_concurrent_jobs must be >= 1 (got %d)", c.Limits.MaxConcurrentJobs)
+	}
+	if c.Limits.MaxJobsQueue < 0 {
+		return fmt.Errorf("limits.max_jobs_queue must be >= 0 (got %d)", c.Limits.MaxJobsQueue)
+	}
+	if c.Limits.MaxJobsQueue > 0 && c.Limits.QueueTTLSec < 1 {
+		return fmt.Errorf("limits.queue_ttl_sec must be >= 1 when queueing is enabled")
+	}
+	if c.Limits.MaxDurationSec < 0 {
+		return fmt.Errorf("limits.max_duration_sec must be >= 0 (got %d)", c.Limits.MaxDurationSec)
+	}
+	if c.Limits.MaxDurationSec > 0 && c.Limits.DefaultDurationSec > c.Limits.MaxDurationSec {
+		return fmt.Errorf("limits.default_duration_sec exceeds limits.max_duration_sec")
+	}
+	if c.State.RetentionSec < 0 {
+		return fmt.Errorf("state.retention_sec must be >= 0 (got %d)", c.State.RetentionSec)
+	}
+	if c.Server.ShutdownTimeoutSec < 1 {
+		return fmt.Errorf("server.shutdown_timeout_sec must be >= 1 (got %d)", c.Server.ShutdownTimeoutSec)
+	}
+	if c.Notify.MaxAttempts < 1 {
+		return fmt.Errorf("notify.max_attempts must be >= 1 (got %d)", c.Notify.MaxAttempts)
+	}
+	if c.Notify.BackoffSec < 0 || c.Notify.TimeoutSec < 1 {
+		return fmt.Errorf("notify.backoff_sec must be >= 0 and notify.timeout_sec >= 1")
+	}
+	if c.Server.Listen == "" && c.Server.Socket.Path == "" {
+		return fmt.Errorf("server.listen or server.socket.path must be set")
+	}
+	if c.Server.Socket.Path != "" {
+		if _, err := c.Server.Socket.FileMode(); err != nil {
+			return err
+		}
+	}
+	if c.Server.MaxBodyBytes < 0 {
+		return fmt.Errorf("server.max_body_bytes must be >= 0 (got %d)", c.Server.MaxBodyBytes)
+	}
+	for name, l := range map[string]RateLimit{"read": c.Server.RateLimit.Read, "write": c.Server.RateLimit.Write} {
+		if l.Rate < 0 || (l.Rate > 0 && l.Burst < 1) {
+			return fmt.Errorf("server.rate_limit.%s needs rate >= 0 and, when rate is set, burst >= 1", name)
+		}
+	}
+	if c.Server.GRPCListen != "" && c.Server.GRPCListen == c.Server.Listen {
+		return fmt.Errorf("server.grpc_listen must differ from server.listen")
+	}
+	if p := c.Export.Prometheus; p.Listen != "" && p.Listen == c.Server.Listen {
+		return fmt.Errorf("export.prometheus.listen must differ from server.listen; leave it empty to share the API listener")
+	}
+	switch c.Security.Auth {
+	case "":
+	case "mtls":
+		if c.Security.CAFile == "" || c.Security.CertFile == "" || c.Security.KeyFile == "" {
+			return fmt.Errorf("security.ca_file, security.cert_file and security.key_file must be set for mtls")
+		}
+	default:
+		return fmt.Errorf("security.auth must be \"mtls\" or empty (got %q)", c.Security.Auth)
+	}
+	return c.Security.RBAC.validate()
+}
+
+func (r RBAC) validate() error {
+	roles := map[string]bool{"viewer": true, "operator": true, "admin": true}
+	if r.DefaultRole != "" && !roles[r.DefaultRole] {
+		return fmt.Errorf("security.rbac.default_role: unknown role %q", r.DefaultRole)
+	}
+	for _, b := range r.Bindings {
+		if !roles[b.Role] {
+			return fmt.Errorf("security.rbac.bindings: unknown role %q", b.Role)
+		}
+	}
+	seen := map[string]bool{}
+	for _, t := range r.Tokens {
+		if t.Name == "" || t.Token == "" {
+			return fmt.Errorf("security.rbac.tokens need a name and a token")
+		}
+		if seen[t.Token] {
+			return fmt.Errorf("security.rbac.tokens: token of %s is used twice", t.Name)
+		}
+		seen[t.Token] = true
+	}
+	return nil
+}
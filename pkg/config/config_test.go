@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "agent.yaml")
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return p
+}
+
+func TestLoad_OverridesDefaults(t *testing.T) {
+	p := writeConfig(t, `
+server:
+  listen: "127.0.0.1:9090"
+limits:
+  max_concurrent_jobs: 1
+state:
+  dir: "/tmp/jobs"
+`)
+	cfg, err := Load(p)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Server.Listen != "127.0.0.1:9090" || cfg.Limits.MaxConcurrentJobs != 1 || cfg.State.Dir != "/tmp/jobs" {
+		t.Fatalf("overrides not applied: %+v", cfg)
+	}
+	// untouched keys keep their defaults
+	if cfg.Limits.DefaultDurationSec != 120 || cfg.Export.IntervalSec != 10 {
+		t.Fatalf("defaults lost: %+v", cfg)
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "nope.yaml"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	if _, err := Load(writeConfig(t, "limits: [")); err == nil {
+		t.Fatalf("expected parse error")
+	}
+	if _, err := Load(writeConfig(t, "limits:\n  max_concurrent_jobs: 0\n")); err == nil {
+		t.Fatalf("expected validation error")
+	}
+	if _, err := Load(writeConfig(t, "limits:\n  max_duration_sec: 60\n")); err == nil {
+		t.Fatalf("expected error for default_duration_sec above max_duration_sec")
+	}
+	if _, err := Load(writeConfig(t, "notify:\n  max_attempts: 0\n")); err == nil {
+		t.Fatalf("expected error for notify.max_attempts 0")
+	}
+	if _, err := Load(writeConfig(t, "server:\n  shutdown_timeout_sec: 0\n")); err == nil {
+		t.Fatalf("expected error for shutdown_timeout_sec 0")
+	}
+	if _, err := Load(writeConfig(t, "server:\n  rate_limit:\n    write: {rate: 1, burst: 0}\n")); err == nil {
+		t.Fatalf("expected error for a rate limit without a burst")
+	}
+	if _, err := Load(writeConfig(t, "server:\n  max_body_bytes: -1\n")); err == nil {
+		t.Fatalf("expected error for a negative max_body_bytes")
+	}
+	if _, err := Load(writeConfig(t, "server:\n  listen: \":8080\"\nexport:\n  prometheus: {enabled: true, listen: \":8080\"}\n")); err == nil {
+		t.Fatalf("expected error for a prometheus listener on the API address")
+	}
+	if _, err := Load(writeConfig(t, "server:\n  listen: \":8080\"\n  grpc_listen: \":8080\"\n")); err == nil {
+		t.Fatalf("expected error for a gRPC listener on the API address")
+	}
+	if _, err := Load(writeConfig(t, "security:\n  auth: basic\n")); err == nil {
+		t.Fatalf("expected error for an unknown security.auth")
+	}
+	if _, err := Load(writeConfig(t, "security:\n  auth: mtls\n  ca_file: \"\"\n")); err == nil {
+		t.Fatalf("expected error for mtls without a CA bundle")
+	}
+}
+
+func TestLoad_Socket(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+server:
+  listen: ""
+  socket:
+    path: /run/telegen-sonic/api.sock
+    allowed_uids: [1000]
+`))
+	if err != nil {
+		t.Fatalf("a socket-only config should load: %v", err)
+	}
+	if mode, err := cfg.Server.Socket.FileMode(); err != nil || mode != 0o660 {
+		t.Fatalf("default socket mode = %v (%v)", mode, err)
+	}
+	if _, err := Load(writeConfig(t, "server:\n  listen: \"\"\n")); err == nil {
+		t.Fatalf("expected error without a TCP address or socket")
+	}
+	if _, err := Load(writeConfig(t, "server:\n  socket:\n    path: /tmp/a.sock\n    mode: \"0999\"\n")); err == nil {
+		t.Fatalf("expected error for a bad socket mode")
+	}
+}
+
+func TestLoad_RBAC(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+security:
+  rbac:
+    enabled: true
+    tokens: [{name: ci-bot, token: abc}]
+    bindings:
+      - {role: operator, subjects: [ci-bot]}
+`))
+	if err != nil || !cfg.Security.RBAC.Enabled || cfg.Security.RBAC.Bindings[0].Subjects[0] != "ci-bot" {
+		t.Fatalf("rbac config: %+v (%v)", cfg.Security.RBAC, err)
+	}
+	for _, bad := range []string{
+		"security:\n  rbac:\n    default_role: root\n",
+		"security:\n  rbac:\n    bindings: [{role: superuser, uids: [0]}]\n",
+		"security:\n  rbac:\n    tokens: [{name: a, token: x}, {name: b, token: x}]\n",
+		"security:\n  rbac:\n    tokens: [{name: a}]\n",
+	} {
+		if _, err := Load(writeConfig(t, bad)); err == nil {
+			t.Fatalf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestLoad_RepoConfig(t *testing.T) {
+	if _, err := Load("../../configs/agent.yaml"); err != nil {
+		t.Fatalf("configs/agent.yaml should load: %v", err)
+	}
+}
+
+func TestLoad_Prometheus(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+export:
+  disable_otlp: true
+  prometheus:
+    enabled: true
+    listen: ":9464"
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.Export.DisableOTLP || !cfg.Export.Prometheus.Enabled || cfg.Export.Prometheus.Listen != ":9464" {
+		t.Fatalf("export = %+v", cfg.Export)
+	}
+	if d := Default().Export; d.DisableOTLP || d.Prometheus.Enabled {
+		t.Fatalf("prometheus must be opt-in: %+v", d)
+	}
+}
@@ -0,0 +1,182 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	"golang.org/x/sys/unix"
+
+	"github.com/platformbuilds/telegen-sonic/pkg/api"
+)
+
+// btfPath is where the kernel exposes its BTF; CO-RE programs need it.
+var btfPath = "/sys/kernel/btf/vmlinux"
+
+// staleScrapes is how many collector intervals may pass without a
+// successful scrape before the agent stops being ready.
+const staleScrapes = 3
+
+// MapStatus is the outcome of opening one pinned map.
+type MapStatus struct {
+	Name     string
+	Required bool  // the agent is not ready without it
+	Err      error // nil once opened
+}
+
+// AgentAdapter exposes the agent's own state as api.AgentCore.
+type AgentAdapter struct {
+	Version, Commit, Date string
+
+	PinDir    string
+	Maps      []MapStatus
+	Collector *MetricsCollector // nil if it could not be created
+	Export    *ExportTracker    // optional
+	Endpoint  string            // of the OTLP exporter
+	S         *Supervisor
+}
+
+func (a *AgentAdapter) AgentInfo() api.AgentInfo {
+	info := api.AgentInfo{
+		Version: a.Version, Commit: a.Commit, BuildDate: a.Date,
+		KernelRelease: kernelRelease(),
+		BPFObject:     getBPFObjPath(),
+		PinDir:        a.PinDir,
+		Maps:          []api.PinnedMap{},
+		OTLPExport:    api.ExporterStatus{Endpoint: a.Endpoint},
+	}
+	notReady := func(format string, args ...interface{}) {
+		info.NotReady = append(info.NotReady, fmt.Sprintf(format, args...))
+	}
+
+	_, err := os.Stat(btfPath)
+	info.BTF = err == nil
+	if _, err := os.Stat(info.BPFObject); err != nil {
+		notReady("missing BPF object: %s", info.BPFObject)
+	} else {
+		info.BPFObjectFound = true
+	}
+	for _, m := range a.Maps {
+		pm := api.PinnedMap{Name: m.Name, Pinned: m.Err == nil, Required: m.Required}
+		if m.Err != nil {
+			pm.Error = m.Err.Error()
+			if m.Required {
+				notReady("pinned map %s: %v", m.Name, m.Err)
+			}
+		}
+		info.Maps = append(info.Maps, pm)
+	}
+
+	if a.Collector == nil {
+		notReady("metrics collector is not running")
+	} else {
+		st := a.Collector.Status()
+		info.Collector.Running = st.Running
+		if !st.LastScrape.IsZero() {
+			info.Collector.LastScrape = &st.LastScrape
+		}
+		if st.LastErr != nil {
+			info.Collector.LastError = st.LastErr.Error()
+		}
+		switch {
+		case !st.Running:
+			notReady("metrics collector stopped: %v", st.LastErr)
+		case st.LastErr != nil:
+			notReady("metrics collector: %v", st.LastErr)
+		case st.LastScrape.IsZero():
+			notReady("metrics collector has not scraped yet")
+		case time.Since(st.LastScrape) > staleScrapes*st.Interval:
+			notReady("metrics collector has not scraped since %s", st.LastScrape.Format(time.RFC3339))
+		}
+	}
+
+	// Export failures are reported but do not make the agent unready: jobs
+	// and their results work without a collector.
+	if a.Export != nil {
+		lastOK, lastErr, errAt := a.Export.Status()
+		if !lastOK.IsZero() {
+			info.OTLPExport.LastExport = &lastOK
+		}
+		if lastErr != nil {
+			info.OTLPExport.LastError = lastErr.Error()
+			info.OTLPExport.LastErrorAt = &errAt
+		}
+	}
+
+	if a.S != nil {
+		active, queued, slots := a.S.JobCounts()
+		info.Jobs = api.JobCounts{Active: active, Queued: queued, MaxConcurrent: slots}
+		if a.S.Closing() {
+			notReady("agent is shutting down")
+		}
+	}
+	info.Ready = len(info.NotReady) == 0
+	return info
+}
+
+// RegisterMetrics reports the agent's own state through meter on every
+// collection: its build, readiness, jobs and collector scrapes.
+func (a *AgentAdapter) RegisterMetrics(meter otelmetric.Meter) error {
+	build, err := meter.Int64ObservableGauge("telegen.build.info",
+		otelmetric.WithDescription("Always 1, with the agent's version and commit as attributes"))
+	if err != nil {
+		return fmt.Errorf("create build info gauge: %w", err)
+	}
+	ready, err := meter.Int64ObservableGauge("telegen.ready",
+		otelmetric.WithDescription("1 while the agent is ready, as GET /readyz reports"))
+	if err != nil {
+		return fmt.Errorf("create ready gauge: %w", err)
+	}
+	active, err := meter.Int64ObservableGauge("telegen.jobs.active",
+		otelmetric.WithDescription("Monitoring jobs running"))
+	if err != nil {
+		return fmt.Errorf("create active jobs gauge: %w", err)
+	}
+	queued, err := meter.Int64ObservableGauge("telegen.jobs.queued",
+		otelmetric.WithDescription("Monitoring jobs waiting for a slot"))
+	if err != nil {
+		return fmt.Errorf("create queued jobs gauge: %w", err)
+	}
+	lastScrape, err := meter.Float64ObservableGauge("telegen.collector.last_scrape",
+		otelmetric.WithDescription("Unix time of the collector's last successful scrape of the BPF maps"),
+		otelmetric.WithUnit("s"))
+	if err != nil {
+		return fmt.Errorf("create last scrape gauge: %w", err)
+	}
+
+	_, err = meter.RegisterCallback(func(_ context.Context, o otelmetric.Observer) error {
+		info := a.AgentInfo()
+		o.ObserveInt64(build, 1, otelmetric.WithAttributes(
+			attribute.String("version", info.Version),
+			attribute.String("commit", info.Commit),
+		))
+		if info.Ready {
+			o.ObserveInt64(ready, 1)
+		} else {
+			o.ObserveInt64(ready, 0)
+		}
+		o.ObserveInt64(active, int64(info.Jobs.Active))
+		o.ObserveInt64(queued, int64(info.Jobs.Queued))
+		if info.Collector.LastScrape != nil {
+			o.ObserveFloat64(lastScrape, float64(info.Collector.LastScrape.UnixNano())/1e9)
+		}
+		return nil
+	}, build, ready, active, queued, lastScrape)
+	if err != nil {
+		return fmt.Errorf("register agent metrics: %w", err)
+	}
+	return nil
+}
+
+func kernelRelease() string {
+	var u unix.Utsname
+	if err := unix.Uname(&u); err != nil {
+		return ""
+	}
+	return unix.ByteSliceToString(u.Release[:])
+}
@@ -0,0 +1,110 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestAgentAdapter_Ready(t *testing.T) {
+	td := t.TempDir()
+	obj := filepath.Join(td, "tc_ingress.bpf.o")
+	if err := os.WriteFile(obj, []byte("elf"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TELEGEN_BPF_OBJ", obj)
+	btfPath = obj // any existing file
+	defer func() { btfPath = "/sys/kernel/btf/vmlinux" }()
+
+	mc := &MetricsCollector{interval: time.Second}
+	mc.running = true
+	mc.scraped(nil)
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)
+	if _, code, err := sup.TryStartJob(startReq{spec: JobSpec{Port: "Ethernet0", Direction: "ingress", Duration: time.Minute}}); err != nil {
+		t.Fatalf("start: %d %v", code, err)
+	}
+	a := &AgentAdapter{
+		Version: "1.2.3", Commit: "abc", PinDir: "/sys/fs/bpf/telegen-sonic",
+		Maps: []MapStatus{
+			{Name: "stats_percpu", Required: true},
+			{Name: "flow_stats", Err: errors.New("no such file")},
+		},
+		Collector: mc, Export: &ExportTracker{}, Endpoint: "collector:4317", S: sup,
+	}
+	info := a.AgentInfo()
+	if !info.Ready || len(info.NotReady) != 0 {
+		t.Fatalf("expected ready, got %v", info.NotReady)
+	}
+	if info.Version != "1.2.3" || !info.BTF || !info.BPFObjectFound || info.KernelRelease == "" {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if len(info.Maps) != 2 || !info.Maps[0].Pinned || info.Maps[1].Pinned || info.Maps[1].Error == "" {
+		t.Fatalf("maps = %+v", info.Maps)
+	}
+	if info.Collector.LastScrape == nil || !info.Collector.Running {
+		t.Fatalf("collector = %+v", info.Collector)
+	}
+	if info.Jobs.Active != 1 || info.Jobs.MaxConcurrent != 2 {
+		t.Fatalf("jobs = %+v", info.Jobs)
+	}
+
+	// a missing required map, a failing collector and export errors
+	a.Maps[0].Err = errors.New("open stats_percpu: no such file")
+	mc.scraped(errors.New("lookup stats_percpu[0]: bad fd"))
+	a.Export.record(errors.New("connection refused"))
+	info = a.AgentInfo()
+	if info.Ready || len(info.NotReady) != 2 {
+		t.Fatalf("expected two reasons, got %v", info.NotReady)
+	}
+	if info.OTLPExport.LastError != "connection refused" || info.OTLPExport.LastErrorAt == nil {
+		t.Fatalf("export = %+v", info.OTLPExport)
+	}
+
+	// a collector that stopped, or stalled, is not ready either
+	a.Maps[0].Err = nil
+	mc.running = false
+	if info := a.AgentInfo(); info.Ready || !strings.Contains(info.NotReady[0], "stopped") {
+		t.Fatalf("not_ready = %v", info.NotReady)
+	}
+	mc.running, mc.lastErr, mc.lastScrape = true, nil, time.Now().Add(-time.Minute)
+	if info := a.AgentInfo(); info.Ready || !strings.Contains(info.NotReady[0], "has not scraped since") {
+		t.Fatalf("not_ready = %v", info.NotReady)
+	}
+	mc.lastScrape = time.Now()
+	if err := sup.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if info := a.AgentInfo(); info.Ready || info.NotReady[0] != "agent is shutting down" {
+		t.Fatalf("not_ready = %v", info.NotReady)
+	}
+}
+
+type failingExporter struct {
+	sdkmetric.Exporter
+	err error
+}
+
+func (f failingExporter) Export(context.Context, *metricdata.ResourceMetrics) error { return f.err }
+
+func TestExportTracker(t *testing.T) {
+	tr := &ExportTracker{}
+	exp := trackedExporter{Exporter: failingExporter{err: errors.New("unavailable")}, t: tr}
+	_ = exp.Export(context.Background(), &metricdata.ResourceMetrics{})
+	if ok, err, at := tr.Status(); !ok.IsZero() || err == nil || at.IsZero() {
+		t.Fatalf("after a failure: ok=%v err=%v at=%v", ok, err, at)
+	}
+	exp.Exporter = failingExporter{}
+	_ = exp.Export(context.Background(), &metricdata.ResourceMetrics{})
+	if ok, err, _ := tr.Status(); ok.IsZero() || err != nil {
+		t.Fatalf("after a success: ok=%v err=%v", ok, err)
+	}
+}
@@ -4,9 +4,15 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+
+	"github.com/cilium/ebpf"
 )
 
-type TC struct{}
+type TC struct {
+	// ConfigMap is the pinned if_config map; when set, Attach and Update
+	// program each interface's sampling and filters into it.
+	ConfigMap *ebpf.Map
+}
 
 // getBPFObjPath lets tests (or ops) override the object path.
 // Default remains /bpf/tc_ingress.bpf.o so runtime behavior is unchanged.
@@ -18,6 +24,11 @@ func getBPFObjPath() string {
 }
 
 func (t *TC) Attach(ifname string, spec JobSpec) (func() error, error) {
+	// Reject filters the data plane cannot apply before touching tc
+	if _, err := ifConfigFromSpec(spec); err != nil {
+		return nil, err
+	}
+
 	// Ensure clsact
 	_ = exec.Command("tc", "qdisc", "add", "dev", ifname, "clsact").Run()
 
@@ -33,11 +44,34 @@ func (t *TC) Attach(ifname string, spec JobSpec) (func() error, error) {
 	if err != nil {
 		return nil, fmt.Errorf("tc attach failed: %v: %s", err, string(out))
 	}
-	cleanup := func() error {
-		_ = exec.Command("tc", "filter", "del", "dev", ifname, "ingress").Run()
-		_ = exec.Command("tc", "qdisc", "del", "dev", ifname, "clsact").Run()
-		return nil
+	if err := t.configure(ifname, spec); err != nil {
+		_ = t.Detach(ifname)
+		return nil, err
 	}
+	cleanup := func() error { return t.Detach(ifname) }
 	fmt.Printf("Attached tc program on %s (dir=%s)\n", ifname, spec.Direction)
 	return cleanup, nil
 }
+
+// Preflight reports a missing tc binary or BPF object, either of which
+// makes Attach fail.
+func (t *TC) Preflight(spec JobSpec) []Problem {
+	var ps []Problem
+	if _, err := exec.LookPath("tc"); err != nil {
+		ps = append(ps, missingTool("tc"))
+	}
+	obj := getBPFObjPath()
+	if _, err := os.Stat(obj); err != nil {
+		ps = append(ps, newProblem("", ProblemBPFObject, fmt.Errorf("missing BPF object: %s", obj)))
+	}
+	return ps
+}
+
+// Detach removes the ingress filter and clsact qdisc from ifname. It is
+// also used at startup to clean up after a crashed agent.
+func (t *TC) Detach(ifname string) error {
+	t.unconfigure(ifname)
+	_ = exec.Command("tc", "filter", "del", "dev", ifname, "ingress").Run()
+	_ = exec.Command("tc", "qdisc", "del", "dev", ifname, "clsact").Run()
+	return nil
+}
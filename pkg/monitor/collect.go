@@ -6,8 +6,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/cilium/ebpf"
@@ -36,19 +39,93 @@ type IfProtoKey struct {
 	Proto   uint32
 }
 
+// FlowKey mirrors struct flow_key in bpf/tc_ingress.bpf.c.
+type FlowKey struct {
+	Ifindex uint32
+	Family  uint8 // 4 or 6
+	L4Proto uint8
+	Pad     uint16
+	Saddr   [16]byte
+	Daddr   [16]byte
+	Sport   uint16
+	Dport   uint16
+}
+
+// String renders the key as "src:sport->dst:dport/PROTO".
+func (k FlowKey) String() string {
+	var src, dst net.IP
+	if k.Family == 4 {
+		src, dst = net.IP(k.Saddr[:4]), net.IP(k.Daddr[:4])
+	} else {
+		src, dst = net.IP(k.Saddr[:]), net.IP(k.Daddr[:])
+	}
+	return net.JoinHostPort(src.String(), strconv.Itoa(int(k.Sport))) + "->" +
+		net.JoinHostPort(dst.String(), strconv.Itoa(int(k.Dport))) + "/" + l4ProtoName(k.L4Proto)
+}
+
+func l4ProtoName(p uint8) string {
+	switch p {
+	case 1:
+		return "ICMP"
+	case 6:
+		return "TCP"
+	case 17:
+		return "UDP"
+	case 58:
+		return "ICMPv6"
+	default:
+		return strconv.Itoa(int(p))
+	}
+}
+
 // MetricsCollector periodically reads BPF maps and emits OTel metrics.
 type MetricsCollector struct {
 	statsMap   *ebpf.Map // BPF_MAP_TYPE_PERCPU_ARRAY [idxMax]ProtoStats
 	ifStatsMap *ebpf.Map // BPF_MAP_TYPE_PERCPU_HASH {IfProtoKey: []ProtoStats per CPU}
+	flowMap    *ebpf.Map // BPF_MAP_TYPE_LRU_HASH {FlowKey: ProtoStats} (optional)
 
 	meter      otelmetric.Meter
 	packetsCtr otelmetric.Int64Counter
 	bytesHist  otelmetric.Int64Histogram
 
+	// per-job totals for jobs started with otlp_export
+	jobPacketsCtr otelmetric.Int64Counter
+	jobBytesCtr   otelmetric.Int64Counter
+
 	lastGlobal [idxMax]ProtoStats
 	lastIF     map[IfProtoKey]ProtoStats
 
 	interval time.Duration
+
+	mu         sync.Mutex // guards the scrape status below
+	running    bool
+	lastScrape time.Time // end of the last successful scrape
+	lastErr    error     // of the last scrape, or why Start returned
+}
+
+// CollectorStatus is how the metrics collector's scrapes are going.
+type CollectorStatus struct {
+	Running    bool
+	Interval   time.Duration
+	LastScrape time.Time // zero until the first successful scrape
+	LastErr    error
+}
+
+// Status reports the collector's last scrape.
+func (c *MetricsCollector) Status() CollectorStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CollectorStatus{Running: c.running, Interval: c.interval, LastScrape: c.lastScrape, LastErr: c.lastErr}
+}
+
+// scraped records the outcome of a scrape.
+func (c *MetricsCollector) scraped(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastErr = err
+	if err == nil {
+		c.lastScrape = time.Now()
+	}
 }
 
 // OpenPinnedMaps expects pinned names "stats_percpu" and "if_stats_percpu".
@@ -71,6 +148,30 @@ func OpenPinnedMaps(pinDir string) (*ebpf.Map, *ebpf.Map, error) {
 	return statsMap, ifMap, nil
 }
 
+// OpenPinnedFlowMap opens the optional pinned "flow_stats" map.
+func OpenPinnedFlowMap(pinDir string) (*ebpf.Map, error) {
+	if pinDir == "" {
+		pinDir = DefaultPinDir
+	}
+	m, err := ebpf.LoadPinnedMap(filepath.Join(pinDir, "flow_stats"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("open flow_stats: %w", err)
+	}
+	return m, nil
+}
+
+// OpenPinnedConfigMap opens the optional pinned "if_config" map.
+func OpenPinnedConfigMap(pinDir string) (*ebpf.Map, error) {
+	if pinDir == "" {
+		pinDir = DefaultPinDir
+	}
+	m, err := ebpf.LoadPinnedMap(filepath.Join(pinDir, "if_config"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("open if_config: %w", err)
+	}
+	return m, nil
+}
+
 func NewMetricsCollector(meter otelmetric.Meter, statsMap, ifStatsMap *ebpf.Map, interval time.Duration) (*MetricsCollector, error) {
 	if meter == nil {
 		return nil, errors.New("meter is nil")
@@ -100,32 +201,61 @@ func NewMetricsCollector(meter otelmetric.Meter, statsMap, ifStatsMap *ebpf.Map,
 		return nil, fmt.Errorf("create bytes histogram: %w", err)
 	}
 
+	jobPacketsCtr, err := meter.Int64Counter(
+		"bpf.job.packets",
+		otelmetric.WithDescription("Packets seen on a monitoring job's interface"),
+		otelmetric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create job packets counter: %w", err)
+	}
+
+	jobBytesCtr, err := meter.Int64Counter(
+		"bpf.job.bytes",
+		otelmetric.WithDescription("Bytes seen on a monitoring job's interface"),
+		otelmetric.WithUnit("By"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create job bytes counter: %w", err)
+	}
+
 	return &MetricsCollector{
-		statsMap:   statsMap,
-		ifStatsMap: ifStatsMap,
-		meter:      meter,
-		packetsCtr: packetsCtr,
-		bytesHist:  bytesHist,
-		lastIF:     make(map[IfProtoKey]ProtoStats),
-		interval:   interval,
+		statsMap:      statsMap,
+		ifStatsMap:    ifStatsMap,
+		meter:         meter,
+		packetsCtr:    packetsCtr,
+		bytesHist:     bytesHist,
+		jobPacketsCtr: jobPacketsCtr,
+		jobBytesCtr:   jobBytesCtr,
+		lastIF:        make(map[IfProtoKey]ProtoStats),
+		interval:      interval,
 	}, nil
 }
 
-func (c *MetricsCollector) Start(ctx context.Context) error {
+func (c *MetricsCollector) Start(ctx context.Context) (err error) {
 	t := time.NewTicker(c.interval)
 	defer t.Stop()
+	c.mu.Lock()
+	c.running = true
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.running, c.lastErr = false, err
+		c.mu.Unlock()
+	}()
 
 	// First scrape establishes baselines.
 	if err := c.collectOnce(ctx); err != nil {
 		return err
 	}
+	c.scraped(nil)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-t.C:
-			_ = c.collectOnce(ctx) // keep going even if one scrape fails
+			c.scraped(c.collectOnce(ctx)) // keep going even if one scrape fails
 		}
 	}
 }
@@ -190,6 +320,64 @@ func (c *MetricsCollector) collectOnce(ctx context.Context) error {
 	return nil
 }
 
+// SetFlowMap enables per-flow lookups used for per-job top flows.
+func (c *MetricsCollector) SetFlowMap(m *ebpf.Map) {
+	c.flowMap = m
+}
+
+// IfCounters reads the cumulative totals recorded for ifindex straight from
+// the per-interface map. ICMPv6 is skipped because the data plane counts it
+// under IPv6 as well.
+func (c *MetricsCollector) IfCounters(ifindex uint32) (ProtoStats, error) {
+	var total ProtoStats
+	if c.ifStatsMap == nil {
+		return total, errors.New("if_stats_percpu map not available")
+	}
+	vals := make([]ProtoStats, runtime.NumCPU())
+	for idx := uint32(0); idx < idxMax; idx++ {
+		if idx == idxICMP6 {
+			continue
+		}
+		k := IfProtoKey{Ifindex: ifindex, Proto: idx}
+		if err := c.ifStatsMap.Lookup(&k, &vals); err != nil {
+			if errors.Is(err, ebpf.ErrKeyNotExist) {
+				continue
+			}
+			return total, fmt.Errorf("lookup if_stats_percpu[%d/%d]: %w", ifindex, idx, err)
+		}
+		s := sumSlice(vals)
+		total.Packets += s.Packets
+		total.Bytes += s.Bytes
+	}
+	return total, nil
+}
+
+// FlowCounters returns the cumulative per-flow totals for the given
+// ifindexes, walking flow_stats once however many interfaces are asked for.
+// BPF objects without a flow_stats map track no flows, which is not an error.
+func (c *MetricsCollector) FlowCounters(ifindexes ...uint32) (map[FlowKey]ProtoStats, error) {
+	if c.flowMap == nil || len(ifindexes) == 0 {
+		return nil, nil
+	}
+	want := make(map[uint32]bool, len(ifindexes))
+	for _, idx := range ifindexes {
+		want[idx] = true
+	}
+	out := make(map[FlowKey]ProtoStats)
+	it := c.flowMap.Iterate()
+	var k FlowKey
+	var v ProtoStats
+	for it.Next(&k, &v) {
+		if want[k.Ifindex] {
+			out[k] = v
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("iterate flow_stats: %w", err)
+	}
+	return out, nil
+}
+
 func protoName(idx uint32) string {
 	switch idx {
 	case idxIPv4:
@@ -57,6 +57,14 @@ func TestNewMetricsCollector_Errors(t *testing.T) {
 	}
 }
 
+func TestFlowCounters_WithoutFlowMap(t *testing.T) {
+	c := &MetricsCollector{}
+	flows, err := c.FlowCounters(1, 2)
+	if err != nil || len(flows) != 0 {
+		t.Fatalf("a BPF object without flow_stats should report no flows, got %v, %v", flows, err)
+	}
+}
+
 func TestProtoName(t *testing.T) {
 	tests := []struct {
 		idx  uint32
@@ -4,66 +4,572 @@ package monitor
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/platformbuilds/telegen-sonic/pkg/api"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
 )
 
-// BPFCollectorAdapter satisfies the Supervisor's Collector interface by
-// delegating to MetricsCollector. Since the collector is started globally
-// in main.go, Run is a no-op to avoid double-starting it.
+// BPFCollectorAdapter satisfies the Supervisor's Collector interface on top
+// of MetricsCollector. The collector itself is started globally in main.go;
+// Run only snapshots the job interfaces' counters so each job can report
+// its own deltas.
 type BPFCollectorAdapter struct {
 	mc *MetricsCollector
+
+	// cpu reads the data-plane CPU time spent on an interface; used for
+	// max_cpu_seconds budgets.
+	cpu func(ifname string) (time.Duration, error)
+
+	// labelAttrs are the job label keys copied onto job metrics.
+	labelAttrs []string
 }
 
 // NewBPFCollector is the factory main.go calls.
 func NewBPFCollector(mc *MetricsCollector) *BPFCollectorAdapter {
-	return &BPFCollectorAdapter{mc: mc}
+	return &BPFCollectorAdapter{mc: mc, cpu: ProgramRuntime}
+}
+
+// SetLabelAttributes selects the job labels that become attributes
+// ("job.label.<key>") on job metrics. Labels are free-form, so only
+// allowlisted keys are exported to keep metric cardinality bounded.
+func (a *BPFCollectorAdapter) SetLabelAttributes(keys []string) {
+	a.labelAttrs = keys
+}
+
+// Preflight rejects budgets the collector cannot measure, as they would
+// never stop the job: any budget without a running metrics collector, and
+// max_cpu_seconds without BPF run-time stats.
+func (a *BPFCollectorAdapter) Preflight(spec JobSpec) []Problem {
+	var ps []Problem
+	if a.mc == nil {
+		for _, b := range []struct {
+			field string
+			set   bool
+		}{{"max_packets", spec.MaxPackets > 0}, {"max_bytes", spec.MaxBytes > 0}, {"max_cpu_seconds", spec.MaxCPU > 0}} {
+			if b.set {
+				ps = append(ps, newProblem(b.field, ProblemInvalid,
+					fmt.Errorf("%w: %s cannot be enforced: the metrics collector is not running", ErrInvalidJob, b.field)))
+			}
+		}
+		return ps
+	}
+	if spec.MaxCPU > 0 && (a.cpu == nil || !runtimeStats.Load()) {
+		ps = append(ps, newProblem("max_cpu_seconds", ProblemInvalid,
+			fmt.Errorf("%w: max_cpu_seconds cannot be enforced: bpf run-time stats are not enabled", ErrInvalidJob)))
+	}
+	return ps
+}
+
+// jobAttributes identifies a job's metrics.
+func jobAttributes(jobID string, spec JobSpec, labelKeys []string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("job.id", jobID),
+		attribute.String("job.port", strings.Join(spec.requestedPorts(), ",")),
+	}
+	for _, k := range labelKeys {
+		if v, ok := spec.Labels[k]; ok {
+			attrs = append(attrs, attribute.String("job.label."+k, v))
+		}
+	}
+	return attrs
+}
+
+// defaultTopFlows caps the number of flows reported per job.
+const defaultTopFlows = 10
+
+// JobSummary is what the BPF collector's ResultsProvider returns.
+type JobSummary struct {
+	Window   time.Duration
+	Packets  uint64
+	Bytes    uint64
+	Errors   map[string]uint64
+	TopFlows []FlowSummary
+	CPU      time.Duration // data-plane CPU time; only measured for max_cpu_seconds budgets
+
+	// Interfaces breaks the totals down by job interface.
+	Interfaces map[string]ProtoStats
+}
+
+type FlowSummary struct {
+	FiveTuple string
+	Packets   uint64
+	Bytes     uint64
+}
+
+// jobResults tracks one job's counters from the start snapshot onwards,
+// summed over the job's interfaces. Until Finalize is called, Summary reads
+// live counters.
+type jobResults struct {
+	mc  *MetricsCollector
+	ifs []jobIf
+
+	mu        sync.Mutex
+	start     time.Time
+	end       time.Time
+	base      ProtoStats
+	last      ProtoStats
+	baseFlows map[FlowKey]ProtoStats
+	lastFlows map[FlowKey]ProtoStats
+	errors    map[string]uint64
+	final     bool
+
+	// budget enforcement; stop is nil for jobs without a budget
+	budget     JobSpec
+	cpu        func() (time.Duration, error)
+	baseCPU    time.Duration
+	lastCPU    time.Duration
+	stop       chan struct{}
+	stopReason string
+
+	// OTel export of the job's own totals; only with otlp_export
+	export  bool
+	attrs   otelmetric.MeasurementOption
+	emitted ProtoStats
+}
+
+// jobIf is one interface a job counts on.
+type jobIf struct {
+	name       string
+	index      uint32
+	base, last ProtoStats
+}
+
+// Run snapshots the counters of ifnames at job start. The returned provider
+// keeps reading live deltas until the Supervisor finalizes it at job stop.
+// A job with a budget fails with ErrBudgetUnenforced when none of its
+// interfaces can be counted, rather than running without a limit.
+func (a *BPFCollectorAdapter) Run(ctx context.Context, jobID string, ifnames []string, spec JobSpec) (ResultsProvider, error) {
+	r := &jobResults{
+		start:  time.Now(),
+		errors: map[string]uint64{},
+	}
+	if a == nil || a.mc == nil {
+		if spec.hasBudget() {
+			return nil, fmt.Errorf("%w: the metrics collector is not running", ErrBudgetUnenforced)
+		}
+		return r, nil
+	}
+	r.mc = a.mc
+	for _, name := range ifnames {
+		ifc, err := net.InterfaceByName(name)
+		if err != nil {
+			r.errors["ifindex_lookup"]++
+			continue
+		}
+		r.ifs = append(r.ifs, jobIf{name: name, index: uint32(ifc.Index)})
+	}
+	if len(r.ifs) == 0 {
+		if spec.hasBudget() {
+			return nil, fmt.Errorf("%w: none of %v can be counted", ErrBudgetUnenforced, ifnames)
+		}
+		return r, nil
+	}
+	r.base, r.baseFlows = r.read()
+	for i := range r.ifs {
+		r.ifs[i].base = r.ifs[i].last
+	}
+	r.last, r.lastFlows = r.base, r.baseFlows
+
+	if spec.OTLPExport && a.mc.jobPacketsCtr != nil {
+		r.export = true
+		r.attrs = otelmetric.WithAttributes(jobAttributes(jobID, spec, a.labelAttrs)...)
+		r.emitted = r.base
+	}
+	if spec.hasBudget() {
+		r.budget = spec
+		r.stop = make(chan struct{})
+		if spec.MaxCPU > 0 && a.cpu != nil {
+			r.cpu = func() (time.Duration, error) {
+				var total time.Duration
+				for _, jif := range r.ifs {
+					d, err := a.cpu(jif.name)
+					if err != nil {
+						return 0, err
+					}
+					total += d
+				}
+				return total, nil
+			}
+			if d, err := r.cpu(); err != nil {
+				r.errors["cpu_read"]++
+			} else {
+				r.baseCPU, r.lastCPU = d, d
+			}
+		}
+	}
+	// exported jobs are refreshed every interval so their metrics keep up
+	if spec.hasBudget() || r.export {
+		go r.watch(ctx, a.mc.interval)
+	}
+	return r, nil
+}
+
+// watch refreshes the job's counters and checks its budget every collector
+// interval until the job ends or the budget is used up.
+func (r *jobResults) watch(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if r.checkBudget() {
+				return
+			}
+		}
+	}
+}
+
+// checkBudget refreshes the counters and closes r.stop once any budget is
+// used up.
+func (r *jobResults) checkBudget() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.final || r.stopReason != "" {
+		return true
+	}
+	r.refresh()
+	b := r.budget
+	over := b.MaxPackets > 0 && diffU64(r.last.Packets, r.base.Packets) >= b.MaxPackets ||
+		b.MaxBytes > 0 && diffU64(r.last.Bytes, r.base.Bytes) >= b.MaxBytes ||
+		b.MaxCPU > 0 && r.lastCPU-r.baseCPU >= b.MaxCPU
+	if !over {
+		return false
+	}
+	r.stopReason = StopBudgetExceeded
+	close(r.stop)
+	return true
+}
+
+// StopC is closed when the job exceeds its budget.
+func (r *jobResults) StopC() <-chan struct{} {
+	return r.stop
+}
+
+func (r *jobResults) StopReason() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stopReason
+}
+
+// read takes a snapshot of every interface, keeping each one's counters in
+// r.ifs and returning the totals; callers hold r.mu.
+func (r *jobResults) read() (ProtoStats, map[FlowKey]ProtoStats) {
+	var total ProtoStats
+	indexes := make([]uint32, 0, len(r.ifs))
+	for i := range r.ifs {
+		st, err := r.mc.IfCounters(r.ifs[i].index)
+		if err != nil {
+			r.errors["counter_read"]++
+		}
+		r.ifs[i].last = st
+		total.Packets += st.Packets
+		total.Bytes += st.Bytes
+		indexes = append(indexes, r.ifs[i].index)
+	}
+	// Flow keys carry the ifindex, so one walk of flow_stats covers every
+	// interface without merging.
+	flows, err := r.mc.FlowCounters(indexes...)
+	if err != nil {
+		r.errors["flow_read"]++
+	}
+	if flows == nil {
+		flows = map[FlowKey]ProtoStats{}
+	}
+	return total, flows
 }
 
-type noopResults struct{}
+func (r *jobResults) refresh() {
+	if r.final {
+		return
+	}
+	if r.cpu != nil {
+		if d, err := r.cpu(); err != nil {
+			r.errors["cpu_read"]++
+		} else {
+			r.lastCPU = d
+		}
+	}
+	if len(r.ifs) > 0 {
+		r.last, r.lastFlows = r.read()
+	}
+	if r.export {
+		r.emit()
+	}
+}
 
-func (noopResults) Summary() interface{} { return map[string]any{} }
+// emit adds what the job saw since the last emit to the job metrics;
+// callers hold r.mu.
+func (r *jobResults) emit() {
+	dp := diffU64(r.last.Packets, r.emitted.Packets)
+	db := diffU64(r.last.Bytes, r.emitted.Bytes)
+	r.emitted = r.last
+	ctx := context.Background()
+	if dp > 0 {
+		r.mc.jobPacketsCtr.Add(ctx, int64(dp), r.attrs)
+	}
+	if db > 0 {
+		r.mc.jobBytesCtr.Add(ctx, int64(db), r.attrs)
+	}
+}
 
-// Run: no-op now that the global collector is already running.
-// We could record jobID/spec for future per-job summaries if needed.
-func (a *BPFCollectorAdapter) Run(ctx context.Context, jobID string, spec JobSpec) (ResultsProvider, error) {
-	return noopResults{}, nil
+// Finalize takes the stop snapshot and freezes the results.
+func (r *jobResults) Finalize() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.final {
+		return
+	}
+	r.refresh()
+	r.end = time.Now()
+	r.final = true
+}
+
+func (r *jobResults) Summary() interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refresh()
+
+	end := r.end
+	if !r.final {
+		end = time.Now()
+	}
+	errs := make(map[string]uint64, len(r.errors))
+	for k, v := range r.errors {
+		errs[k] = v
+	}
+
+	flows := make([]FlowSummary, 0, len(r.lastFlows))
+	for k, cur := range r.lastFlows {
+		prev := r.baseFlows[k]
+		p, b := diffU64(cur.Packets, prev.Packets), diffU64(cur.Bytes, prev.Bytes)
+		if p == 0 && b == 0 {
+			continue
+		}
+		flows = append(flows, FlowSummary{FiveTuple: k.String(), Packets: p, Bytes: b})
+	}
+	sort.Slice(flows, func(i, j int) bool {
+		if flows[i].Packets != flows[j].Packets {
+			return flows[i].Packets > flows[j].Packets
+		}
+		return flows[i].FiveTuple < flows[j].FiveTuple
+	})
+	if len(flows) > defaultTopFlows {
+		flows = flows[:defaultTopFlows]
+	}
+
+	perIf := make(map[string]ProtoStats, len(r.ifs))
+	for _, jif := range r.ifs {
+		perIf[jif.name] = ProtoStats{
+			Packets: diffU64(jif.last.Packets, jif.base.Packets),
+			Bytes:   diffU64(jif.last.Bytes, jif.base.Bytes),
+		}
+	}
+
+	return JobSummary{
+		Window:     end.Sub(r.start),
+		Packets:    diffU64(r.last.Packets, r.base.Packets),
+		Bytes:      diffU64(r.last.Bytes, r.base.Bytes),
+		Errors:     errs,
+		TopFlows:   flows,
+		CPU:        r.lastCPU - r.baseCPU,
+		Interfaces: perIf,
+	}
+}
+
+// startRequest lets Supervisor.TryStartJob derive a JobSpec from the typed
+// API request.
+type startRequest api.StartJobRequest
+
+func (r startRequest) ToSpec() JobSpec {
+	return JobSpec{
+		Port:         r.Port,
+		Ports:        r.Ports,
+		Direction:    r.Direction,
+		SpanMethod:   r.SpanMethod,
+		VLAN:         r.VLAN,
+		Filters:      r.Filters,
+		SampleRate:   r.SampleRate,
+		Duration:     time.Duration(r.DurationSec) * time.Second,
+		OTLPExport:   r.OTLPExport,
+		ResultDetail: r.ResultDetail,
+		AllowShared:  r.AllowShared,
+		Priority:     r.Priority,
+		MaxPackets:   r.MaxPackets,
+		MaxBytes:     r.MaxBytes,
+		MaxCPU:       time.Duration(r.MaxCPUSeconds * float64(time.Second)),
+		Notify:       (*Notify)(r.Notify),
+		Labels:       r.Labels,
+		Owner:        r.Owner,
+		Description:  r.Description,
+	}
+}
+
+// updateRequest lets Supervisor.UpdateJob read the typed PATCH body.
+type updateRequest api.UpdateJobRequest
+
+func (r updateRequest) ToUpdate() JobUpdate {
+	return JobUpdate{
+		Duration:   time.Duration(r.DurationSec) * time.Second,
+		SampleRate: r.SampleRate,
+		Filters:    r.Filters,
+	}
 }
 
 // CoreAdapter translates between the generic Supervisor methods (map[string]any)
 // and the typed api.Core interface used by the HTTP layer.
 type CoreAdapter struct {
 	S *Supervisor
+
+	// OTLPEndpoint is reported back in job results.
+	OTLPEndpoint string
 }
 
 func (c *CoreAdapter) TryStartJob(req api.StartJobRequest) (api.StartJobResponse, int, error) {
-	resp, code, err := c.S.TryStartJob(req)
+	resp, code, err := c.S.TryStartJob(startRequest(req))
 	if err != nil {
-		return api.StartJobResponse{}, code, err
+		return api.StartJobResponse{}, code, apiError(err, "")
 	}
 	m, _ := resp.(map[string]any)
 	return api.StartJobResponse{
-		JobID:     asString(m, "job_id"),
-		Status:    asString(m, "status"),
-		Interface: asString(m, "interface"),
+		JobID:         asString(m, "job_id"),
+		Status:        asString(m, "status"),
+		Interface:     asString(m, "interface"),
+		QueuePosition: asInt(m, "queue_position"),
+
+		PreemptedJobID: asString(m, "preempted_job_id"),
 	}, code, nil
 }
 
+func (c *CoreAdapter) ValidateJob(req api.StartJobRequest) (api.ValidateJobResponse, int, error) {
+	resp, code, err := c.S.ValidateJob(startRequest(req))
+	if err != nil {
+		return api.ValidateJobResponse{}, code, err
+	}
+	m, _ := resp.(map[string]any)
+	valid, _ := m["valid"].(bool)
+	out := api.ValidateJobResponse{
+		Valid:        valid,
+		Admission:    asString(m, "admission"),
+		PreemptJobID: asString(m, "preempt_job_id"),
+	}
+	ps, _ := m["problems"].([]Problem)
+	out.Problems = apiProblems(ps)
+	return out, code, nil
+}
+
+func apiProblems(ps []Problem) []api.Problem {
+	out := make([]api.Problem, 0, len(ps))
+	for _, p := range ps {
+		out = append(out, api.Problem{Field: p.Field, Code: p.Code, Message: p.Message})
+	}
+	return out
+}
+
+// apiError hands the problems of an invalid job to the API, with their
+// fields under prefix; other errors pass through.
+func apiError(err error, prefix string) error {
+	var invalid *InvalidJobError
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	ps := apiProblems(invalid.Problems)
+	for i := range ps {
+		if ps[i].Field != "" {
+			ps[i].Field = prefix + ps[i].Field
+		}
+	}
+	return &api.InvalidRequestError{Problems: ps}
+}
+
 func (c *CoreAdapter) GetJob(id string) (api.JobStatus, int, error) {
 	resp, code, err := c.S.GetJob(id)
 	if err != nil {
 		return api.JobStatus{}, code, err
 	}
 	m, _ := resp.(map[string]any)
-	return api.JobStatus{
+	return jobStatusFromMap(m), code, nil
+}
+
+func (c *CoreAdapter) ListJobs(req api.ListJobsRequest) (api.ListJobsResponse, int, error) {
+	f := JobFilter{
+		Port: req.Port, Owner: req.Owner, Labels: req.Labels,
+		Since: req.Since, Until: req.Until, Limit: req.Limit, Cursor: req.Cursor,
+	}
+	for _, st := range req.States {
+		f.States = append(f.States, JobState(st))
+	}
+	resp, code, err := c.S.ListJobs(f)
+	if err != nil {
+		return api.ListJobsResponse{}, code, err
+	}
+	m, _ := resp.(map[string]any)
+	out := api.ListJobsResponse{Jobs: []api.JobStatus{}, NextCursor: asString(m, "next_cursor")}
+	jobs, _ := m["jobs"].([]map[string]interface{})
+	for _, jm := range jobs {
+		out.Jobs = append(out.Jobs, jobStatusFromMap(jm))
+	}
+	return out, code, nil
+}
+
+func jobStatusFromMap(m map[string]any) api.JobStatus {
+	st := api.JobStatus{
 		JobID:     asString(m, "job_id"),
 		Status:    asString(m, "status"),
+		CreatedAt: asTime(m, "created_at"),
 		StartedAt: asTime(m, "started_at"),
 		ExpiresAt: asTime(m, "expires_at"),
 		Port:      asString(m, "port"),
 		Interface: asString(m, "interface"),
-	}, code, nil
+
+		QueuePosition: asInt(m, "queue_position"),
+		FailureReason: asString(m, "failure_reason"),
+		SampleRate:    asInt(m, "sample_rate"),
+		StopReason:    asString(m, "stop_reason"),
+		Owner:         asString(m, "owner"),
+		Description:   asString(m, "description"),
+		Priority:      asInt(m, "priority"),
+		PreemptedBy:   asString(m, "preempted_by"),
+	}
+	if f, ok := m["filters"].(map[string]interface{}); ok {
+		st.Filters = f
+	}
+	if l, ok := m["labels"].(map[string]string); ok {
+		st.Labels = l
+	}
+	if p, ok := m["ports"].([]string); ok {
+		st.Ports = p
+	}
+	if members, ok := m["members"].([]JobMember); ok {
+		for _, mb := range members {
+			st.Members = append(st.Members, api.JobMember{Port: mb.Port, Interface: mb.IfName})
+		}
+	}
+	if t := asTime(m, "ended_at"); !t.IsZero() {
+		st.EndedAt = &t
+	}
+	if errs, ok := m["step_errors"].(map[string]string); ok {
+		st.StepErrors = errs
+	}
+	return st
+}
+
+func (c *CoreAdapter) UpdateJob(id string, req api.UpdateJobRequest) (api.JobStatus, int, error) {
+	resp, code, err := c.S.UpdateJob(id, updateRequest(req))
+	if err != nil {
+		return api.JobStatus{}, code, err
+	}
+	m, _ := resp.(map[string]any)
+	return jobStatusFromMap(m), code, nil
 }
 
 func (c *CoreAdapter) StopJob(id string) (api.StopJobResponse, int, error) {
@@ -79,12 +585,41 @@ func (c *CoreAdapter) StopJob(id string) (api.StopJobResponse, int, error) {
 }
 
 func (c *CoreAdapter) GetResults(id string) (api.JobResults, int, error) {
-	// TODO: map Supervisor's results payload to api.JobResults when you wire it up.
-	_, code, err := c.S.GetResults(id)
+	resp, code, err := c.S.GetResults(id)
 	if err != nil {
 		return api.JobResults{}, code, err
 	}
-	return api.JobResults{}, code, nil
+	m, _ := resp.(map[string]any)
+	out := api.JobResults{
+		WindowSec:          asInt(m, "window_sec"),
+		Packets:            asUint64(m, "packets_total"),
+		Bytes:              asUint64(m, "bytes_total"),
+		Errors:             map[string]uint64{},
+		TopFlows:           []api.TopFlow{},
+		LatencyHistogramNs: api.Histogram{Bounds: []uint64{}, Counts: []uint64{}},
+		OTLPExport: api.OTLPInfo{
+			Exported: asBool(m, "otlp_export"),
+			Endpoint: c.OTLPEndpoint,
+		},
+		StopReason: asString(m, "stop_reason"),
+	}
+	if cpu, ok := m["cpu_seconds"].(float64); ok {
+		out.CPUSeconds = cpu
+	}
+	if errs, ok := m["errors"].(map[string]uint64); ok {
+		out.Errors = errs
+	}
+	if flows, ok := m["top_flows"].([]FlowSummary); ok {
+		for _, f := range flows {
+			out.TopFlows = append(out.TopFlows, api.TopFlow{FiveTuple: f.FiveTuple, Pkts: f.Packets, Bytes: f.Bytes})
+		}
+	}
+	if members, ok := m["members"].([]MemberResult); ok {
+		for _, mr := range members {
+			out.Members = append(out.Members, api.MemberResults{Port: mr.Port, Interface: mr.IfName, Packets: mr.Packets, Bytes: mr.Bytes})
+		}
+	}
+	return out, code, nil
 }
 
 /* ---------- small helpers for safe conversions ---------- */
@@ -111,6 +646,26 @@ func asInt(m map[string]any, k string) int {
 	}
 }
 
+func asUint64(m map[string]any, k string) uint64 {
+	switch v := m[k].(type) {
+	case uint64:
+		return v
+	case int:
+		return uint64(v)
+	case int64:
+		return uint64(v)
+	case float64:
+		return uint64(v)
+	default:
+		return 0
+	}
+}
+
+func asBool(m map[string]any, k string) bool {
+	v, _ := m[k].(bool)
+	return v
+}
+
 func asTime(m map[string]any, k string) time.Time {
 	switch v := m[k].(type) {
 	case time.Time:
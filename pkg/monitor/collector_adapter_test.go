@@ -4,8 +4,19 @@ package monitor
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	apispec "github.com/platformbuilds/telegen-sonic/api"
+	"github.com/platformbuilds/telegen-sonic/pkg/api"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
 
 func TestBPFCollectorAdapter_Run_NoOp(t *testing.T) {
@@ -13,7 +24,7 @@ func TestBPFCollectorAdapter_Run_NoOp(t *testing.T) {
 	// Using nil receiver is fine because Run is a no-op in this design.
 	adapter := NewBPFCollector(nil)
 
-	rp, err := adapter.Run(context.Background(), "job-1", JobSpec{Port: "Eth0", Duration: 10 * time.Second})
+	rp, err := adapter.Run(context.Background(), "job-1", []string{"erspan0"}, JobSpec{Port: "Eth0", Duration: 10 * time.Second})
 	if err != nil {
 		t.Fatalf("Run returned error: %v", err)
 	}
@@ -21,3 +32,382 @@ func TestBPFCollectorAdapter_Run_NoOp(t *testing.T) {
 		t.Fatalf("expected non-nil results provider")
 	}
 }
+
+func TestJobResults_SummaryDeltasAndTopFlows(t *testing.T) {
+	k1 := FlowKey{Family: 4, L4Proto: 6, Sport: 443, Dport: 5000}
+	copy(k1.Saddr[:], []byte{10, 0, 0, 1})
+	copy(k1.Daddr[:], []byte{10, 0, 0, 2})
+	k2 := FlowKey{Family: 4, L4Proto: 17, Sport: 53, Dport: 6000}
+
+	r := &jobResults{
+		start:     time.Now().Add(-2 * time.Second),
+		base:      ProtoStats{Packets: 100, Bytes: 1000},
+		last:      ProtoStats{Packets: 160, Bytes: 1900},
+		baseFlows: map[FlowKey]ProtoStats{k1: {Packets: 10, Bytes: 100}, k2: {Packets: 5, Bytes: 50}},
+		lastFlows: map[FlowKey]ProtoStats{k1: {Packets: 50, Bytes: 700}, k2: {Packets: 5, Bytes: 50}},
+		errors:    map[string]uint64{},
+	}
+	r.Finalize()
+
+	sum := r.Summary().(JobSummary)
+	if sum.Packets != 60 || sum.Bytes != 900 {
+		t.Fatalf("unexpected totals: %+v", sum)
+	}
+	if sum.Window < 2*time.Second {
+		t.Fatalf("window too short: %v", sum.Window)
+	}
+	if len(sum.TopFlows) != 1 {
+		t.Fatalf("idle flows should be dropped: %+v", sum.TopFlows)
+	}
+	if got := sum.TopFlows[0]; got.FiveTuple != "10.0.0.1:443->10.0.0.2:5000/TCP" || got.Packets != 40 || got.Bytes != 600 {
+		t.Fatalf("unexpected top flow: %+v", got)
+	}
+}
+
+func TestCoreAdapter_GetResults_MapsSummary(t *testing.T) {
+	rp := &summaryResults{sum: JobSummary{
+		Window:   5 * time.Second,
+		Packets:  7,
+		Bytes:    700,
+		Errors:   map[string]uint64{"counter_read": 2},
+		TopFlows: []FlowSummary{{FiveTuple: "a->b/UDP", Packets: 7, Bytes: 700}},
+	}}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{results: rp}, 2)
+	core := &CoreAdapter{S: sup, OTLPEndpoint: "collector:4317"}
+
+	resp, _, err := core.TryStartJob(api.StartJobRequest{Port: "Ethernet0", DurationSec: 1, OTLPExport: true})
+	if err != nil {
+		t.Fatalf("TryStartJob: %v", err)
+	}
+	// wait until the collector has been wired to the job
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		sup.mu.RLock()
+		ready := sup.jobs[resp.JobID].results != nil
+		sup.mu.RUnlock()
+		if ready {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("collector never ran")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	res, code, err := core.GetResults(resp.JobID)
+	if err != nil || code != 200 {
+		t.Fatalf("GetResults err=%v code=%d", err, code)
+	}
+	if res.WindowSec != 5 || res.Packets != 7 || res.Bytes != 700 || res.Errors["counter_read"] != 2 {
+		t.Fatalf("unexpected results: %+v", res)
+	}
+	if len(res.TopFlows) != 1 || res.TopFlows[0].FiveTuple != "a->b/UDP" {
+		t.Fatalf("unexpected top flows: %+v", res.TopFlows)
+	}
+	if !res.OTLPExport.Exported || res.OTLPExport.Endpoint != "collector:4317" {
+		t.Fatalf("unexpected otel_export: %+v", res.OTLPExport)
+	}
+	_, _, _ = core.StopJob(resp.JobID)
+}
+
+func TestJobResults_CheckBudget(t *testing.T) {
+	newResults := func(budget JobSpec) *jobResults {
+		return &jobResults{
+			start:  time.Now(),
+			base:   ProtoStats{Packets: 100, Bytes: 1000},
+			last:   ProtoStats{Packets: 100, Bytes: 1000},
+			errors: map[string]uint64{},
+			budget: budget,
+			stop:   make(chan struct{}),
+		}
+	}
+
+	r := newResults(JobSpec{MaxPackets: 50})
+	r.last.Packets = 149
+	if r.checkBudget() {
+		t.Fatalf("49 packets should be within a budget of 50")
+	}
+	r.last.Packets = 150
+	if !r.checkBudget() {
+		t.Fatalf("50 packets should use up a budget of 50")
+	}
+	select {
+	case <-r.StopC():
+	default:
+		t.Fatalf("StopC should be closed once the budget is used up")
+	}
+	if r.StopReason() != StopBudgetExceeded {
+		t.Fatalf("unexpected stop reason %q", r.StopReason())
+	}
+	// a second check must not close the channel again
+	if !r.checkBudget() {
+		t.Fatalf("an exceeded budget stays exceeded")
+	}
+
+	r = newResults(JobSpec{MaxBytes: 500})
+	r.last.Bytes = 1600
+	if !r.checkBudget() {
+		t.Fatalf("600 bytes should exceed a budget of 500")
+	}
+
+	cpu := 2 * time.Second
+	r = newResults(JobSpec{MaxCPU: time.Second})
+	r.cpu = func() (time.Duration, error) { return cpu, nil }
+	r.baseCPU = 2 * time.Second
+	if r.checkBudget() {
+		t.Fatalf("no CPU used yet")
+	}
+	cpu = 3500 * time.Millisecond
+	if !r.checkBudget() {
+		t.Fatalf("1.5s of CPU should exceed a budget of 1s")
+	}
+	r.Finalize()
+	if got := r.Summary().(JobSummary).CPU; got != 1500*time.Millisecond {
+		t.Fatalf("summary CPU = %s, want 1.5s", got)
+	}
+}
+
+func TestBPFCollectorAdapter_CPUBudgetNeedsRuntimeStats(t *testing.T) {
+	a := &BPFCollectorAdapter{mc: &MetricsCollector{}, cpu: ProgramRuntime}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, a, 1)
+	spec := JobSpec{Port: "Ethernet0", Duration: time.Minute, MaxCPU: time.Second}
+
+	_, code, err := sup.TryStartJob(startReq{spec})
+	var inv *InvalidJobError
+	if code != 400 || !errors.As(err, &inv) || inv.Problems[0].Field != "max_cpu_seconds" {
+		t.Fatalf("without run-time stats: code=%d err=%v", code, err)
+	}
+	resp, _, _ := sup.ValidateJob(startReq{spec})
+	if ps := resp.(map[string]interface{})["problems"].([]Problem); len(ps) != 1 || ps[0].Field != "max_cpu_seconds" {
+		t.Fatalf("ValidateJob problems = %+v", ps)
+	}
+
+	runtimeStats.Store(true)
+	defer runtimeStats.Store(false)
+	if ps := a.Preflight(spec); len(ps) != 0 {
+		t.Fatalf("with run-time stats: %+v", ps)
+	}
+}
+
+func TestBPFCollectorAdapter_BudgetNeedsCollector(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, NewBPFCollector(nil), 1)
+	spec := JobSpec{Port: "Ethernet0", Duration: time.Minute, MaxPackets: 100, MaxBytes: 1000}
+	_, code, err := sup.TryStartJob(startReq{spec})
+	var inv *InvalidJobError
+	if code != 400 || !errors.As(err, &inv) || len(inv.Problems) != 2 {
+		t.Fatalf("without a collector: code=%d err=%v", code, err)
+	}
+
+	// budgets are not dropped when the job's interfaces cannot be counted
+	if _, err := NewBPFCollector(nil).Run(context.Background(), "j1", []string{"mirror0"}, spec); !errors.Is(err, ErrBudgetUnenforced) {
+		t.Fatalf("Run without a collector: %v", err)
+	}
+	a := &BPFCollectorAdapter{mc: &MetricsCollector{}}
+	if _, err := a.Run(context.Background(), "j1", []string{"no-such-if0"}, spec); !errors.Is(err, ErrBudgetUnenforced) {
+		t.Fatalf("Run without a countable interface: %v", err)
+	}
+	if _, err := a.Run(context.Background(), "j1", []string{"no-such-if0"}, JobSpec{Port: "Ethernet0"}); err != nil {
+		t.Fatalf("jobs without a budget still run: %v", err)
+	}
+}
+
+func TestStartRequest_ToSpec_Budgets(t *testing.T) {
+	spec := startRequest(api.StartJobRequest{MaxPackets: 1e6, MaxBytes: 1 << 30, MaxCPUSeconds: 1.5}).ToSpec()
+	if spec.MaxPackets != 1e6 || spec.MaxBytes != 1<<30 || spec.MaxCPU != 1500*time.Millisecond {
+		t.Fatalf("budgets not mapped: %+v", spec)
+	}
+	if back := specRequest(spec); back.MaxCPUSeconds != 1.5 || back.MaxPackets != 1e6 {
+		t.Fatalf("budgets lost on the way back: %+v", back)
+	}
+}
+
+func TestStartRequest_ToSpec_Notify(t *testing.T) {
+	spec := startRequest(api.StartJobRequest{Notify: &api.Notify{WebhookURL: "https://hooks.example/t"}}).ToSpec()
+	if spec.Notify == nil || spec.Notify.WebhookURL != "https://hooks.example/t" {
+		t.Fatalf("notify not mapped: %+v", spec.Notify)
+	}
+	if back := specRequest(spec); back.Notify == nil || back.Notify.WebhookURL != spec.Notify.WebhookURL {
+		t.Fatalf("notify lost on the way back: %+v", back.Notify)
+	}
+	if spec := startRequest(api.StartJobRequest{}).ToSpec(); spec.Notify != nil {
+		t.Fatalf("notify should stay nil")
+	}
+}
+
+func TestJobResults_ExportsJobMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")
+	pkts, _ := meter.Int64Counter("bpf.job.packets")
+	bytes, _ := meter.Int64Counter("bpf.job.bytes")
+	mc := &MetricsCollector{jobPacketsCtr: pkts, jobBytesCtr: bytes}
+
+	spec := JobSpec{Port: "Ethernet0", Labels: map[string]string{"team": "netops", "secret": "x"}}
+	r := &jobResults{
+		mc:      mc,
+		export:  true,
+		attrs:   otelmetric.WithAttributes(jobAttributes("j1", spec, []string{"team", "absent"})...),
+		base:    ProtoStats{Packets: 100, Bytes: 1000},
+		emitted: ProtoStats{Packets: 100, Bytes: 1000},
+		errors:  map[string]uint64{},
+	}
+	r.last = ProtoStats{Packets: 130, Bytes: 1600}
+	r.emit()
+	r.last = ProtoStats{Packets: 150, Bytes: 1900}
+	r.emit()
+	r.emit() // nothing new
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	got := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum := m.Data.(metricdata.Sum[int64])
+			if len(sum.DataPoints) != 1 {
+				t.Fatalf("%s: %d series", m.Name, len(sum.DataPoints))
+			}
+			dp := sum.DataPoints[0]
+			if v, _ := dp.Attributes.Value("job.id"); v.AsString() != "j1" {
+				t.Fatalf("%s: job.id = %v", m.Name, v)
+			}
+			if v, _ := dp.Attributes.Value("job.label.team"); v.AsString() != "netops" {
+				t.Fatalf("%s: allowlisted label missing", m.Name)
+			}
+			if _, ok := dp.Attributes.Value("job.label.secret"); ok {
+				t.Fatalf("%s: label outside the allowlist was exported", m.Name)
+			}
+			got[m.Name] = dp.Value
+		}
+	}
+	if got["bpf.job.packets"] != 50 || got["bpf.job.bytes"] != 900 {
+		t.Fatalf("job totals = %v", got)
+	}
+}
+
+func TestCoreAdapter_ValidateJob(t *testing.T) {
+	core := &CoreAdapter{S: NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)}
+	resp, code, err := core.ValidateJob(api.StartJobRequest{Port: "Ethernet0", Direction: "sideways", DurationSec: 60})
+	if err != nil || code != 200 {
+		t.Fatalf("ValidateJob err=%v code=%d", err, code)
+	}
+	if resp.Valid || resp.Admission != AdmitStart || len(resp.Problems) != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if p := resp.Problems[0]; p.Field != "direction" || p.Code != ProblemInvalid || p.Message == "" {
+		t.Fatalf("unexpected problem: %+v", p)
+	}
+}
+
+func TestCoreAdapter_TryStartJob_Problems(t *testing.T) {
+	core := &CoreAdapter{S: NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)}
+	_, code, err := core.TryStartJob(api.StartJobRequest{Port: "Ethernet0", DurationSec: 60,
+		Owner: strings.Repeat("o", maxOwner+1), Priority: MaxPriority + 1})
+	var invalid *api.InvalidRequestError
+	if code != 400 || !errors.As(err, &invalid) {
+		t.Fatalf("expected 400 with problems, got code=%d err=%v", code, err)
+	}
+	got := map[string]string{}
+	for _, p := range invalid.Problems {
+		got[p.Field] = p.Code
+	}
+	if len(got) != 2 || got["owner"] != ProblemInvalid || got["priority"] != ProblemInvalid {
+		t.Fatalf("problems = %+v", invalid.Problems)
+	}
+}
+
+func TestScheduleAdapter_JobProblems(t *testing.T) {
+	sched := &ScheduleAdapter{S: NewScheduler(NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1), nil)}
+	_, code, err := sched.CreateSchedule(api.ScheduleRequest{Cron: "@hourly", Job: api.StartJobRequest{
+		Port: "Ethernet0", DurationSec: 60, Labels: map[string]string{"team": strings.Repeat("x", maxLabelValue+1)}}})
+	var invalid *api.InvalidRequestError
+	if code != 400 || !errors.As(err, &invalid) || invalid.Problems[0].Field != "job.labels.team" {
+		t.Fatalf("expected the job's problems under job., got code=%d err=%v", code, err)
+	}
+}
+
+func TestJobResults_PerInterface(t *testing.T) {
+	r := &jobResults{
+		start: time.Now(),
+		ifs: []jobIf{
+			{name: "mirror-Eth0", base: ProtoStats{Packets: 10, Bytes: 100}, last: ProtoStats{Packets: 15, Bytes: 150}},
+			{name: "mirror-Eth4", base: ProtoStats{Packets: 0, Bytes: 0}, last: ProtoStats{Packets: 7, Bytes: 70}},
+		},
+		base:   ProtoStats{Packets: 10, Bytes: 100},
+		last:   ProtoStats{Packets: 22, Bytes: 220},
+		errors: map[string]uint64{},
+		final:  true,
+	}
+	sum := r.Summary().(JobSummary)
+	if sum.Packets != 12 || sum.Interfaces["mirror-Eth0"].Packets != 5 || sum.Interfaces["mirror-Eth4"].Bytes != 70 {
+		t.Fatalf("unexpected summary: %+v", sum)
+	}
+}
+
+// TestCoreAdapter_Contract serves the adapters of a real Supervisor with
+// the OpenAPI description enforced, so that what the agent answers, and
+// not only what the api package's stubs answer, matches the spec.
+func TestCoreAdapter_Contract(t *testing.T) {
+	spec, err := api.LoadSpec(apispec.OpenAPI)
+	if err != nil {
+		t.Fatalf("LoadSpec: %v", err)
+	}
+	rp := &summaryResults{sum: JobSummary{
+		Window: 5 * time.Second, Packets: 7, Bytes: 700,
+		Errors:   map[string]uint64{"counter_read": 2},
+		TopFlows: []FlowSummary{{FiveTuple: "a->b/UDP", Packets: 7, Bytes: 700}},
+	}}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{results: rp}, 1,
+		WithQueue(1, time.Minute), WithMaxDuration(time.Hour))
+	h := &api.Handlers{
+		Core:      &CoreAdapter{S: sup},
+		Schedules: &ScheduleAdapter{S: NewScheduler(sup, nil)},
+		Contract:  spec,
+		ContractViolation: func(r *http.Request, status int, ps []api.Problem) {
+			for _, p := range ps {
+				t.Errorf("%s %s answered %d: %s: %s", r.Method, r.URL.Path, status, p.Field, p.Message)
+			}
+		},
+	}
+	router := api.NewRouter(h)
+	do := func(method, path, body string, want int) map[string]interface{} {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("%s %s = %d, want %d: %s", method, path, rec.Code, want, rec.Body)
+		}
+		var out map[string]interface{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &out)
+		return out
+	}
+
+	job := `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":60,"sample_rate":100}`
+	do(http.MethodPost, "/v1/monitor/jobs:validate", job, http.StatusOK)
+	id := do(http.MethodPost, "/v1/monitor/jobs", job, http.StatusCreated)["job_id"].(string)
+	waitState(t, sup, id, JobRunning)
+	if st := do(http.MethodGet, "/v1/monitor/jobs/"+id, "", http.StatusOK); st["sample_rate"] != 100.0 {
+		t.Fatalf("sample_rate = %v", st["sample_rate"])
+	}
+	queued := do(http.MethodPost, "/v1/monitor/jobs", `{"port":"Ethernet4","direction":"egress","span_method":"span","duration_sec":60}`, http.StatusAccepted)
+	qid := queued["job_id"].(string)
+	do(http.MethodGet, "/v1/monitor/jobs/"+qid, "", http.StatusOK)
+	do(http.MethodGet, "/v1/monitor/jobs/"+qid+"/results", "", http.StatusOK)
+	do(http.MethodGet, "/v1/monitor/jobs/"+id+"/results", "", http.StatusOK)
+	do(http.MethodPatch, "/v1/monitor/jobs/"+id, `{"duration_sec":120}`, http.StatusOK)
+	do(http.MethodGet, "/v1/monitor/jobs", "", http.StatusOK)
+	do(http.MethodPost, "/v1/monitor/jobs", `{"port":"Ethernet0","direction":"ingress","span_method":"span","duration_sec":7200}`, http.StatusBadRequest)
+
+	sched := do(http.MethodPost, "/v1/monitor/schedules", `{"cron":"@hourly","job":`+job+`}`, http.StatusCreated)
+	do(http.MethodGet, "/v1/monitor/schedules", "", http.StatusOK)
+	do(http.MethodDelete, "/v1/monitor/schedules/"+sched["schedule_id"].(string), "", http.StatusNoContent)
+
+	do(http.MethodDelete, "/v1/monitor/jobs/"+qid, "", http.StatusOK)
+	do(http.MethodDelete, "/v1/monitor/jobs/"+id, "", http.StatusOK)
+	waitState(t, sup, id, JobDone)
+	do(http.MethodGet, "/v1/monitor/jobs/"+id+"/results", "", http.StatusOK)
+}
@@ -0,0 +1,67 @@
+//go:build linux
+
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"golang.org/x/sys/unix"
+)
+
+// runtimeStats is set once EnableRuntimeStats succeeded; without it the
+// kernel reports a run time of zero for every program.
+var runtimeStats atomic.Bool
+
+// EnableRuntimeStats turns on the kernel's BPF run-time accounting (5.8+),
+// which max_cpu_seconds budgets are measured with. Accounting stays on
+// until the returned Closer is closed.
+func EnableRuntimeStats() (io.Closer, error) {
+	c, err := ebpf.EnableStats(uint32(unix.BPF_STATS_RUN_TIME))
+	if err != nil {
+		return nil, fmt.Errorf("enable bpf run-time stats: %w", err)
+	}
+	runtimeStats.Store(true)
+	return c, nil
+}
+
+var tcProgID = regexp.MustCompile(`\bid (\d+)`)
+
+// ProgramRuntime returns the CPU time the kernel has spent in the tc program
+// attached to ifname's ingress. tc loads one program per interface, so this
+// is the data-plane cost of the jobs watching ifname.
+func ProgramRuntime(ifname string) (time.Duration, error) {
+	if !runtimeStats.Load() {
+		return 0, errors.New("bpf run-time stats are not enabled")
+	}
+	out, err := exec.Command("tc", "filter", "show", "dev", ifname, "ingress").CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("tc filter show %s: %v: %s", ifname, err, out)
+	}
+	m := tcProgID.FindSubmatch(out)
+	if m == nil {
+		return 0, fmt.Errorf("no bpf program attached to %s", ifname)
+	}
+	id, _ := strconv.ParseUint(string(m[1]), 10, 32)
+	prog, err := ebpf.NewProgramFromID(ebpf.ProgramID(id))
+	if err != nil {
+		return 0, fmt.Errorf("open program %d: %w", id, err)
+	}
+	defer prog.Close()
+	info, err := prog.Info()
+	if err != nil {
+		return 0, fmt.Errorf("program %d info: %w", id, err)
+	}
+	rt, ok := info.Runtime()
+	if !ok {
+		return 0, fmt.Errorf("program %d: run time not reported by this kernel", id)
+	}
+	return rt, nil
+}
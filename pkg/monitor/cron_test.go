@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	// Friday 2025-08-15 12:34:56 UTC
+	from := time.Date(2025, 8, 15, 12, 34, 56, 0, time.UTC)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 8, 15, 12, 35, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2025, 8, 15, 13, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 8, 15, 13, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 8, 15, 12, 45, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2025, 8, 15, 12, 45, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2025, 8, 16, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2025, 8, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1", time.Date(2025, 8, 18, 0, 0, 0, 0, time.UTC)},  // next Monday
+		{"0 0 * * 7", time.Date(2025, 8, 17, 0, 0, 0, 0, time.UTC)},  // 7 is Sunday
+		{"0 0 1 1 *", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},   // rolls the year
+		{"0 0 31 * *", time.Date(2025, 8, 31, 0, 0, 0, 0, time.UTC)}, // skips short months later
+		{"0 0 13 * 5", time.Date(2025, 8, 22, 0, 0, 0, 0, time.UTC)}, // dom OR dow
+		{"0,30 12 15 8 *", time.Date(2026, 8, 15, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		expr, err := parseCron(c.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", c.expr, err)
+		}
+		if got := expr.next(from); !got.Equal(c.want) {
+			t.Errorf("%q: next = %s, want %s", c.expr, got, c.want)
+		}
+	}
+
+	// impossible dates never fire
+	expr, err := parseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := expr.next(from); !got.IsZero() {
+		t.Fatalf("Feb 30 should never fire, got %s", got)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+		"* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@often",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) should fail", expr)
+		}
+	}
+}
@@ -3,6 +3,23 @@ package monitor
 import "errors"
 
 var (
-	ErrConcurrencyLimit = errors.New("only 2 concurrent jobs are allowed, try again later")
-	ErrJobNotFound      = errors.New("job not found")
+	ErrConcurrencyLimit  = errors.New("too many concurrent jobs, try again later")
+	ErrJobNotFound       = errors.New("job not found")
+	ErrQueueFull         = errors.New("job queue is full, try again later")
+	ErrJobNotActive      = errors.New("job is not active")
+	ErrPortConflict      = errors.New("port is already being monitored")
+	ErrIllegalTransition = errors.New("illegal job state transition")
+	ErrJobNotUpdatable   = errors.New("only queued or running jobs can be updated")
+	ErrDurationTooLong   = errors.New("duration exceeds the configured maximum")
+	ErrInvalidUpdate     = errors.New("invalid job update")
+	ErrSharedInterface   = errors.New("interface is shared with another job")
+	ErrScheduleNotFound  = errors.New("schedule not found")
+	ErrInvalidSchedule   = errors.New("invalid schedule")
+	ErrShuttingDown      = errors.New("agent is shutting down")
+	ErrInvalidNotify     = errors.New("invalid notify block")
+	ErrInvalidMetadata   = errors.New("invalid job metadata")
+	ErrInvalidPriority   = errors.New("invalid priority")
+	ErrInvalidJob        = errors.New("invalid job request")
+	ErrBudgetUnenforced  = errors.New("job budget cannot be enforced")
+	ErrPortNotFound      = errors.New("port not found")
 )
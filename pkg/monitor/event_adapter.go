@@ -0,0 +1,41 @@
+//go:build linux
+
+package monitor
+
+import (
+	"github.com/platformbuilds/telegen-sonic/pkg/api"
+)
+
+// EventAdapter exposes an EventBus as api.EventCore.
+type EventAdapter struct {
+	B *EventBus
+}
+
+func apiEvent(e Event) api.Event {
+	return api.Event{ID: e.ID, Time: e.Time, JobID: e.JobID, State: string(e.State), Reason: e.Reason}
+}
+
+func (a *EventAdapter) Subscribe(jobID string, lastID uint64) ([]api.Event, <-chan api.Event, func()) {
+	backlog, src, cancel := a.B.Subscribe(jobID, lastID)
+	out := make([]api.Event, 0, len(backlog))
+	for _, e := range backlog {
+		out = append(out, apiEvent(e))
+	}
+	ch := make(chan api.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(ch)
+		for e := range src {
+			select {
+			case ch <- apiEvent(e):
+			case <-done:
+				return
+			}
+		}
+	}()
+	stop := func() {
+		close(done)
+		cancel()
+	}
+	return out, ch, stop
+}
@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// eventBacklog is how many past events the bus keeps for resuming
+// subscribers.
+const eventBacklog = 1024
+
+// subscriberBuffer is how far a subscriber may fall behind before it is
+// dropped; it can resume from the last event it saw.
+const subscriberBuffer = 64
+
+// Event is one job state change.
+type Event struct {
+	ID    uint64    `json:"id"`
+	Time  time.Time `json:"time"`
+	JobID string    `json:"job_id"`
+	State JobState  `json:"state"`
+	// Reason is the failure reason of a failed job and the stop reason
+	// of a stopping or done one.
+	Reason string `json:"reason,omitempty"`
+}
+
+// EventBus fans job events out to subscribers and keeps a bounded backlog
+// so a subscriber can resume after a disconnect. Event IDs increase by one
+// per event and restart at 1 with the agent.
+type EventBus struct {
+	mu     sync.Mutex
+	lastID uint64
+	ring   []Event // oldest first, at most eventBacklog long
+	subs   map[*eventSub]struct{}
+	closed bool
+}
+
+type eventSub struct {
+	jobID string // "" receives every job
+	ch    chan Event
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[*eventSub]struct{})}
+}
+
+// Publish stamps e with the next ID and the current time and delivers it.
+// It never blocks: a subscriber whose buffer is full is dropped.
+func (b *EventBus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.lastID++
+	e.ID = b.lastID
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if len(b.ring) == eventBacklog {
+		copy(b.ring, b.ring[1:])
+		b.ring = b.ring[:eventBacklog-1]
+	}
+	b.ring = append(b.ring, e)
+	for sub := range b.subs {
+		if sub.jobID != "" && sub.jobID != e.JobID {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe returns the buffered events after lastID followed by a channel
+// of new ones, both limited to jobID unless it is empty. lastID 0 skips the
+// backlog; an ID the bus has not issued (from before an agent restart)
+// replays all of it. The channel is closed when the subscriber falls too
+// far behind, cancel is called or the bus is closed.
+func (b *EventBus) Subscribe(jobID string, lastID uint64) ([]Event, <-chan Event, func()) {
+	sub := &eventSub{jobID: jobID, ch: make(chan Event, subscriberBuffer)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []Event
+	if lastID > 0 {
+		if lastID > b.lastID {
+			lastID = 0
+		}
+		for _, e := range b.ring {
+			if e.ID > lastID && (jobID == "" || e.JobID == jobID) {
+				backlog = append(backlog, e)
+			}
+		}
+	}
+	if b.closed {
+		close(sub.ch)
+		return backlog, sub.ch, func() {}
+	}
+	b.subs[sub] = struct{}{}
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[sub]; ok {
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+	return backlog, sub.ch, cancel
+}
+
+// Close ends every subscription; later events are discarded.
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
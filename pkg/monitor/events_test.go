@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"testing"
+)
+
+func eventIDs(evs []Event) []uint64 {
+	var ids []uint64
+	for _, e := range evs {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestEventBus_FilterAndResume(t *testing.T) {
+	b := NewEventBus()
+	b.Publish(Event{JobID: "a", State: JobStarting})
+	b.Publish(Event{JobID: "b", State: JobStarting})
+	b.Publish(Event{JobID: "a", State: JobRunning})
+
+	backlog, _, cancel := b.Subscribe("", 0)
+	cancel()
+	if len(backlog) != 0 {
+		t.Fatalf("lastID 0 should skip the backlog, got %v", eventIDs(backlog))
+	}
+
+	backlog, ch, cancel := b.Subscribe("a", 1)
+	defer cancel()
+	if got := eventIDs(backlog); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("resume after 1 for job a = %v, want [3]", got)
+	}
+	b.Publish(Event{JobID: "b", State: JobRunning})
+	b.Publish(Event{JobID: "a", State: JobStopping, Reason: StopRequested})
+	e := <-ch
+	if e.ID != 5 || e.JobID != "a" || e.Reason != StopRequested || e.Time.IsZero() {
+		t.Fatalf("live event = %+v", e)
+	}
+
+	// an ID from before a restart replays everything buffered
+	backlog, _, cancel2 := b.Subscribe("", 99)
+	cancel2()
+	if len(backlog) != 5 {
+		t.Fatalf("unknown lastID should replay all 5 events, got %v", eventIDs(backlog))
+	}
+}
+
+func TestEventBus_BacklogIsBounded(t *testing.T) {
+	b := NewEventBus()
+	for i := 0; i < eventBacklog+10; i++ {
+		b.Publish(Event{JobID: "a", State: JobRunning})
+	}
+	backlog, _, cancel := b.Subscribe("", 1)
+	defer cancel()
+	if len(backlog) != eventBacklog || backlog[0].ID != 11 {
+		t.Fatalf("backlog len=%d first=%d", len(backlog), backlog[0].ID)
+	}
+}
+
+func TestEventBus_DropsSlowSubscriber(t *testing.T) {
+	b := NewEventBus()
+	_, ch, cancel := b.Subscribe("", 0)
+	defer cancel()
+	for i := 0; i < subscriberBuffer+1; i++ {
+		b.Publish(Event{JobID: "a", State: JobRunning})
+	}
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Fatalf("received %d events before the drop, want %d", n, subscriberBuffer)
+	}
+}
+
+func TestEventBus_Close(t *testing.T) {
+	b := NewEventBus()
+	_, ch, cancel := b.Subscribe("", 0)
+	b.Close()
+	if _, ok := <-ch; ok {
+		t.Fatalf("channel should be closed")
+	}
+	cancel() // must not double-close
+
+	b.Publish(Event{JobID: "a", State: JobRunning})
+	_, ch, _ = b.Subscribe("", 0)
+	if _, ok := <-ch; ok {
+		t.Fatalf("subscribing to a closed bus should return a closed channel")
+	}
+}
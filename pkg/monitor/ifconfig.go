@@ -0,0 +1,172 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/cilium/ebpf"
+)
+
+// Keep these in sync with bpf/tc_ingress.bpf.c
+const (
+	maxCfgPorts = 4
+
+	protoBitTCP  = 1 << 0
+	protoBitUDP  = 1 << 1
+	protoBitICMP = 1 << 2
+)
+
+// IfConfig mirrors struct if_config in bpf/tc_ingress.bpf.c. It carries the
+// sampling and flow filters of the job watching an interface.
+type IfConfig struct {
+	SampleRate uint32
+	ProtoMask  uint32
+	Sports     [maxCfgPorts]uint16
+	Dports     [maxCfgPorts]uint16
+}
+
+// ifConfigFromSpec translates the job's sample rate and the filters the data
+// plane understands (ip_proto, l4_sport, l4_dport) into an IfConfig. Any
+// other filter is an error rather than silently matching everything.
+func ifConfigFromSpec(spec JobSpec) (IfConfig, error) {
+	var cfg IfConfig
+	for k := range spec.Filters {
+		switch k {
+		case "ip_proto", "l4_sport", "l4_dport":
+		default:
+			return cfg, fmt.Errorf("filters.%s: unsupported filter; use ip_proto, l4_sport or l4_dport", k)
+		}
+	}
+	if spec.SampleRate > 0 {
+		cfg.SampleRate = uint32(spec.SampleRate)
+	}
+	if v, ok := spec.Filters["ip_proto"]; ok && v != nil {
+		protos, err := filterStrings(v)
+		if err != nil {
+			return cfg, fmt.Errorf("filters.ip_proto: %w", err)
+		}
+		for _, p := range protos {
+			switch strings.ToLower(p) {
+			case "tcp":
+				cfg.ProtoMask |= protoBitTCP
+			case "udp":
+				cfg.ProtoMask |= protoBitUDP
+			case "icmp", "icmpv6":
+				cfg.ProtoMask |= protoBitICMP
+			default:
+				return cfg, fmt.Errorf("filters.ip_proto: unsupported protocol %q", p)
+			}
+		}
+	}
+	var err error
+	if cfg.Sports, err = filterPorts(spec.Filters, "l4_sport"); err != nil {
+		return cfg, err
+	}
+	if cfg.Dports, err = filterPorts(spec.Filters, "l4_dport"); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func filterStrings(v interface{}) ([]string, error) {
+	switch t := v.(type) {
+	case string:
+		return []string{t}, nil
+	case []string:
+		return t, nil
+	case []interface{}:
+		out := make([]string, 0, len(t))
+		for _, e := range t {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected strings, got %T", e)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("expected a string or list of strings, got %T", v)
+}
+
+func filterPorts(filters map[string]interface{}, key string) ([maxCfgPorts]uint16, error) {
+	var out [maxCfgPorts]uint16
+	v, ok := filters[key]
+	if !ok || v == nil {
+		return out, nil
+	}
+	var nums []float64
+	switch t := v.(type) {
+	case float64:
+		nums = []float64{t}
+	case int:
+		nums = []float64{float64(t)}
+	case []int:
+		for _, n := range t {
+			nums = append(nums, float64(n))
+		}
+	case []interface{}:
+		for _, e := range t {
+			n, ok := e.(float64)
+			if !ok {
+				return out, fmt.Errorf("filters.%s: expected numbers, got %T", key, e)
+			}
+			nums = append(nums, n)
+		}
+	default:
+		return out, fmt.Errorf("filters.%s: expected a port or list of ports, got %T", key, v)
+	}
+	if len(nums) > maxCfgPorts {
+		return out, fmt.Errorf("filters.%s: at most %d ports are supported", key, maxCfgPorts)
+	}
+	for i, n := range nums {
+		if n < 1 || n > 65535 || n != float64(int(n)) {
+			return out, fmt.Errorf("filters.%s: invalid port %v", key, n)
+		}
+		out[i] = uint16(n)
+	}
+	return out, nil
+}
+
+// configure writes spec's data-plane parameters for ifname; a TC without
+// a config map leaves the interface tracking every packet.
+func (t *TC) configure(ifname string, spec JobSpec) error {
+	if t.ConfigMap == nil {
+		return nil
+	}
+	cfg, err := ifConfigFromSpec(spec)
+	if err != nil {
+		return err
+	}
+	ifi, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return fmt.Errorf("lookup %s: %w", ifname, err)
+	}
+	key := uint32(ifi.Index)
+	if err := t.ConfigMap.Put(&key, &cfg); err != nil {
+		return fmt.Errorf("update if_config[%s]: %w", ifname, err)
+	}
+	return nil
+}
+
+// unconfigure drops the parameters for ifname.
+func (t *TC) unconfigure(ifname string) {
+	if t.ConfigMap == nil {
+		return
+	}
+	ifi, err := net.InterfaceByName(ifname)
+	if err != nil {
+		return
+	}
+	key := uint32(ifi.Index)
+	if err := t.ConfigMap.Delete(&key); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+		fmt.Printf("clear if_config[%s]: %v\n", ifname, err)
+	}
+}
+
+// Update pushes a running job's new sample rate and filters to the data
+// plane without touching the tc attachment.
+func (t *TC) Update(ifname string, spec JobSpec) error {
+	return t.configure(ifname, spec)
+}
@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIfConfigFromSpec(t *testing.T) {
+	var filters map[string]interface{}
+	// decoded the way the API hands filters over
+	body := `{"ip_proto": ["tcp", "UDP"], "l4_sport": [80, 443], "l4_dport": 53}`
+	if err := json.Unmarshal([]byte(body), &filters); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ifConfigFromSpec(JobSpec{SampleRate: 100, Filters: filters})
+	if err != nil {
+		t.Fatalf("ifConfigFromSpec: %v", err)
+	}
+	if cfg.SampleRate != 100 || cfg.ProtoMask != protoBitTCP|protoBitUDP {
+		t.Fatalf("unexpected sampling/protocols: %+v", cfg)
+	}
+	if cfg.Sports != [maxCfgPorts]uint16{80, 443} || cfg.Dports != [maxCfgPorts]uint16{53} {
+		t.Fatalf("unexpected ports: %+v", cfg)
+	}
+
+	empty, err := ifConfigFromSpec(JobSpec{})
+	if err != nil || empty != (IfConfig{}) {
+		t.Fatalf("no sampling or filters should track everything, got %+v err=%v", empty, err)
+	}
+}
+
+func TestIfConfigFromSpec_Invalid(t *testing.T) {
+	for name, f := range map[string]map[string]interface{}{
+		"unknown proto":  {"ip_proto": "sctp"},
+		"proto type":     {"ip_proto": 6.0},
+		"port range":     {"l4_dport": []interface{}{0.0}},
+		"port type":      {"l4_sport": []interface{}{"http"}},
+		"too many ports": {"l4_sport": []interface{}{1.0, 2.0, 3.0, 4.0, 5.0}},
+		"unsupported":    {"dscp": []interface{}{46.0}},
+		"unknown":        {"vni": 5.0},
+	} {
+		if _, err := ifConfigFromSpec(JobSpec{Filters: f}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -2,25 +2,136 @@ package monitor
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"regexp"
 	"sync"
 	"time"
 )
 
 type JobSpec struct {
-	Port         string
-	Direction    string
-	SpanMethod   string
-	VLAN         *int
-	Filters      map[string]interface{}
-	SampleRate   int
-	Duration     time.Duration
-	OTLPExport   bool
-	ResultDetail string
+	Port         string                 `json:"port"`
+	Ports        []string               `json:"ports,omitempty"` // several ports as one job, instead of Port
+	Direction    string                 `json:"direction"`
+	SpanMethod   string                 `json:"span_method"`
+	VLAN         *int                   `json:"vlan,omitempty"`
+	Filters      map[string]interface{} `json:"filters,omitempty"`
+	SampleRate   int                    `json:"sample_rate"`
+	Duration     time.Duration          `json:"duration"`
+	OTLPExport   bool                   `json:"otlp_export"`
+	ResultDetail string                 `json:"result_detail"`
+	// AllowShared lets this job overlap another job on the same port and
+	// direction when that job allows it too; each job keeps its own mirror.
+	AllowShared bool `json:"allow_shared,omitempty"`
+
+	// Optional budgets; a job that uses any of them up stops early as done
+	// with stop reason budget_exceeded. Zero means unlimited.
+	MaxPackets uint64        `json:"max_packets,omitempty"`
+	MaxBytes   uint64        `json:"max_bytes,omitempty"`
+	MaxCPU     time.Duration `json:"max_cpu,omitempty"` // data-plane CPU time
+
+	// Priority decides which job gives way when the gate is full: a job
+	// preempts the lowest-priority active job below its own, and queued
+	// jobs start in priority order. 0 (the default) never preempts.
+	Priority int `json:"priority,omitempty"`
+
+	// Notify, when set, is told about the job once it is done or failed.
+	Notify *Notify `json:"notify,omitempty"`
+
+	// Free-form metadata about who started the job and why. Labels can
+	// be used to select jobs and, when allowlisted, tag job metrics.
+	Labels      map[string]string `json:"labels,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Description string            `json:"description,omitempty"`
+
+	// JobID is set on the spec providers see, so that a mirror can be
+	// named after the job and member port it belongs to.
+	JobID string `json:"-"`
+}
+
+// MaxPriority is the highest job priority.
+const MaxPriority = 100
+
+// maxJobPorts caps the member ports of one job, after PortChannels are
+// expanded.
+const maxJobPorts = 64
+
+const (
+	maxLabels         = 32
+	maxLabelValue     = 255
+	maxOwner          = 128
+	maxDescription    = 1024
+	labelKeyMaxLength = 63
+)
+
+// labelKey allows keys such as "team", "ticket_id" or "example.com/site".
+var labelKey = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_./-]*[A-Za-z0-9])?$`)
+
+// validateMetadata checks labels, owner and description and names the
+// field at fault.
+func (s JobSpec) validateMetadata() (string, error) {
+	if len(s.Labels) > maxLabels {
+		return "labels", fmt.Errorf("%w: at most %d labels are allowed", ErrInvalidMetadata, maxLabels)
+	}
+	for k, v := range s.Labels {
+		if len(k) > labelKeyMaxLength || !labelKey.MatchString(k) {
+			return "labels", fmt.Errorf("%w: bad label key %q", ErrInvalidMetadata, k)
+		}
+		if len(v) > maxLabelValue {
+			return "labels." + k, fmt.Errorf("%w: label %q is longer than %d bytes", ErrInvalidMetadata, k, maxLabelValue)
+		}
+	}
+	if len(s.Owner) > maxOwner {
+		return "owner", fmt.Errorf("%w: owner is longer than %d bytes", ErrInvalidMetadata, maxOwner)
+	}
+	if len(s.Description) > maxDescription {
+		return "description", fmt.Errorf("%w: description is longer than %d bytes", ErrInvalidMetadata, maxDescription)
+	}
+	return "", nil
+}
+
+// requestedPorts returns the ports the job was asked to monitor, before
+// PortChannels are expanded.
+func (s JobSpec) requestedPorts() []string {
+	if len(s.Ports) > 0 {
+		return s.Ports
+	}
+	if s.Port == "" {
+		return nil
+	}
+	return []string{s.Port}
+}
+
+// forMember is the spec providers see for one member port of a job.
+func (s JobSpec) forMember(jobID, port string) JobSpec {
+	s.Port, s.Ports, s.JobID = port, nil, jobID
+	return s
+}
+
+func (s JobSpec) hasBudget() bool {
+	return s.MaxPackets > 0 || s.MaxBytes > 0 || s.MaxCPU > 0
+}
+
+// Notify says where to deliver a job's final results.
+type Notify struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+func (n *Notify) validate() error {
+	if n == nil {
+		return nil
+	}
+	u, err := url.Parse(n.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: webhook_url must be an absolute http(s) URL", ErrInvalidNotify)
+	}
+	return nil
 }
 
 type JobState string
 
 const (
+	JobQueued   JobState = "queued"
 	JobStarting JobState = "starting"
 	JobRunning  JobState = "running"
 	JobStopping JobState = "stopping"
@@ -28,14 +139,177 @@ const (
 	JobFailed   JobState = "failed"
 )
 
+// terminal reports whether no further transitions are expected.
+func (s JobState) terminal() bool {
+	return s == JobDone || s == JobFailed
+}
+
+// jobTransitions is the job lifecycle:
+//
+//	queued -> starting -> running -> stopping -> done|failed
+//
+// A queued job may also be cancelled (done) or expire (failed), and any
+// active job may fail outright.
+var jobTransitions = map[JobState][]JobState{
+	JobQueued:   {JobStarting, JobDone, JobFailed},
+	JobStarting: {JobRunning, JobStopping, JobFailed},
+	JobRunning:  {JobStopping, JobFailed},
+	JobStopping: {JobDone, JobFailed},
+}
+
+func (s JobState) canTransition(to JobState) bool {
+	for _, t := range jobTransitions[s] {
+		if t == to {
+			return true
+		}
+	}
+	return false
+}
+
+// JobUpdate carries the changes requested for a queued or running job;
+// zero values leave a field unchanged.
+type JobUpdate struct {
+	Duration   time.Duration // new total duration, measured from the start
+	SampleRate int
+	Filters    map[string]interface{}
+}
+
+// Why a job stopped; reported as stop_reason.
+const (
+	StopExpired        = "expired"         // ran for its full duration
+	StopRequested      = "stopped"         // DELETE /v1/monitor/jobs/{id}
+	StopBudgetExceeded = "budget_exceeded" // used up max_packets, max_bytes or max_cpu_seconds
+	StopShutdown       = "shutdown"        // the agent was shut down
+	StopPreempted      = "preempted"       // made room for a higher-priority job
+)
+
+// Steps a job goes through; used as keys of Job.StepErrors.
+const (
+	StepMirror   = "mirror"
+	StepAttach   = "attach"
+	StepCollect  = "collect"
+	StepTeardown = "teardown"
+)
+
+// ResultsProvider may also implement Finalize(), which the Supervisor calls
+// once the job window closes and before teardown to take the stop snapshot.
+type ResultsProvider interface {
+	Summary() interface{}
+}
+
 type Job struct {
 	ID        string
 	Spec      JobSpec
 	State     JobState
+	CreatedAt time.Time
+	QueuedAt  time.Time
 	StartedAt time.Time
 	ExpiresAt time.Time
+	EndedAt   time.Time
 	IfName    string
 
-	mu     sync.Mutex
-	cancel context.CancelFunc
+	// FailureReason explains a failed job; StepErrors keeps the error of
+	// each step (mirror, attach, collect, teardown) that went wrong.
+	FailureReason string
+	StepErrors    map[string]string
+	StopReason    string
+	PreemptedBy   string // job that preempted this one
+
+	// Members are the ports the job monitors once PortChannels are
+	// expanded, and the interface provisioned for each. IfName is the
+	// first member's.
+	Members []JobMember
+
+	mu         sync.Mutex
+	cancel     context.CancelFunc
+	results    ResultsProvider
+	queueTimer *time.Timer
+	deadline   *time.Timer // cancels the job at ExpiresAt
+}
+
+// record snapshots the persisted fields; callers hold Supervisor.mu.
+func (j *Job) record() JobRecord {
+	return JobRecord{
+		ID:        j.ID,
+		Spec:      j.Spec,
+		State:     j.State,
+		CreatedAt: j.CreatedAt,
+		QueuedAt:  j.QueuedAt,
+		StartedAt: j.StartedAt,
+		ExpiresAt: j.ExpiresAt,
+		EndedAt:   j.EndedAt,
+		IfName:    j.IfName,
+
+		FailureReason: j.FailureReason,
+		StepErrors:    j.StepErrors,
+		StopReason:    j.StopReason,
+		PreemptedBy:   j.PreemptedBy,
+		Members:       j.Members,
+	}
+}
+
+// JobMember is one port of a job and the mirror interface watching it.
+type JobMember struct {
+	Port   string `json:"port"`
+	IfName string `json:"interface,omitempty"`
+}
+
+// expanded reports whether the job covers other ports than its port field,
+// i.e. it was given ports or a PortChannel; only such jobs report members.
+func (j *Job) expanded() bool {
+	return len(j.Members) > 1 || len(j.Members) == 1 && j.Members[0].Port != j.Spec.Port
+}
+
+// coversPort reports whether port was requested by the job or is one of
+// its members; callers hold Supervisor.mu.
+func (j *Job) coversPort(port string) bool {
+	for _, p := range j.Spec.requestedPorts() {
+		if p == port {
+			return true
+		}
+	}
+	for _, m := range j.Members {
+		if m.Port == port {
+			return true
+		}
+	}
+	return false
+}
+
+// leasesOn counts the members of j provisioned on ifname; callers hold
+// Supervisor.mu.
+func (j *Job) leasesOn(ifname string) int {
+	n := 0
+	for _, m := range j.Members {
+		if m.IfName == ifname {
+			n++
+		}
+	}
+	return n
+}
+
+// interfaces lists the distinct interfaces provisioned for the job, in
+// member order; callers hold Supervisor.mu.
+func (j *Job) interfaces() []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, m := range j.Members {
+		if m.IfName != "" && !seen[m.IfName] {
+			seen[m.IfName] = true
+			out = append(out, m.IfName)
+		}
+	}
+	return out
+}
+
+// stepError records err for step and keeps the first failure as the reason;
+// callers hold Supervisor.mu.
+func (j *Job) stepError(step string, err error) {
+	if j.StepErrors == nil {
+		j.StepErrors = map[string]string{}
+	}
+	j.StepErrors[step] = err.Error()
+	if j.FailureReason == "" {
+		j.FailureReason = step + " failed: " + err.Error()
+	}
 }
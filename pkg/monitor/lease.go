@@ -0,0 +1,129 @@
+//go:build linux
+
+package monitor
+
+import "fmt"
+
+// ifLease tracks every job using a mirror interface. The tc attachment is
+// made by the first job and, like the mirror cleanups, only undone when the
+// last job releases the interface, so one job's teardown can never remove
+// resources another job still depends on.
+type ifLease struct {
+	refs        int
+	port        string       // source port mirrored to the interface
+	attach      attachConfig // what the data plane was attached with
+	shared      bool         // every holder allowed sharing
+	attCleanup  func() error
+	mirCleanups []func() error
+}
+
+// attachConfig is the part of a spec the tc attachment is made from.
+type attachConfig struct {
+	direction string
+	cfg       IfConfig
+}
+
+func attachConfigOf(spec JobSpec) (attachConfig, error) {
+	cfg, err := ifConfigFromSpec(spec)
+	return attachConfig{direction: dirOrBoth(spec.Direction), cfg: cfg}, err
+}
+
+// acquireLease attaches the data plane to ifname unless another job already
+// did, and takes a reference on the interface. A job may only join an
+// interface that mirrors the same port with the same direction, sample rate
+// and filters, and only when it and every holder allow sharing; anything
+// else would count the other job's traffic under its own settings.
+func (s *Supervisor) acquireLease(ifname string, spec JobSpec, mirCleanup func() error) error {
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+
+	ac, err := attachConfigOf(spec)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJob, err)
+	}
+	if l, ok := s.leases[ifname]; ok {
+		switch {
+		case l.port != spec.Port:
+			return fmt.Errorf("%w: %s already mirrors %s", ErrPortConflict, ifname, l.port)
+		case !l.shared || !spec.AllowShared:
+			return fmt.Errorf("%w: %s is in use and not shared", ErrPortConflict, ifname)
+		case l.attach != ac:
+			return fmt.Errorf("%w: %s is attached with a different direction, sample rate or filters", ErrPortConflict, ifname)
+		}
+		l.refs++
+		l.mirCleanups = append(l.mirCleanups, mirCleanup)
+		return nil
+	}
+	attCleanup, err := s.att.Attach(ifname, spec)
+	if err != nil {
+		return err
+	}
+	s.leases[ifname] = &ifLease{refs: 1, port: spec.Port, attach: ac, shared: spec.AllowShared,
+		attCleanup: attCleanup, mirCleanups: []func() error{mirCleanup}}
+	return nil
+}
+
+// releaseLease drops a reference and tears the interface down once unused.
+func (s *Supervisor) releaseLease(ifname string) (attErr, mirErr error) {
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+
+	l, ok := s.leases[ifname]
+	if !ok {
+		return nil, nil
+	}
+	l.refs--
+	if l.refs > 0 {
+		return nil, nil
+	}
+	delete(s.leases, ifname)
+
+	attErr = l.attCleanup()
+	for _, c := range l.mirCleanups {
+		if err := c(); err != nil && mirErr == nil {
+			mirErr = err
+		}
+	}
+	return attErr, mirErr
+}
+
+// portConflict returns the active or starting job that overlaps self on a
+// member port and direction, unless both jobs opted into sharing; callers
+// hold s.mu.
+func (s *Supervisor) portConflict(self *Job) error {
+	for _, j := range s.jobs {
+		if j == self {
+			continue
+		}
+		switch j.State {
+		case JobStarting, JobRunning, JobStopping:
+		default:
+			continue
+		}
+		if !directionsOverlap(j.Spec.Direction, self.Spec.Direction) {
+			continue
+		}
+		if j.Spec.AllowShared && self.Spec.AllowShared {
+			continue
+		}
+		for _, m := range self.Members {
+			if j.coversPort(m.Port) {
+				return fmt.Errorf("%w: job %s is monitoring %s (%s)", ErrPortConflict, j.ID, m.Port, dirOrBoth(j.Spec.Direction))
+			}
+		}
+	}
+	return nil
+}
+
+// dirOrBoth treats an unspecified direction as both.
+func dirOrBoth(d string) string {
+	if d == "" {
+		return "both"
+	}
+	return d
+}
+
+func directionsOverlap(a, b string) bool {
+	a, b = dirOrBoth(a), dirOrBoth(b)
+	return a == b || a == "both" || b == "both"
+}
@@ -0,0 +1,196 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+var ErrBadCursor = errors.New("invalid cursor")
+
+// JobFilter selects jobs for ListJobs. Zero fields match everything; the
+// time range applies to CreatedAt as [Since, Until). A job matches Labels
+// when it carries every one of them with the same value.
+type JobFilter struct {
+	States []JobState
+	Port   string
+	Owner  string
+	Labels map[string]string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+	Cursor string
+}
+
+func (f JobFilter) match(j *Job) bool {
+	if len(f.States) > 0 {
+		ok := false
+		for _, st := range f.States {
+			if j.State == st {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if f.Port != "" && !j.coversPort(f.Port) {
+		return false
+	}
+	if f.Owner != "" && j.Spec.Owner != f.Owner {
+		return false
+	}
+	for k, v := range f.Labels {
+		if got, ok := j.Spec.Labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && j.CreatedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !j.CreatedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Jobs are listed newest first; the cursor encodes the last job returned.
+func newerFirst(a, b *Job) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+func encodeCursor(j *Job) string {
+	raw := strconv.FormatInt(j.CreatedAt.UnixNano(), 10) + "|" + j.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(c string) (*Job, error) {
+	b, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return nil, ErrBadCursor
+	}
+	ts, id, ok := strings.Cut(string(b), "|")
+	if !ok || id == "" {
+		return nil, ErrBadCursor
+	}
+	ns, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, ErrBadCursor
+	}
+	return &Job{ID: id, CreatedAt: time.Unix(0, ns)}, nil
+}
+
+// ListJobs returns a page of jobs matching f, newest first.
+func (s *Supervisor) ListJobs(f JobFilter) (interface{}, int, error) {
+	if f.Limit <= 0 {
+		f.Limit = DefaultListLimit
+	}
+	if f.Limit > MaxListLimit {
+		f.Limit = MaxListLimit
+	}
+	var after *Job
+	if f.Cursor != "" {
+		c, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return nil, 400, err
+		}
+		after = c
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matched := make([]*Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		if !f.match(j) {
+			continue
+		}
+		if after != nil && !newerFirst(after, j) {
+			continue
+		}
+		matched = append(matched, j)
+	}
+	sort.Slice(matched, func(a, b int) bool { return newerFirst(matched[a], matched[b]) })
+
+	next := ""
+	if len(matched) > f.Limit {
+		matched = matched[:f.Limit]
+		next = encodeCursor(matched[len(matched)-1])
+	}
+	jobs := make([]map[string]interface{}, 0, len(matched))
+	for _, j := range matched {
+		jobs = append(jobs, s.jobStatus(j))
+	}
+	return map[string]interface{}{"jobs": jobs, "next_cursor": next}, 200, nil
+}
+
+// RunGC evicts finished jobs older than the retention period until ctx is
+// done. It returns immediately when retention is disabled.
+func (s *Supervisor) RunGC(ctx context.Context) {
+	if s.retention <= 0 {
+		return
+	}
+	every := s.retention / 2
+	if every > time.Minute {
+		every = time.Minute
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			if n := s.gcOnce(now); n > 0 {
+				log.Printf("gc: evicted %d finished jobs", n)
+			}
+		}
+	}
+}
+
+func (s *Supervisor) gcOnce(now time.Time) int {
+	cutoff := now.Add(-s.retention)
+
+	s.mu.Lock()
+	var evict []string
+	for id, j := range s.jobs {
+		if !j.State.terminal() {
+			continue
+		}
+		// records written before EndedAt existed fall back to CreatedAt
+		ended := j.EndedAt
+		if ended.IsZero() {
+			ended = j.CreatedAt
+		}
+		if ended.Before(cutoff) {
+			delete(s.jobs, id)
+			evict = append(evict, id)
+		}
+	}
+	s.mu.Unlock()
+
+	if s.store != nil {
+		for _, id := range evict {
+			if err := s.store.Delete(id); err != nil {
+				log.Printf("gc: delete job %s: %v", id, err)
+			}
+		}
+	}
+	return len(evict)
+}
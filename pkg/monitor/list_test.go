@@ -0,0 +1,128 @@
+//go:build linux
+
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func seedJobs(sup *Supervisor, jobs ...*Job) {
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	for _, j := range jobs {
+		sup.jobs[j.ID] = j
+	}
+}
+
+func listIDs(t *testing.T, sup *Supervisor, f JobFilter) ([]string, string) {
+	t.Helper()
+	resp, code, err := sup.ListJobs(f)
+	if err != nil || code != 200 {
+		t.Fatalf("ListJobs(%+v) err=%v code=%d", f, err, code)
+	}
+	m := resp.(map[string]interface{})
+	var ids []string
+	for _, jm := range m["jobs"].([]map[string]interface{}) {
+		ids = append(ids, jm["job_id"].(string))
+	}
+	return ids, m["next_cursor"].(string)
+}
+
+func TestSupervisor_ListJobs_FilterAndPaginate(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)
+	base := time.Date(2025, 8, 15, 12, 0, 0, 0, time.UTC)
+	seedJobs(sup,
+		&Job{ID: "a", State: JobDone, CreatedAt: base, Spec: JobSpec{Port: "Ethernet0"}},
+		&Job{ID: "b", State: JobFailed, CreatedAt: base.Add(time.Minute), Spec: JobSpec{Port: "Ethernet4"}},
+		&Job{ID: "c", State: JobRunning, CreatedAt: base.Add(2 * time.Minute), Spec: JobSpec{Port: "Ethernet0"}},
+		&Job{ID: "d", State: JobDone, CreatedAt: base.Add(3 * time.Minute), Spec: JobSpec{Port: "Ethernet0"}},
+	)
+
+	ids, next := listIDs(t, sup, JobFilter{})
+	if len(ids) != 4 || ids[0] != "d" || ids[3] != "a" || next != "" {
+		t.Fatalf("expected all jobs newest first, got %v next=%q", ids, next)
+	}
+
+	ids, _ = listIDs(t, sup, JobFilter{States: []JobState{JobDone, JobFailed}})
+	if len(ids) != 3 || ids[0] != "d" || ids[1] != "b" || ids[2] != "a" {
+		t.Fatalf("state filter: %v", ids)
+	}
+	ids, _ = listIDs(t, sup, JobFilter{Port: "Ethernet0"})
+	if len(ids) != 3 {
+		t.Fatalf("port filter: %v", ids)
+	}
+	ids, _ = listIDs(t, sup, JobFilter{Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute)})
+	if len(ids) != 2 || ids[0] != "c" || ids[1] != "b" {
+		t.Fatalf("time range filter: %v", ids)
+	}
+
+	// walk all pages of size 3 with a filter applied
+	var got []string
+	cursor := ""
+	for i := 0; i < 5; i++ {
+		ids, next := listIDs(t, sup, JobFilter{Limit: 1, Port: "Ethernet0", Cursor: cursor})
+		got = append(got, ids...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(got) != 3 || got[0] != "d" || got[1] != "c" || got[2] != "a" {
+		t.Fatalf("pagination: %v", got)
+	}
+
+	seedJobs(sup,
+		&Job{ID: "e", State: JobDone, CreatedAt: base.Add(4 * time.Minute), Spec: JobSpec{Port: "Ethernet8", Owner: "alice",
+			Labels: map[string]string{"team": "netops", "ticket": "INC-1"}}},
+		&Job{ID: "f", State: JobDone, CreatedAt: base.Add(5 * time.Minute), Spec: JobSpec{Port: "Ethernet8", Owner: "bob",
+			Labels: map[string]string{"team": "netops"}}},
+	)
+	ids, _ = listIDs(t, sup, JobFilter{Labels: map[string]string{"team": "netops"}})
+	if len(ids) != 2 || ids[0] != "f" || ids[1] != "e" {
+		t.Fatalf("label selector: %v", ids)
+	}
+	ids, _ = listIDs(t, sup, JobFilter{Labels: map[string]string{"team": "netops", "ticket": "INC-1"}})
+	if len(ids) != 1 || ids[0] != "e" {
+		t.Fatalf("all labels must match: %v", ids)
+	}
+	ids, _ = listIDs(t, sup, JobFilter{Owner: "bob"})
+	if len(ids) != 1 || ids[0] != "f" {
+		t.Fatalf("owner filter: %v", ids)
+	}
+
+	if _, code, err := sup.ListJobs(JobFilter{Cursor: "!!not-base64"}); code != 400 || !errors.Is(err, ErrBadCursor) {
+		t.Fatalf("expected 400 ErrBadCursor, got code=%d err=%v", code, err)
+	}
+}
+
+func TestSupervisor_GC_EvictsOldFinishedJobs(t *testing.T) {
+	now := time.Now()
+	st := newMemStore(JobRecord{ID: "old-done"}, JobRecord{ID: "old-failed"})
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2,
+		WithStore(st), WithRetention(time.Hour))
+	seedJobs(sup,
+		&Job{ID: "old-done", State: JobDone, EndedAt: now.Add(-2 * time.Hour)},
+		&Job{ID: "old-failed", State: JobFailed, CreatedAt: now.Add(-3 * time.Hour)}, // no EndedAt
+		&Job{ID: "recent", State: JobDone, EndedAt: now.Add(-time.Minute)},
+		&Job{ID: "old-running", State: JobRunning, CreatedAt: now.Add(-5 * time.Hour)},
+	)
+
+	if n := sup.gcOnce(now); n != 2 {
+		t.Fatalf("expected 2 evictions, got %d", n)
+	}
+	for _, id := range []string{"old-done", "old-failed"} {
+		if _, code, _ := sup.GetJob(id); code != 404 {
+			t.Fatalf("%s should be evicted", id)
+		}
+		if rec := st.get(id); rec.ID != "" {
+			t.Fatalf("%s should be removed from the store", id)
+		}
+	}
+	for _, id := range []string{"recent", "old-running"} {
+		if _, code, _ := sup.GetJob(id); code != 200 {
+			t.Fatalf("%s should be kept", id)
+		}
+	}
+}
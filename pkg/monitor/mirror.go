@@ -3,28 +3,38 @@
 package monitor
 
 import (
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"net"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 )
 
-// Mirror creates (or reuses) a traffic mirror target interface that the
-// collector will attach to. Preferred mode is ERSPAN v2 with a dedicated
-// netdev (e.g. "erspan0"). If ERSPAN env config is not present, we fall
-// back to a harmless placeholder that simply returns "erspan0".
+// Mirror creates the traffic mirror target interface that the collector
+// will attach to, one per member port of a job. Preferred mode is ERSPAN v2
+// with a dedicated netdev named after the job and port (see mirrorName).
+// If ERSPAN env config is not present, we fall back to a harmless
+// placeholder that returns the same name without creating anything.
 //
 // Environment (all optional unless noted):
 //
 //	TELEGEN_MIRROR_MODE        = "erspan" | "placeholder"   (default: erspan)
-//	TELEGEN_ERSPAN_NAME        = name of netdev             (default: erspan0)
+//	TELEGEN_ERSPAN_NAME        = netdev name prefix, at most 7 bytes (default: erspan)
 //	TELEGEN_ERSPAN_DEV         = source dev for mirroring   (default: spec.Port)
 //	TELEGEN_ERSPAN_REMOTE      = remote IPv4 address        (REQUIRED for erspan)
 //	TELEGEN_ERSPAN_LOCAL       = local  IPv4 address        (REQUIRED for erspan)
-//	TELEGEN_ERSPAN_KEY         = numeric key (ERSPAN session id) (default: 10)
+//	TELEGEN_ERSPAN_KEY         = first ERSPAN key (session id) to hand out (default: 10)
 //	TELEGEN_ERSPAN_TTL         = TTL value                  (default: 64)
 //	TELEGEN_ERSPAN_TOS         = TOS/DSCP (e.g. "inherit")  (default: inherit)
 //
+// Every netdev gets its own key, the lowest one from TELEGEN_ERSPAN_KEY up
+// that no other mirror of this agent holds. TELEGEN_ERSPAN_DEV, when set,
+// is the source of every member's mirror.
+//
 // Example (env):
 //
 //	TELEGEN_MIRROR_MODE=erspan
@@ -33,17 +43,20 @@ import (
 //	TELEGEN_ERSPAN_DEV=Ethernet0
 //	TELEGEN_ERSPAN_KEY=17
 //	TELEGEN_ERSPAN_TTL=64
-//	TELEGEN_ERSPAN_NAME=erspan0
+//	TELEGEN_ERSPAN_NAME=erspan
 //
 // NOTE: This function assumes the container has CAP_NET_ADMIN and `ip`.
 //
 //	On failure (or when not configured) it falls back to placeholder.
-type Mirror struct{}
+type Mirror struct {
+	mu   sync.Mutex
+	keys map[int]string // ERSPAN key -> netdev holding it
+}
 
 func (m *Mirror) Create(spec JobSpec) (string, func() error, error) {
 	mode := getenvDefault("TELEGEN_MIRROR_MODE", "erspan")
 	if strings.EqualFold(mode, "erspan") {
-		ifname, cleanup, err := ensureERSPAN(spec)
+		ifname, cleanup, err := m.ensureERSPAN(spec)
 		if err == nil {
 			fmt.Printf("Created ERSPAN mirror for port=%s dir=%s -> %s\n", spec.Port, spec.Direction, ifname)
 			return ifname, cleanup, nil
@@ -53,8 +66,8 @@ func (m *Mirror) Create(spec JobSpec) (string, func() error, error) {
 		fmt.Printf("ERSPAN provisioning failed: %v\n", err)
 	}
 
-	// Placeholder: no real mirroring; return a stable name to allow tc attach attempts.
-	ifname := getenvDefault("TELEGEN_ERSPAN_NAME", "erspan0")
+	// Placeholder: no real mirroring; return the member's name to allow tc attach attempts.
+	ifname := mirrorName(spec)
 	fmt.Printf("Created mirror session (placeholder) for port=%s dir=%s -> %s\n", spec.Port, spec.Direction, ifname)
 	cleanup := func() error {
 		fmt.Println("Deleted mirror session (placeholder)")
@@ -63,14 +76,89 @@ func (m *Mirror) Create(spec JobSpec) (string, func() error, error) {
 	return ifname, cleanup, nil
 }
 
+// Preflight reports ports that do not exist, and ERSPAN mode without
+// the settings Create needs, in which case a job would silently get the
+// placeholder mirror.
+func (m *Mirror) Preflight(spec JobSpec) []Problem {
+	var ps []Problem
+	field := "port"
+	if len(spec.Ports) > 0 {
+		field = "ports"
+	}
+	for _, p := range spec.requestedPorts() {
+		if _, err := net.InterfaceByName(p); err != nil {
+			ps = append(ps, newProblem(field, ProblemPortNotFound, fmt.Errorf("port %s not found: %v", p, err)))
+		}
+	}
+	if !strings.EqualFold(getenvDefault("TELEGEN_MIRROR_MODE", "erspan"), "erspan") {
+		return ps
+	}
+	if os.Getenv("TELEGEN_ERSPAN_REMOTE") == "" || os.Getenv("TELEGEN_ERSPAN_LOCAL") == "" {
+		ps = append(ps, newProblem("", ProblemMirrorConfig,
+			errors.New("TELEGEN_ERSPAN_REMOTE and TELEGEN_ERSPAN_LOCAL must be set for erspan mirrors")))
+	}
+	if _, err := exec.LookPath("ip"); err != nil {
+		ps = append(ps, missingTool("ip"))
+	}
+	return ps
+}
+
+// Teardown removes a leftover mirror netdev during startup reconciliation.
+// Only erspan links are deleted, so a physical port is never touched.
+func (m *Mirror) Teardown(ifname string) error {
+	out, err := exec.Command("ip", "-d", "link", "show", "dev", ifname).CombinedOutput()
+	if err != nil {
+		return nil // already gone
+	}
+	if linkKind(string(out)) != "erspan" {
+		return nil
+	}
+	if out, err := exec.Command("ip", "link", "del", ifname).CombinedOutput(); err != nil {
+		return fmt.Errorf("ip link del %s failed: %v: %s", ifname, err, string(out))
+	}
+	fmt.Printf("Deleted leftover mirror %s\n", ifname)
+	return nil
+}
+
+// MirrorName is the netdev Create makes for spec; see mirrorName.
+func (m *Mirror) MirrorName(spec JobSpec) string {
+	return mirrorName(spec)
+}
+
 /* ------------------ ERSPAN helpers ------------------ */
 
-func ensureERSPAN(spec JobSpec) (string, func() error, error) {
-	name := getenvDefault("TELEGEN_ERSPAN_NAME", "erspan0")
+// maxMirrorPrefix leaves room for the 8 hex digits mirrorName appends
+// within the 15 bytes of a netdev name.
+const maxMirrorPrefix = 7
+
+// mirrorName names the netdev mirroring one member port of a job:
+// TELEGEN_ERSPAN_NAME followed by a hash of the job ID and port. A spec
+// without a job ID, i.e. a mirror created outside the Supervisor, gets
+// TELEGEN_ERSPAN_NAME (default erspan0) as is.
+func mirrorName(spec JobSpec) string {
+	prefix := os.Getenv("TELEGEN_ERSPAN_NAME")
+	if spec.JobID == "" {
+		if prefix == "" {
+			return "erspan0"
+		}
+		return prefix
+	}
+	if prefix == "" {
+		prefix = "erspan"
+	}
+	if len(prefix) > maxMirrorPrefix {
+		prefix = prefix[:maxMirrorPrefix]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(spec.JobID + "/" + spec.Port))
+	return fmt.Sprintf("%s%08x", prefix, h.Sum32())
+}
+
+func (m *Mirror) ensureERSPAN(spec JobSpec) (string, func() error, error) {
+	name := mirrorName(spec)
 	dev := getenvDefault("TELEGEN_ERSPAN_DEV", spec.Port)
 	remote := os.Getenv("TELEGEN_ERSPAN_REMOTE")
 	local := os.Getenv("TELEGEN_ERSPAN_LOCAL")
-	key := getenvDefault("TELEGEN_ERSPAN_KEY", "10")
 	ttl := getenvDefault("TELEGEN_ERSPAN_TTL", "64")
 	tos := getenvDefault("TELEGEN_ERSPAN_TOS", "inherit") // "inherit" or numeric
 
@@ -80,10 +168,14 @@ func ensureERSPAN(spec JobSpec) (string, func() error, error) {
 	if dev == "" {
 		return "", nil, fmt.Errorf("missing TELEGEN_ERSPAN_DEV (or JobSpec.Port)")
 	}
-
-	// If already exists, reuse.
+	// Another job's mirror must never be adopted: its cleanup would
+	// remove it from under that job.
 	if linkExists(name) {
-		return name, func() error { return nil }, nil
+		return "", nil, fmt.Errorf("mirror %s already exists", name)
+	}
+	key, err := m.takeKey(name)
+	if err != nil {
+		return "", nil, err
 	}
 
 	// ip link add erspan device:
@@ -91,7 +183,7 @@ func ensureERSPAN(spec JobSpec) (string, func() error, error) {
 	args := []string{
 		"link", "add", "name", name, "type", "erspan",
 		"erspan_ver", "2",
-		"key", key,
+		"key", strconv.Itoa(key),
 		"remote", remote,
 		"local", local,
 		"dev", dev,
@@ -103,20 +195,71 @@ func ensureERSPAN(spec JobSpec) (string, func() error, error) {
 	}
 
 	if out, err := exec.Command("ip", args...).CombinedOutput(); err != nil {
+		m.releaseKey(key)
 		return "", nil, fmt.Errorf("ip link add %s failed: %v: %s", name, err, string(out))
 	}
 	if out, err := exec.Command("ip", "link", "set", name, "up").CombinedOutput(); err != nil {
 		_ = exec.Command("ip", "link", "del", name).Run()
+		m.releaseKey(key)
 		return "", nil, fmt.Errorf("ip link set up %s failed: %v: %s", name, err, string(out))
 	}
 
 	cleanup := func() error {
 		_ = exec.Command("ip", "link", "del", name).Run()
+		m.releaseKey(key)
 		return nil
 	}
 	return name, cleanup, nil
 }
 
+// maxERSPANKey is the largest ERSPAN session id; the field has 10 bits.
+const maxERSPANKey = 1023
+
+// takeKey reserves the lowest free ERSPAN key from TELEGEN_ERSPAN_KEY up,
+// so mirrors to the same remote can be told apart.
+func (m *Mirror) takeKey(name string) (int, error) {
+	base, err := strconv.Atoi(getenvDefault("TELEGEN_ERSPAN_KEY", "10"))
+	if err != nil || base < 0 || base > maxERSPANKey {
+		return 0, fmt.Errorf("TELEGEN_ERSPAN_KEY must be a number from 0 to %d", maxERSPANKey)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.keys == nil {
+		m.keys = map[int]string{}
+	}
+	for k := base; k <= maxERSPANKey; k++ {
+		if _, used := m.keys[k]; !used {
+			m.keys[k] = name
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("no free ERSPAN key from %d to %d", base, maxERSPANKey)
+}
+
+func (m *Mirror) releaseKey(key int) {
+	m.mu.Lock()
+	delete(m.keys, key)
+	m.mu.Unlock()
+}
+
+// linkKind returns the kind `ip -d link show` reports for a link, e.g.
+// "erspan": the first word of the line after the link/ line. Physical
+// ports have no kind and yield whatever detail follows instead.
+func linkKind(out string) string {
+	lines := strings.Split(out, "\n")
+	for i, l := range lines {
+		if f := strings.Fields(l); len(f) > 0 && strings.HasPrefix(f[0], "link/") {
+			if i+1 < len(lines) {
+				if f := strings.Fields(lines[i+1]); len(f) > 0 {
+					return f[0]
+				}
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
 func linkExists(name string) bool {
 	cmd := exec.Command("ip", "link", "show", "dev", name)
 	if err := cmd.Run(); err != nil {
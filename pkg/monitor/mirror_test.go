@@ -84,6 +84,112 @@ exit 0
 	}
 }
 
+func TestMirror_ERSPAN_PerMember(t *testing.T) {
+	script := `
+if [ "$1" = "link" ] && [ "$2" = "show" ]; then exit 1; fi
+exit 0
+`
+	restore, logPath := withFakeIP(t, script)
+	defer restore()
+	t.Setenv("TELEGEN_MIRROR_MODE", "erspan")
+	t.Setenv("TELEGEN_ERSPAN_REMOTE", "192.0.2.100")
+	t.Setenv("TELEGEN_ERSPAN_LOCAL", "192.0.2.10")
+
+	m := &Mirror{}
+	spec := JobSpec{Direction: "ingress"}
+	a, cleanA, err := m.Create(spec.forMember("j1", "Ethernet0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, cleanB, err := m.Create(spec.forMember("j1", "Ethernet4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _, err := m.Create(spec.forMember("j2", "Ethernet0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b || a == c || b == c {
+		t.Fatalf("members must get their own netdevs: %s %s %s", a, b, c)
+	}
+
+	data, _ := os.ReadFile(logPath)
+	log := string(data)
+	for _, want := range []string{
+		"name " + a + " type erspan erspan_ver 2 key 10 ",
+		"name " + b + " type erspan erspan_ver 2 key 11 ",
+		"name " + c + " type erspan erspan_ver 2 key 12 ",
+	} {
+		if !strings.Contains(log, want) {
+			t.Fatalf("expected %q in calls; got:\n%s", want, log)
+		}
+	}
+
+	// a released key is handed out again
+	_ = cleanA()
+	_ = cleanB()
+	d, _, err := m.Create(spec.forMember("j3", "Ethernet8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(logPath)
+	if !strings.Contains(string(data), "name "+d+" type erspan erspan_ver 2 key 10 ") {
+		t.Fatalf("expected key 10 to be reused; got:\n%s", data)
+	}
+}
+
+func TestMirror_ERSPAN_ExistingLinkIsNotAdopted(t *testing.T) {
+	restore, logPath := withFakeIP(t, "exit 0") // every link exists
+	defer restore()
+	t.Setenv("TELEGEN_MIRROR_MODE", "erspan")
+	t.Setenv("TELEGEN_ERSPAN_REMOTE", "192.0.2.100")
+	t.Setenv("TELEGEN_ERSPAN_LOCAL", "192.0.2.10")
+
+	m := &Mirror{}
+	if _, _, err := m.ensureERSPAN(JobSpec{Port: "Ethernet0", JobID: "j1"}); err == nil {
+		t.Fatal("expected an error for an existing mirror netdev")
+	}
+	data, _ := os.ReadFile(logPath)
+	if strings.Contains(string(data), "link add") || strings.Contains(string(data), "link del") {
+		t.Fatalf("existing link must be left alone; calls:\n%s", data)
+	}
+}
+func TestMirror_ERSPAN_JobNamesAndDev(t *testing.T) {
+	script := `
+if [ "$1" = "link" ] && [ "$2" = "show" ]; then exit 1; fi
+exit 0
+`
+	restore, logPath := withFakeIP(t, script)
+	defer restore()
+	t.Setenv("TELEGEN_MIRROR_MODE", "erspan")
+	t.Setenv("TELEGEN_ERSPAN_REMOTE", "192.0.2.100")
+	t.Setenv("TELEGEN_ERSPAN_LOCAL", "192.0.2.10")
+	t.Setenv("TELEGEN_ERSPAN_DEV", "Ethernet64")
+
+	m := &Mirror{}
+	spec := JobSpec{Direction: "ingress"}.forMember("j1", "Ethernet0")
+	ifname, _, err := m.Create(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ifname, "erspan") || len(ifname) != 14 {
+		t.Fatalf("expected erspan plus 8 hex digits, got %q", ifname)
+	}
+	data, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(data), "name "+ifname+" ") || !strings.Contains(string(data), "dev Ethernet64") {
+		t.Fatalf("expected %s mirroring Ethernet64; got:\n%s", ifname, data)
+	}
+
+	t.Setenv("TELEGEN_ERSPAN_NAME", "mirror")
+	if name := mirrorName(spec); !strings.HasPrefix(name, "mirror") || len(name) != 14 {
+		t.Fatalf("expected the mirror prefix, got %q", name)
+	}
+	t.Setenv("TELEGEN_ERSPAN_NAME", "telegen-erspan")
+	if name := mirrorName(spec); name[:7] != "telegen" || len(name) != 15 {
+		t.Fatalf("a long prefix must be cut to fit a netdev name, got %q", name)
+	}
+}
+
 func TestMirror_Placeholder_Mode(t *testing.T) {
 	// No fake ip needed; placeholder does not call ip(8)
 	t.Setenv("TELEGEN_MIRROR_MODE", "placeholder")
@@ -121,3 +227,38 @@ func TestMirror_ERSPAN_MissingEnv_FallsBackToPlaceholder(t *testing.T) {
 	}
 	_ = cleanup()
 }
+
+func TestMirror_Teardown_OnlyDeletesErspanLinks(t *testing.T) {
+	script := `
+if [ "$1" = "-d" ] && [ "$5" = "erspan0" ]; then
+  printf '7: erspan0@Ethernet0: <UP> mtu 1450\n    link/ether 02:00:00:00:00:07 brd ff:ff:ff:ff:ff:ff promiscuity 0\n    erspan remote 192.0.2.100 local 192.0.2.10 erspan_ver 2\n'; exit 0
+fi
+if [ "$1" = "-d" ] && [ "$5" = "Ethernet0" ]; then
+  printf '3: Ethernet0: <UP> mtu 9100\n    link/ether 02:00:00:00:00:03 brd ff:ff:ff:ff:ff:ff promiscuity 0\n    addrgenmode eui64 numtxqueues 1\n'; exit 0
+fi
+# named like a mirror, but a plain dummy link
+if [ "$1" = "-d" ] && [ "$5" = "erspan9" ]; then
+  printf '9: erspan9: <UP> mtu 1500\n    link/ether 02:00:00:00:00:09 brd ff:ff:ff:ff:ff:ff promiscuity 0\n    dummy addrgenmode eui64\n'; exit 0
+fi
+if [ "$1" = "-d" ]; then exit 1; fi
+exit 0
+`
+	restore, logPath := withFakeIP(t, script)
+	defer restore()
+
+	m := &Mirror{}
+	for _, name := range []string{"erspan0", "Ethernet0", "erspan9", "missing0"} {
+		if err := m.Teardown(name); err != nil {
+			t.Fatalf("Teardown(%s): %v", name, err)
+		}
+	}
+
+	data, _ := os.ReadFile(logPath)
+	log := string(data)
+	if !strings.Contains(log, "ip link del erspan0") {
+		t.Fatalf("expected erspan0 to be deleted; calls:\n%s", log)
+	}
+	if strings.Contains(log, "link del Ethernet0") || strings.Contains(log, "link del erspan9") || strings.Contains(log, "link del missing0") {
+		t.Fatalf("non-erspan links must not be deleted; calls:\n%s", log)
+	}
+}
@@ -0,0 +1,116 @@
+//go:build linux
+
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/platformbuilds/telegen-sonic/pkg/api"
+)
+
+// maxWebhookBackoff caps the delay between two delivery attempts.
+const maxWebhookBackoff = time.Minute
+
+// Webhook POSTs a finished job's api.JobResults to its notify.webhook_url.
+// The job ID and final status travel in the X-Telegen-Job-ID and
+// X-Telegen-Job-Status headers; with a Secret the body is signed as
+// X-Telegen-Signature: sha256=<hex HMAC-SHA256 of the body>. Network
+// errors, 429 and 5xx responses are retried with exponential backoff;
+// other responses are final, and so is the last attempt once the context
+// is done.
+type Webhook struct {
+	Results     func(id string) (api.JobResults, int, error) // usually CoreAdapter.GetResults
+	Secret      []byte
+	Client      *http.Client  // nil uses http.DefaultClient
+	MaxAttempts int           // at least 1
+	Backoff     time.Duration // delay before the first retry, doubled after each one
+}
+
+// errPermanent marks a failure that retrying will not fix.
+type errPermanent struct{ error }
+
+func (w *Webhook) JobFinished(ctx context.Context, jobID string, state JobState, n Notify) {
+	res, _, err := w.Results(jobID)
+	if err != nil {
+		log.Printf("job %s: webhook not sent: %v", jobID, err)
+		return
+	}
+	body, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("job %s: webhook not sent: %v", jobID, err)
+		return
+	}
+	delivery := uuid.NewString()
+	delay := w.Backoff
+	for attempt := 1; ; attempt++ {
+		err := w.post(n.WebhookURL, jobID, state, delivery, body)
+		if err == nil {
+			return
+		}
+		var perm errPermanent
+		if errors.As(err, &perm) || attempt >= w.MaxAttempts {
+			log.Printf("job %s: webhook %s failed after %d attempts: %v", jobID, n.WebhookURL, attempt, err)
+			return
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			log.Printf("job %s: webhook %s abandoned after %d attempts: %v", jobID, n.WebhookURL, attempt, err)
+			return
+		}
+		delay = min(2*delay, maxWebhookBackoff)
+	}
+}
+
+func (w *Webhook) post(url, jobID string, state JobState, delivery string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errPermanent{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Telegen-Job-ID", jobID)
+	req.Header.Set("X-Telegen-Job-Status", string(state))
+	// the same for every attempt so receivers can drop duplicates
+	req.Header.Set("X-Telegen-Delivery", delivery)
+	if len(w.Secret) > 0 {
+		req.Header.Set("X-Telegen-Signature", "sha256="+sign(w.Secret, body))
+	}
+	c := w.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook answered %d", resp.StatusCode)
+	default:
+		return errPermanent{fmt.Errorf("webhook answered %d", resp.StatusCode)}
+	}
+}
+
+func sign(secret, body []byte) string {
+	m := hmac.New(sha256.New, secret)
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
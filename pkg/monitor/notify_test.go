@@ -0,0 +1,185 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/platformbuilds/telegen-sonic/pkg/api"
+)
+
+// hookServer answers with codes in turn (200 once they run out) and keeps
+// every request it saw.
+type hookServer struct {
+	*httptest.Server
+	codes []int
+
+	mu     sync.Mutex
+	bodies [][]byte
+	hdrs   []http.Header
+}
+
+func newHookServer(t *testing.T, codes ...int) *hookServer {
+	h := &hookServer{codes: codes}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		h.mu.Lock()
+		n := len(h.bodies)
+		h.bodies = append(h.bodies, b)
+		h.hdrs = append(h.hdrs, r.Header.Clone())
+		h.mu.Unlock()
+		code := http.StatusOK
+		if n < len(h.codes) {
+			code = h.codes[n]
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(h.Close)
+	return h
+}
+
+func (h *hookServer) calls() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.bodies)
+}
+
+func testWebhook(attempts int) *Webhook {
+	return &Webhook{
+		Results: func(id string) (api.JobResults, int, error) {
+			return api.JobResults{WindowSec: 60, Packets: 42, StopReason: StopExpired}, 200, nil
+		},
+		Secret:      []byte("s3cret"),
+		MaxAttempts: attempts,
+		Backoff:     time.Millisecond,
+	}
+}
+
+func TestWebhook_SignedDelivery(t *testing.T) {
+	srv := newHookServer(t)
+	testWebhook(3).JobFinished(context.Background(), "job-1", JobDone, Notify{WebhookURL: srv.URL})
+
+	if srv.calls() != 1 {
+		t.Fatalf("expected one delivery, got %d", srv.calls())
+	}
+	body, h := srv.bodies[0], srv.hdrs[0]
+	var res api.JobResults
+	if err := json.Unmarshal(body, &res); err != nil || res.Packets != 42 || res.StopReason != StopExpired {
+		t.Fatalf("body = %s (err %v)", body, err)
+	}
+	if h.Get("X-Telegen-Job-ID") != "job-1" || h.Get("X-Telegen-Job-Status") != "done" {
+		t.Fatalf("job headers = %v", h)
+	}
+	want := "sha256=" + sign([]byte("s3cret"), body)
+	if !hmac.Equal([]byte(h.Get("X-Telegen-Signature")), []byte(want)) {
+		t.Fatalf("signature = %q, want %q", h.Get("X-Telegen-Signature"), want)
+	}
+}
+
+func TestWebhook_Retries(t *testing.T) {
+	srv := newHookServer(t, 500, 429)
+	testWebhook(5).JobFinished(context.Background(), "job-1", JobFailed, Notify{WebhookURL: srv.URL})
+	if srv.calls() != 3 {
+		t.Fatalf("expected 2 retries then success, got %d calls", srv.calls())
+	}
+	d := srv.hdrs[0].Get("X-Telegen-Delivery")
+	if d == "" || srv.hdrs[2].Get("X-Telegen-Delivery") != d {
+		t.Fatalf("delivery id should be stable across attempts")
+	}
+
+	srv = newHookServer(t, 503, 503, 503, 503)
+	testWebhook(3).JobFinished(context.Background(), "job-1", JobDone, Notify{WebhookURL: srv.URL})
+	if srv.calls() != 3 {
+		t.Fatalf("expected to give up after 3 attempts, got %d calls", srv.calls())
+	}
+
+	// client errors are not retried
+	srv = newHookServer(t, 404)
+	testWebhook(5).JobFinished(context.Background(), "job-1", JobDone, Notify{WebhookURL: srv.URL})
+	if srv.calls() != 1 {
+		t.Fatalf("404 should not be retried, got %d calls", srv.calls())
+	}
+}
+
+func TestWebhook_UnsignedWithoutSecret(t *testing.T) {
+	srv := newHookServer(t)
+	w := testWebhook(1)
+	w.Secret = nil
+	w.JobFinished(context.Background(), "job-1", JobDone, Notify{WebhookURL: srv.URL})
+	if srv.calls() != 1 || srv.hdrs[0].Get("X-Telegen-Signature") != "" {
+		t.Fatalf("expected one unsigned delivery")
+	}
+}
+
+func TestWebhook_ShutdownAbandonsRetries(t *testing.T) {
+	srv := newHookServer(t, 503, 503, 503)
+	w := testWebhook(5)
+	w.Backoff = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.JobFinished(ctx, "job-1", JobDone, Notify{WebhookURL: srv.URL})
+		close(done)
+	}()
+	for srv.calls() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("a cancelled context should end the backoff")
+	}
+	if srv.calls() != 1 {
+		t.Fatalf("expected no retry after cancel, got %d calls", srv.calls())
+	}
+}
+
+type recordingNotifier struct {
+	got chan string
+}
+
+func (r recordingNotifier) JobFinished(ctx context.Context, jobID string, state JobState, n Notify) {
+	r.got <- jobID + " " + string(state) + " " + n.WebhookURL
+}
+
+func TestSupervisor_NotifiesFinishedJobs(t *testing.T) {
+	rn := recordingNotifier{got: make(chan string, 2)}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2, WithNotifier(rn))
+
+	if _, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth0", Duration: time.Minute, Notify: &Notify{WebhookURL: "ftp://x"}}}); code != 400 || !errors.Is(err, ErrInvalidNotify) {
+		t.Fatalf("expected 400 ErrInvalidNotify, got code=%d err=%v", code, err)
+	}
+
+	silent := startJob(t, sup, JobSpec{Port: "Eth0", Duration: time.Minute})
+	id := startJob(t, sup, JobSpec{Port: "Eth4", Duration: time.Minute, Notify: &Notify{WebhookURL: "http://hooks.example/x"}})
+	_, _, _ = sup.StopJob(silent)
+	_, _, _ = sup.StopJob(id)
+	waitState(t, sup, silent, JobDone)
+	waitState(t, sup, id, JobDone)
+
+	select {
+	case got := <-rn.got:
+		if got != id+" done http://hooks.example/x" {
+			t.Fatalf("notifier got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("notifier not called")
+	}
+	select {
+	case got := <-rn.got:
+		t.Fatalf("job without notify block was reported: %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+var _ Notifier = (*Webhook)(nil)
@@ -5,37 +5,126 @@ package monitor
 import (
 	"context"
 	"crypto/tls"
+	"sync"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"google.golang.org/grpc/credentials"
 )
 
+// OTelOption configures optional SetupOTelMetrics features.
+type OTelOption func(*otelOptions)
+
+type otelOptions struct {
+	tracker     *ExportTracker
+	promReg     prometheus.Registerer
+	withoutOTLP bool
+}
+
+// WithPrometheus also exposes every metric through reg for Prometheus to
+// scrape, alongside or instead of the OTLP push.
+func WithPrometheus(reg prometheus.Registerer) OTelOption {
+	return func(o *otelOptions) { o.promReg = reg }
+}
+
+// WithoutOTLP leaves out the OTLP exporter, for agents without a reachable
+// collector; endpoint and the export interval are then unused.
+func WithoutOTLP() OTelOption {
+	return func(o *otelOptions) { o.withoutOTLP = true }
+}
+
+// WithExportTracker records the outcome of every OTLP export in t.
+func WithExportTracker(t *ExportTracker) OTelOption {
+	return func(o *otelOptions) { o.tracker = t }
+}
+
+// ExportTracker remembers how the OTLP exporter's exports went.
+type ExportTracker struct {
+	mu      sync.Mutex
+	lastOK  time.Time
+	lastErr error
+	errAt   time.Time
+}
+
+// Status returns the time of the last successful export and the error of
+// the last export, if it failed, with its time.
+func (t *ExportTracker) Status() (lastOK time.Time, lastErr error, errAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastOK, t.lastErr, t.errAt
+}
+
+func (t *ExportTracker) record(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		t.lastErr, t.errAt = err, time.Now()
+		return
+	}
+	t.lastOK, t.lastErr = time.Now(), nil
+}
+
+// trackedExporter reports each export to an ExportTracker.
+type trackedExporter struct {
+	sdkmetric.Exporter
+	t *ExportTracker
+}
+
+func (e trackedExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := e.Exporter.Export(ctx, rm)
+	e.t.record(err)
+	return err
+}
+
 // SetupOTelMetrics configures an OTLP gRPC exporter + periodic reader and returns a MeterProvider and Meter.
-func SetupOTelMetrics(ctx context.Context, serviceName, endpoint string, insecure bool, interval time.Duration) (*sdkmetric.MeterProvider, metric.Meter, error) {
+func SetupOTelMetrics(ctx context.Context, serviceName, endpoint string, insecure bool, interval time.Duration, options ...OTelOption) (*sdkmetric.MeterProvider, metric.Meter, error) {
 	if interval <= 0 {
 		interval = 5 * time.Second
 	}
-
-	opts := []otlpmetricgrpc.Option{
-		otlpmetricgrpc.WithEndpoint(endpoint),
+	var o otelOptions
+	for _, opt := range options {
+		opt(&o)
 	}
 
-	if insecure {
-		opts = append(opts, otlpmetricgrpc.WithInsecure())
-	} else {
-		creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
-		opts = append(opts, otlpmetricgrpc.WithTLSCredentials(creds))
-	}
+	var readers []sdkmetric.Reader
+	if !o.withoutOTLP {
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(endpoint),
+		}
 
-	exp, err := otlpmetricgrpc.New(ctx, opts...)
-	if err != nil {
-		return nil, nil, err
+		if insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		} else {
+			creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+			opts = append(opts, otlpmetricgrpc.WithTLSCredentials(creds))
+		}
+
+		var exp sdkmetric.Exporter
+		exp, err := otlpmetricgrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		if o.tracker != nil {
+			exp = trackedExporter{Exporter: exp, t: o.tracker}
+		}
+		readers = append(readers, sdkmetric.NewPeriodicReader(exp, sdkmetric.WithInterval(interval)))
+	}
+	if o.promReg != nil {
+		// Unit suffixes would turn the dimensionless counters into
+		// bpf_packets_ratio_total; names stay as the instruments have them.
+		reader, err := otelprom.New(otelprom.WithRegisterer(o.promReg), otelprom.WithoutUnits())
+		if err != nil {
+			return nil, nil, err
+		}
+		readers = append(readers, reader)
 	}
 
 	// No semconv import; set attributes directly.
@@ -52,8 +141,6 @@ func SetupOTelMetrics(ctx context.Context, serviceName, endpoint string, insecur
 		return nil, nil, err
 	}
 
-	reader := sdkmetric.NewPeriodicReader(exp, sdkmetric.WithInterval(interval))
-
 	// Example view: explicit buckets for bpf.bytes
 	bytesHistView := sdkmetric.NewView(
 		sdkmetric.Instrument{
@@ -67,11 +154,14 @@ func SetupOTelMetrics(ctx context.Context, serviceName, endpoint string, insecur
 		},
 	)
 
-	mp := sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(reader),
+	mpOpts := []sdkmetric.Option{
 		sdkmetric.WithResource(res),
 		sdkmetric.WithView(bytesHistView),
-	)
+	}
+	for _, r := range readers {
+		mpOpts = append(mpOpts, sdkmetric.WithReader(r))
+	}
+	mp := sdkmetric.NewMeterProvider(mpOpts...)
 	otel.SetMeterProvider(mp)
 
 	meter := mp.Meter("telegen-sonic/monitor")
@@ -0,0 +1,62 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cilium/ebpf"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+func TestSetupOTelMetrics_Prometheus(t *testing.T) {
+	ctx := context.Background()
+	reg := prometheus.NewRegistry()
+	mp, meter, err := SetupOTelMetrics(ctx, "telegen-test", "", true, time.Second, WithoutOTLP(), WithPrometheus(reg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = mp.Shutdown(ctx) }()
+
+	c, err := NewMetricsCollector(meter, &ebpf.Map{}, nil, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.packetsCtr.Add(ctx, 3, otelmetric.WithAttributes(attribute.String("proto", "ipv4")))
+	c.bytesHist.Record(ctx, 1400, otelmetric.WithAttributes(attribute.String("proto", "ipv4")))
+	c.running = true
+	c.scraped(nil)
+	a := &AgentAdapter{Version: "1.2.3", Commit: "abc", Collector: c,
+		S: NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)}
+	if err := a.RegisterMetrics(meter); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{
+		`bpf_packets_total{`,
+		`bpf_bytes_bucket{`,
+		`telegen_build_info{commit="abc"`,
+		`telegen_jobs_active{`,
+		`telegen_collector_last_scrape{`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+}
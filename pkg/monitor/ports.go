@@ -0,0 +1,132 @@
+//go:build linux
+
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sysClassNet is where PortChannel members are looked up; tests point it
+// at a fake tree.
+var sysClassNet = "/sys/class/net"
+
+// expandPort returns the member ports of a PortChannel, found through the
+// lower_<member> links the kernel keeps for a team or bond device, and any
+// other port as is.
+func expandPort(port string) ([]string, error) {
+	if !strings.HasPrefix(port, "PortChannel") {
+		return []string{port}, nil
+	}
+	dir := filepath.Join(sysClassNet, port)
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("%w: %w: %s", ErrInvalidJob, ErrPortNotFound, port)
+	}
+	links, err := filepath.Glob(filepath.Join(dir, "lower_*"))
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, fmt.Errorf("%w: %s has no member ports", ErrInvalidJob, port)
+	}
+	members := make([]string, 0, len(links))
+	for _, l := range links {
+		members = append(members, strings.TrimPrefix(filepath.Base(l), "lower_"))
+	}
+	return members, nil
+}
+
+// resolvePorts expands the ports spec asks for into the job's members,
+// each port once.
+func (s *Supervisor) resolvePorts(spec JobSpec) ([]JobMember, error) {
+	var members []JobMember
+	seen := map[string]bool{}
+	for _, p := range spec.requestedPorts() {
+		ports, err := s.expand(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range ports {
+			if !seen[m] {
+				seen[m] = true
+				members = append(members, JobMember{Port: m})
+			}
+		}
+	}
+	if len(members) > maxJobPorts {
+		return nil, fmt.Errorf("%w: the job would cover %d ports, at most %d are allowed", ErrInvalidJob, len(members), maxJobPorts)
+	}
+	return members, nil
+}
+
+// provision creates the mirror and tc attachment for every member of j.
+// If one of them fails, the failed step is recorded against j and the
+// members provisioned so far are torn down again.
+func (s *Supervisor) provision(j *Job, spec JobSpec, members []JobMember) ([]JobMember, string, error) {
+	done := make([]JobMember, 0, len(members))
+	for _, m := range members {
+		mspec := spec.forMember(j.ID, m.Port)
+		step := StepMirror
+		ifname, mirCleanup, err := s.mir.Create(mspec)
+		if err == nil {
+			step = StepAttach
+			err = s.acquireLease(ifname, mspec, mirCleanup)
+		}
+		if err == nil {
+			done = append(done, JobMember{Port: m.Port, IfName: ifname})
+			continue
+		}
+		if len(members) > 1 {
+			err = fmt.Errorf("%s: %w", m.Port, err)
+		}
+		s.mu.Lock()
+		j.stepError(step, err)
+		s.mu.Unlock()
+		if step == StepAttach {
+			if cerr := mirCleanup(); cerr != nil {
+				s.mu.Lock()
+				j.stepError(StepTeardown, cerr)
+				s.mu.Unlock()
+			}
+		}
+		s.unprovision(j, done)
+		return nil, step, err
+	}
+	return done, "", nil
+}
+
+// unprovision releases the interfaces of members and records teardown
+// errors against j.
+func (s *Supervisor) unprovision(j *Job, members []JobMember) {
+	for _, m := range members {
+		attErr, mirErr := s.releaseLease(m.IfName)
+		s.mu.Lock()
+		if attErr != nil {
+			j.stepError(StepTeardown, fmt.Errorf("detach: %w", attErr))
+		}
+		if mirErr != nil {
+			j.stepError(StepTeardown, fmt.Errorf("mirror: %w", mirErr))
+		}
+		s.mu.Unlock()
+	}
+}
+
+// MemberResult is one member's share of a multi-port job's results.
+// Members whose mirrors share an interface report that interface's totals.
+type MemberResult struct {
+	Port    string
+	IfName  string
+	Packets uint64
+	Bytes   uint64
+}
+
+func memberResults(members []JobMember, perIf map[string]ProtoStats) []MemberResult {
+	out := make([]MemberResult, 0, len(members))
+	for _, m := range members {
+		st := perIf[m.IfName]
+		out = append(out, MemberResult{Port: m.Port, IfName: m.IfName, Packets: st.Packets, Bytes: st.Bytes})
+	}
+	return out
+}
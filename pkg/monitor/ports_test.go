@@ -0,0 +1,183 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExpandPort(t *testing.T) {
+	old := sysClassNet
+	sysClassNet = t.TempDir()
+	defer func() { sysClassNet = old }()
+	for _, p := range []string{"PortChannel0001/lower_Ethernet4", "PortChannel0001/lower_Ethernet0", "PortChannel0002/bonding"} {
+		if err := os.MkdirAll(filepath.Join(sysClassNet, p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := expandPort("PortChannel0001")
+	if err != nil || strings.Join(got, ",") != "Ethernet0,Ethernet4" {
+		t.Fatalf("members = %v, err = %v", got, err)
+	}
+	if got, err := expandPort("Ethernet8"); err != nil || len(got) != 1 || got[0] != "Ethernet8" {
+		t.Fatalf("a plain port should stay as is, got %v (%v)", got, err)
+	}
+	for _, p := range []string{"PortChannel0002", "PortChannel0003"} {
+		if _, err := expandPort(p); !errors.Is(err, ErrInvalidJob) {
+			t.Fatalf("%s: expected ErrInvalidJob, got %v", p, err)
+		}
+	}
+	if _, err := expandPort("PortChannel0003"); !errors.Is(err, ErrPortNotFound) {
+		t.Fatalf("a missing PortChannel should be ErrPortNotFound, got %v", err)
+	}
+	if _, err := expandPort("PortChannel0002"); errors.Is(err, ErrPortNotFound) {
+		t.Fatalf("a PortChannel without members exists, got %v", err)
+	}
+}
+
+// portMirror gives every port its own mirror interface.
+type portMirror struct {
+	failPort string
+}
+
+func (m *portMirror) Create(spec JobSpec) (string, func() error, error) {
+	if spec.Port == m.failPort {
+		return "", nil, errors.New("no mirror session left")
+	}
+	if spec.JobID == "" || len(spec.Ports) > 0 {
+		return "", nil, errors.New("mirrors are made for one member of a job")
+	}
+	return "mirror-" + spec.Port, func() error { return nil }, nil
+}
+
+// ifCollector records the interfaces it was asked to count on.
+type ifCollector struct {
+	mu      sync.Mutex
+	ifnames []string
+	results ResultsProvider
+}
+
+func (c *ifCollector) Run(ctx context.Context, jobID string, ifnames []string, spec JobSpec) (ResultsProvider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ifnames = ifnames
+	return c.results, nil
+}
+
+func lagSupervisor(mir MirrorProvider, att AttachProvider, col Collector) *Supervisor {
+	sup := NewSupervisor(mir, att, col, 2)
+	sup.expand = func(port string) ([]string, error) {
+		if port == "PortChannel1" {
+			return []string{"Eth4", "Eth8"}, nil
+		}
+		return []string{port}, nil
+	}
+	return sup
+}
+
+func TestSupervisor_MultiPortJob(t *testing.T) {
+	att := &fakeAttach{}
+	col := &ifCollector{results: &summaryResults{sum: JobSummary{
+		Packets: 30, Bytes: 3000,
+		Interfaces: map[string]ProtoStats{
+			"mirror-Eth0": {Packets: 5, Bytes: 500},
+			"mirror-Eth4": {Packets: 10, Bytes: 1000},
+			"mirror-Eth8": {Packets: 15, Bytes: 1500},
+		},
+	}}}
+	sup := lagSupervisor(&portMirror{}, att, col)
+
+	// Eth4 comes in twice, once through the PortChannel
+	id := startJob(t, sup, JobSpec{Ports: []string{"Eth0", "PortChannel1", "Eth4"}, Duration: time.Minute})
+	waitState(t, sup, id, JobRunning)
+
+	members := jobField(t, sup, id, "members").([]JobMember)
+	want := []JobMember{{"Eth0", "mirror-Eth0"}, {"Eth4", "mirror-Eth4"}, {"Eth8", "mirror-Eth8"}}
+	if len(members) != len(want) {
+		t.Fatalf("members = %+v", members)
+	}
+	for i := range want {
+		if members[i] != want[i] {
+			t.Fatalf("members = %+v, want %+v", members, want)
+		}
+	}
+	if n := atomic.LoadInt32(&att.calls); n != 3 {
+		t.Fatalf("expected one attach per member, got %d", n)
+	}
+	col.mu.Lock()
+	ifnames := strings.Join(col.ifnames, ",")
+	col.mu.Unlock()
+	if ifnames != "mirror-Eth0,mirror-Eth4,mirror-Eth8" {
+		t.Fatalf("collector counted on %s", ifnames)
+	}
+
+	res, _, _ := sup.GetResults(id)
+	m := res.(map[string]interface{})
+	if m["packets_total"] != uint64(30) {
+		t.Fatalf("aggregate packets = %v", m["packets_total"])
+	}
+	per := m["members"].([]MemberResult)
+	if len(per) != 3 || per[1].Port != "Eth4" || per[1].Packets != 10 || per[2].Bytes != 1500 {
+		t.Fatalf("per-member results = %+v", per)
+	}
+
+	// a member port is busy even when asked for by name
+	if _, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth8", Duration: time.Minute}}); code != 409 || !errors.Is(err, ErrPortConflict) {
+		t.Fatalf("expected 409 on a member port, got code=%d err=%v", code, err)
+	}
+	if list, _, _ := sup.ListJobs(JobFilter{Port: "Eth8"}); len(list.(map[string]interface{})["jobs"].([]map[string]interface{})) != 1 {
+		t.Fatalf("listing by member port should find the job")
+	}
+
+	_, _, _ = sup.StopJob(id)
+	waitState(t, sup, id, JobDone)
+	if n := atomic.LoadInt32(&att.cleanups); n != 3 {
+		t.Fatalf("expected every member torn down, got %d", n)
+	}
+}
+
+func TestSupervisor_MultiPortJob_RollsBackOnFailure(t *testing.T) {
+	att := &fakeAttach{}
+	sup := lagSupervisor(&portMirror{failPort: "Eth8"}, att, &fakeCollector{})
+
+	_, code, err := sup.TryStartJob(startReq{JobSpec{Port: "PortChannel1", Duration: time.Minute}})
+	if code != 500 || err == nil || !strings.Contains(err.Error(), "Eth8") {
+		t.Fatalf("expected the failing member to be named, got code=%d err=%v", code, err)
+	}
+	if n := atomic.LoadInt32(&att.cleanups); n != 1 {
+		t.Fatalf("the member provisioned before the failure should be torn down, got %d cleanups", n)
+	}
+	sup.leaseMu.Lock()
+	leases := len(sup.leases)
+	sup.leaseMu.Unlock()
+	if leases != 0 {
+		t.Fatalf("%d leases left behind", leases)
+	}
+	if _, code, _ := sup.TryStartJob(startReq{JobSpec{Port: "Eth4", Duration: time.Minute}}); code != 201 {
+		t.Fatalf("the slot and port should be free again, got %d", code)
+	}
+}
+
+func TestJobSpec_PortsValidation(t *testing.T) {
+	for _, spec := range []JobSpec{
+		{},
+		{Port: "Eth0", Ports: []string{"Eth4"}},
+		{Ports: []string{"Eth0", "Eth0"}},
+		{Ports: []string{""}},
+	} {
+		spec.Duration = time.Minute
+		ps := spec.problems(0)
+		if len(ps) != 1 || !errors.Is(ps[0].err, ErrInvalidJob) {
+			t.Fatalf("%+v: problems = %+v", spec, ps)
+		}
+	}
+}
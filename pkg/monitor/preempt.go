@@ -0,0 +1,38 @@
+//go:build linux
+
+package monitor
+
+import "log"
+
+// preemptible picks the job to stop so a job of priority p can run: the
+// lowest-priority active job below p, the most recently started one on a
+// tie. It returns nil when there is none; callers hold s.mu.
+func (s *Supervisor) preemptible(p int) *Job {
+	var victim *Job
+	for _, j := range s.jobs {
+		if j.State != JobStarting && j.State != JobRunning {
+			continue
+		}
+		if j.Spec.Priority >= p {
+			continue
+		}
+		if victim == nil || j.Spec.Priority < victim.Spec.Priority ||
+			j.Spec.Priority == victim.Spec.Priority && j.StartedAt.After(victim.StartedAt) {
+			victim = j
+		}
+	}
+	return victim
+}
+
+// preempt stops victim on behalf of j and queues j ahead of lower-priority
+// jobs, so the slot victim releases after teardown goes to j (or to an
+// even higher-priority job that is already waiting). It returns j's queue
+// position and the victim's cancel func, which the caller invokes after
+// releasing s.mu.
+func (s *Supervisor) preempt(j, victim *Job) (int, func()) {
+	victim.StopReason = StopPreempted
+	victim.PreemptedBy = j.ID
+	_ = s.transition(victim, JobStopping)
+	log.Printf("job %s: preempted by %s (priority %d > %d)", victim.ID, j.ID, j.Spec.Priority, victim.Spec.Priority)
+	return s.insertQueued(j), victim.cancel
+}
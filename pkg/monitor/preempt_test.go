@@ -0,0 +1,101 @@
+//go:build linux
+
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSupervisor_Preempt_LowestPriority(t *testing.T) {
+	att := &fakeAttach{}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, att, &fakeCollector{}, 2)
+
+	baseline := startJob(t, sup, JobSpec{Port: "Eth0", Duration: time.Minute})
+	mid := startJob(t, sup, JobSpec{Port: "Eth4", Duration: time.Minute, Priority: 5})
+	waitState(t, sup, baseline, JobRunning)
+	waitState(t, sup, mid, JobRunning)
+
+	resp, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth8", Duration: time.Minute, Priority: 50}})
+	if err != nil || code != 202 {
+		t.Fatalf("expected 202 while the victim tears down, got code=%d err=%v", code, err)
+	}
+	m := resp.(map[string]interface{})
+	urgent := m["job_id"].(string)
+	if m["preempted_job_id"] != baseline {
+		t.Fatalf("should preempt the priority-0 job, got %v", m["preempted_job_id"])
+	}
+
+	waitState(t, sup, baseline, JobDone)
+	waitState(t, sup, urgent, JobRunning)
+	if r := jobField(t, sup, baseline, "stop_reason"); r != StopPreempted {
+		t.Fatalf("victim stop_reason = %v", r)
+	}
+	if by := jobField(t, sup, baseline, "preempted_by"); by != urgent {
+		t.Fatalf("victim preempted_by = %v, want %s", by, urgent)
+	}
+	if st := jobField(t, sup, mid, "status"); st != string(JobRunning) {
+		t.Fatalf("higher-priority job should keep running, got %v", st)
+	}
+	if p := jobField(t, sup, urgent, "priority"); p != 50 {
+		t.Fatalf("priority = %v", p)
+	}
+
+	// nothing left below priority 5, so an equal-priority request is refused
+	if _, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth12", Duration: time.Minute, Priority: 5}}); code != 429 || !errors.Is(err, ErrConcurrencyLimit) {
+		t.Fatalf("expected 429 without a lower-priority victim, got code=%d err=%v", code, err)
+	}
+	_, _, _ = sup.StopJob(mid)
+	_, _, _ = sup.StopJob(urgent)
+}
+
+func TestSupervisor_Preempt_TieGoesToNewest(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)
+	older := startJob(t, sup, JobSpec{Port: "Eth0", Duration: time.Minute, Priority: 1})
+	time.Sleep(5 * time.Millisecond)
+	newer := startJob(t, sup, JobSpec{Port: "Eth4", Duration: time.Minute, Priority: 1})
+
+	resp, _, _ := sup.TryStartJob(startReq{JobSpec{Port: "Eth8", Duration: time.Minute, Priority: 2}})
+	if got := resp.(map[string]interface{})["preempted_job_id"]; got != newer {
+		t.Fatalf("preempted %v, want the most recently started job %s", got, newer)
+	}
+	_, _, _ = sup.StopJob(older)
+}
+
+func TestSupervisor_Queue_PriorityOrder(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1, WithQueue(3, time.Minute))
+	first := startJob(t, sup, JobSpec{Port: "Eth0", Duration: time.Minute, Priority: 10})
+
+	queue := func(p int) string {
+		resp, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth4", Duration: time.Minute, Priority: p, AllowShared: true}})
+		if err != nil || code != 202 {
+			t.Fatalf("priority %d: expected queued, got code=%d err=%v", p, code, err)
+		}
+		return resp.(map[string]interface{})["job_id"].(string)
+	}
+	low := queue(0)
+	high := queue(5)
+	low2 := queue(0)
+
+	for id, want := range map[string]int{high: 1, low: 2, low2: 3} {
+		if pos := jobField(t, sup, id, "queue_position"); pos != want {
+			t.Fatalf("job %s at position %v, want %d", id, pos, want)
+		}
+	}
+	_, _, _ = sup.StopJob(first)
+	waitState(t, sup, high, JobRunning)
+	_, _, _ = sup.StopJob(high)
+	waitState(t, sup, low, JobRunning)
+	_, _, _ = sup.StopJob(low)
+	_, _, _ = sup.StopJob(low2)
+}
+
+func TestSupervisor_InvalidPriority(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1)
+	for _, p := range []int{-1, MaxPriority + 1} {
+		if _, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth0", Duration: time.Minute, Priority: p}}); code != 400 || !errors.Is(err, ErrInvalidPriority) {
+			t.Fatalf("priority %d: expected 400 ErrInvalidPriority, got code=%d err=%v", p, code, err)
+		}
+	}
+}
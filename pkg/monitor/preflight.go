@@ -0,0 +1,85 @@
+//go:build linux
+
+package monitor
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// What submit would do with a job right now; reported as admission.
+const (
+	AdmitStart   = "start"
+	AdmitQueue   = "queue"
+	AdmitPreempt = "preempt"
+	AdmitReject  = "reject"
+)
+
+// ValidateJob runs the checks TryStartJob would for req without creating a
+// mirror, attaching tc or reserving a slot, and lists every problem found
+// rather than only the first. Providers that implement Preflight add checks
+// of their own, e.g. that the port exists or the BPF object is in place.
+func (s *Supervisor) ValidateJob(req interface{}) (interface{}, int, error) {
+	spec := req.(interface{ ToSpec() JobSpec }).ToSpec()
+	problems := s.problems(spec)
+	// providers may look at the system; keep that outside the lock
+	for _, p := range []interface{}{s.mir, s.att} {
+		if pf, ok := p.(preflighter); ok {
+			problems = append(problems, pf.Preflight(spec)...)
+		}
+	}
+
+	members, err := s.resolvePorts(spec)
+	if err != nil {
+		field := "port"
+		if len(spec.Ports) > 0 {
+			field = "ports"
+		}
+		code := ProblemInvalid
+		if errors.Is(err, ErrPortNotFound) {
+			code = ProblemPortNotFound
+		}
+		problems = append(problems, newProblem(field, code, err))
+	}
+
+	s.mu.RLock()
+	admission, victim, ps := s.admission(spec, members)
+	s.mu.RUnlock()
+	problems = append(problems, ps...)
+
+	resp := map[string]interface{}{
+		"valid":     len(problems) == 0,
+		"admission": admission,
+		"problems":  problems,
+	}
+	if victim != "" {
+		resp["preempt_job_id"] = victim
+	}
+	return resp, 200, nil
+}
+
+// admission predicts what submit would do with spec given the jobs that
+// are active and queued now, and the ID of the job it would preempt;
+// callers hold s.mu.
+func (s *Supervisor) admission(spec JobSpec, members []JobMember) (string, string, []Problem) {
+	if s.closing {
+		return AdmitReject, "", []Problem{newProblem("", ProblemShuttingDown, ErrShuttingDown)}
+	}
+	if atomic.LoadInt32(&s.activeJobs) >= s.maxConcurrent {
+		if victim := s.preemptible(spec.Priority); victim != nil {
+			return AdmitPreempt, victim.ID, nil
+		}
+		switch {
+		case s.maxQueue == 0:
+			return AdmitReject, "", []Problem{newProblem("", ProblemNoCapacity, s.concurrencyLimit())}
+		case len(s.queue) >= s.maxQueue:
+			return AdmitReject, "", []Problem{newProblem("", ProblemNoCapacity, ErrQueueFull)}
+		}
+		// whether the port is free is only known once the job leaves the queue
+		return AdmitQueue, "", nil
+	}
+	if err := s.portConflict(&Job{Spec: spec, Members: members}); err != nil {
+		return AdmitReject, "", []Problem{newProblem("port", ProblemPortConflict, err)}
+	}
+	return AdmitStart, "", nil
+}
@@ -0,0 +1,192 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func validateJob(t *testing.T, sup *Supervisor, spec JobSpec) (map[string]interface{}, []Problem) {
+	t.Helper()
+	resp, code, err := sup.ValidateJob(startReq{spec})
+	if err != nil || code != 200 {
+		t.Fatalf("ValidateJob: code=%d err=%v", code, err)
+	}
+	m := resp.(map[string]interface{})
+	ps, _ := m["problems"].([]Problem)
+	return m, ps
+}
+
+func problemFields(ps []Problem) map[string]string {
+	out := map[string]string{}
+	for _, p := range ps {
+		out[p.Field] = p.Code
+	}
+	return out
+}
+
+// preflightMirror is a fakeMirror whose Preflight reports problems.
+type preflightMirror struct {
+	fakeMirror
+	problems []Problem
+}
+
+func (p *preflightMirror) Preflight(JobSpec) []Problem { return p.problems }
+
+func TestSupervisor_ValidateJob_ListsEveryProblem(t *testing.T) {
+	mir := &preflightMirror{fakeMirror: fakeMirror{ifname: "mirror0"},
+		problems: []Problem{newProblem("port", ProblemPortNotFound, errors.New("port Eth0 not found"))}}
+	att := &fakeAttach{}
+	sup := NewSupervisor(mir, att, &fakeCollector{}, 1, WithMaxDuration(time.Hour))
+
+	spec := JobSpec{
+		Port: "Eth0", Direction: "sideways", SpanMethod: "rspan", Duration: 2 * time.Hour,
+		Filters: map[string]interface{}{"ip_proto": "sctp"}, Priority: -1,
+	}
+	m, ps := validateJob(t, sup, spec)
+	if m["valid"] != false {
+		t.Fatalf("expected invalid, got %v", m)
+	}
+	want := map[string]string{
+		"direction": ProblemInvalid, "span_method": ProblemInvalid, "duration_sec": ProblemInvalid,
+		"filters": ProblemInvalid, "priority": ProblemInvalid, "port": ProblemPortNotFound,
+	}
+	got := problemFields(ps)
+	for f, code := range want {
+		if got[f] != code {
+			t.Fatalf("%s: code %q, want %q (all: %+v)", f, got[f], code, ps)
+		}
+	}
+	if mir.calls != 0 || att.calls != 0 {
+		t.Fatalf("a dry run must not provision anything")
+	}
+	sup.mu.RLock()
+	n := len(sup.jobs)
+	sup.mu.RUnlock()
+	if n != 0 {
+		t.Fatalf("a dry run must not create jobs, have %d", n)
+	}
+
+	// submit rejects the same request with the first problem
+	if _, code, err := sup.TryStartJob(startReq{spec}); code != 400 || !errors.Is(err, ErrInvalidJob) {
+		t.Fatalf("expected 400 ErrInvalidJob, got code=%d err=%v", code, err)
+	}
+
+	mir.problems = nil
+	if m, ps := validateJob(t, sup, JobSpec{Port: "Eth0", Direction: "ingress", Duration: time.Minute}); m["valid"] != true || len(ps) != 0 || m["admission"] != AdmitStart {
+		t.Fatalf("expected a valid request, got %v", m)
+	}
+}
+
+func TestSupervisor_ValidateJob_Admission(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2, WithQueue(1, time.Minute))
+	startJob(t, sup, JobSpec{Port: "Eth0", Duration: time.Minute, Priority: 5})
+
+	m, ps := validateJob(t, sup, JobSpec{Port: "Eth0", Duration: time.Minute})
+	if m["admission"] != AdmitReject || problemFields(ps)["port"] != ProblemPortConflict {
+		t.Fatalf("expected a port conflict, got %v", m)
+	}
+
+	other := startJob(t, sup, JobSpec{Port: "Eth4", Duration: time.Minute})
+	if m, _ := validateJob(t, sup, JobSpec{Port: "Eth8", Duration: time.Minute}); m["admission"] != AdmitQueue || m["valid"] != true {
+		t.Fatalf("expected to be queued, got %v", m)
+	}
+	if m, _ := validateJob(t, sup, JobSpec{Port: "Eth8", Duration: time.Minute, Priority: 1}); m["admission"] != AdmitPreempt || m["preempt_job_id"] != other {
+		t.Fatalf("expected to preempt %s, got %v", other, m)
+	}
+
+	if _, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth8", Duration: time.Minute}}); code != 202 {
+		t.Fatalf("expected queued, got code=%d err=%v", code, err)
+	}
+	m, ps = validateJob(t, sup, JobSpec{Port: "Eth12", Duration: time.Minute})
+	if m["admission"] != AdmitReject || len(ps) != 1 || ps[0].Code != ProblemNoCapacity {
+		t.Fatalf("expected no capacity, got %v", m)
+	}
+	if err := sup.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	m, ps = validateJob(t, sup, JobSpec{Port: "Eth12", Duration: time.Minute})
+	if len(ps) != 1 || ps[0].Code != ProblemShuttingDown {
+		t.Fatalf("expected shutting_down, got %v", m)
+	}
+}
+
+func TestSupervisor_ValidateJob_PortProblems(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1)
+	sup.expand = func(port string) ([]string, error) {
+		switch port {
+		case "PortChannel9":
+			return nil, fmt.Errorf("%w: %w: %s", ErrInvalidJob, ErrPortNotFound, port)
+		case "PortChannel1":
+			return nil, fmt.Errorf("%w: %s has no member ports", ErrInvalidJob, port)
+		}
+		return []string{port}, nil
+	}
+
+	_, ps := validateJob(t, sup, JobSpec{Port: "PortChannel9", Duration: time.Minute})
+	if got := problemFields(ps); got["port"] != ProblemPortNotFound {
+		t.Fatalf("a missing PortChannel is port_not_found, got %+v", ps)
+	}
+	_, ps = validateJob(t, sup, JobSpec{Port: "PortChannel1", Duration: time.Minute})
+	if got := problemFields(ps); got["port"] != ProblemInvalid {
+		t.Fatalf("a PortChannel without members is invalid, got %+v", ps)
+	}
+
+	many := make([]string, maxJobPorts+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("Eth%d", i*4)
+	}
+	_, ps = validateJob(t, sup, JobSpec{Ports: many, Duration: time.Minute})
+	if got := problemFields(ps); got["ports"] != ProblemInvalid {
+		t.Fatalf("too many ports is invalid, got %+v", ps)
+	}
+}
+
+func TestMirror_Preflight(t *testing.T) {
+	restore, _ := withFakeIP(t, "exit 0")
+	defer restore()
+	t.Setenv("TELEGEN_MIRROR_MODE", "erspan")
+	t.Setenv("TELEGEN_ERSPAN_REMOTE", "")
+	t.Setenv("TELEGEN_ERSPAN_LOCAL", "")
+
+	got := problemFields((&Mirror{}).Preflight(JobSpec{Port: "no-such-port0"}))
+	if got["port"] != ProblemPortNotFound || got[""] != ProblemMirrorConfig {
+		t.Fatalf("unexpected problems: %v", got)
+	}
+
+	t.Setenv("TELEGEN_ERSPAN_REMOTE", "192.0.2.100")
+	t.Setenv("TELEGEN_ERSPAN_LOCAL", "192.0.2.10")
+	if ps := (&Mirror{}).Preflight(JobSpec{Port: "lo"}); len(ps) != 0 {
+		t.Fatalf("expected no problems, got %+v", ps)
+	}
+
+	t.Setenv("TELEGEN_MIRROR_MODE", "placeholder")
+	t.Setenv("TELEGEN_ERSPAN_REMOTE", "")
+	if ps := (&Mirror{}).Preflight(JobSpec{Port: "lo"}); len(ps) != 0 {
+		t.Fatalf("placeholder mode needs no ERSPAN settings, got %+v", ps)
+	}
+}
+
+func TestTC_Preflight(t *testing.T) {
+	restore := withFakeTC(t)
+	defer restore()
+	obj := filepath.Join(t.TempDir(), "tc_ingress.bpf.o")
+	t.Setenv("TELEGEN_BPF_OBJ", obj)
+
+	ps := (&TC{}).Preflight(JobSpec{})
+	if len(ps) != 1 || ps[0].Code != ProblemBPFObject {
+		t.Fatalf("expected a missing BPF object, got %+v", ps)
+	}
+	if err := os.WriteFile(obj, []byte{0x7f, 'E', 'L', 'F'}, 0o644); err != nil {
+		t.Fatalf("write temp obj: %v", err)
+	}
+	if ps := (&TC{}).Preflight(JobSpec{}); len(ps) != 0 {
+		t.Fatalf("expected no problems, got %+v", ps)
+	}
+}
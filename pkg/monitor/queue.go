@@ -0,0 +1,104 @@
+//go:build linux
+
+package monitor
+
+import (
+	"log"
+	"time"
+)
+
+// The helpers below manage the admission queue, which is FIFO within each
+// priority; callers hold s.mu.
+
+// enqueue queues j in the queued state and returns its 1-based position.
+func (s *Supervisor) enqueue(j *Job) (int, error) {
+	if len(s.queue) >= s.maxQueue {
+		return 0, ErrQueueFull
+	}
+	return s.insertQueued(j), nil
+}
+
+// insertQueued places j behind every queued job of the same or higher
+// priority, regardless of the queue limit.
+func (s *Supervisor) insertQueued(j *Job) int {
+	j.State = JobQueued // initial state, no transition
+	j.QueuedAt = time.Now()
+	s.jobs[j.ID] = j
+	i := len(s.queue)
+	for i > 0 && s.queue[i-1].Spec.Priority < j.Spec.Priority {
+		i--
+	}
+	s.queue = append(s.queue, nil)
+	copy(s.queue[i+1:], s.queue[i:])
+	s.queue[i] = j
+	if s.queueTTL > 0 {
+		id := j.ID
+		j.queueTimer = time.AfterFunc(s.queueTTL, func() { s.expireQueued(id) })
+	}
+	s.persist(j)
+	s.publish(j)
+	return i + 1
+}
+
+// dequeue pops the oldest queued job that can start, or returns nil. Jobs
+// whose port is now taken by another job fail instead of starting.
+func (s *Supervisor) dequeue() *Job {
+	for len(s.queue) > 0 {
+		j := s.queue[0]
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		if j.queueTimer != nil {
+			j.queueTimer.Stop()
+		}
+		if err := s.portConflict(j); err != nil {
+			j.FailureReason = err.Error()
+			_ = s.transition(j, JobFailed)
+			continue
+		}
+		_ = s.transition(j, JobStarting)
+		return j
+	}
+	return nil
+}
+
+func (s *Supervisor) removeQueued(j *Job) {
+	for i, q := range s.queue {
+		if q == j {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			break
+		}
+	}
+	if j.queueTimer != nil {
+		j.queueTimer.Stop()
+	}
+}
+
+func (s *Supervisor) queuePosition(id string) int {
+	for i, q := range s.queue {
+		if q.ID == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (s *Supervisor) expireQueued(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok || j.State != JobQueued {
+		return
+	}
+	s.removeQueued(j)
+	j.FailureReason = "expired in queue after " + s.queueTTL.String()
+	_ = s.transition(j, JobFailed)
+	log.Printf("job %s: expired in queue after %s", id, s.queueTTL)
+}
+
+// startQueued runs a dequeued job on the slot handed over by release.
+func (s *Supervisor) startQueued(j *Job) {
+	if err := s.start(j); err != nil {
+		log.Printf("job %s: start from queue failed: %v", j.ID, err)
+		s.release()
+	}
+}
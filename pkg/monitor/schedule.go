@@ -0,0 +1,60 @@
+package monitor
+
+import "time"
+
+// Policies for a scheduled run that finds every job slot busy.
+const (
+	PolicySkip  = "skip"  // record the run as skipped
+	PolicyQueue = "queue" // wait in the admission queue (skipped if it is disabled or full)
+)
+
+// Outcomes of a scheduled run.
+const (
+	RunStarted = "started"
+	RunQueued  = "queued"
+	RunSkipped = "skipped"
+	RunFailed  = "failed"
+)
+
+// maxScheduleHistory bounds the runs kept per schedule.
+const maxScheduleHistory = 50
+
+// ScheduleSpec is what a client supplies to create or replace a schedule.
+type ScheduleSpec struct {
+	Name    string
+	Cron    string
+	Spec    JobSpec
+	Policy  string
+	Enabled bool
+}
+
+// Schedule starts a job from Spec every time Cron fires. It is also the
+// persisted form, so it carries JSON tags.
+type Schedule struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Cron      string        `json:"cron"`
+	Spec      JobSpec       `json:"spec"`
+	Policy    string        `json:"policy"`
+	Enabled   bool          `json:"enabled"`
+	CreatedAt time.Time     `json:"created_at"`
+	NextRun   time.Time     `json:"next_run,omitempty"`
+	History   []ScheduleRun `json:"history,omitempty"` // oldest first
+
+	expr *cronExpr
+}
+
+// ScheduleRun links one firing of a schedule to the job it started.
+type ScheduleRun struct {
+	At     time.Time `json:"at"`
+	JobID  string    `json:"job_id,omitempty"`
+	Status string    `json:"status"` // started|queued|skipped|failed
+	Error  string    `json:"error,omitempty"`
+}
+
+// ScheduleStore persists schedules and their run history.
+type ScheduleStore interface {
+	SaveSchedule(sc Schedule) error
+	DeleteSchedule(id string) error
+	ListSchedules() ([]Schedule, error)
+}
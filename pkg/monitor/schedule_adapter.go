@@ -0,0 +1,105 @@
+//go:build linux
+
+package monitor
+
+import (
+	"github.com/platformbuilds/telegen-sonic/pkg/api"
+)
+
+// ScheduleAdapter exposes a Scheduler as api.ScheduleCore.
+type ScheduleAdapter struct {
+	S *Scheduler
+}
+
+func scheduleSpec(req api.ScheduleRequest) ScheduleSpec {
+	enabled := req.Enabled == nil || *req.Enabled
+	return ScheduleSpec{
+		Name:    req.Name,
+		Cron:    req.Cron,
+		Spec:    startRequest(req.Job).ToSpec(),
+		Policy:  req.Policy,
+		Enabled: enabled,
+	}
+}
+
+// specRequest is the inverse of startRequest.ToSpec.
+func specRequest(spec JobSpec) api.StartJobRequest {
+	return api.StartJobRequest{
+		Port:          spec.Port,
+		Ports:         spec.Ports,
+		Direction:     spec.Direction,
+		SpanMethod:    spec.SpanMethod,
+		VLAN:          spec.VLAN,
+		Filters:       spec.Filters,
+		SampleRate:    spec.SampleRate,
+		DurationSec:   int(spec.Duration.Seconds()),
+		OTLPExport:    spec.OTLPExport,
+		ResultDetail:  spec.ResultDetail,
+		AllowShared:   spec.AllowShared,
+		Priority:      spec.Priority,
+		MaxPackets:    spec.MaxPackets,
+		MaxBytes:      spec.MaxBytes,
+		MaxCPUSeconds: spec.MaxCPU.Seconds(),
+		Notify:        (*api.Notify)(spec.Notify),
+		Labels:        spec.Labels,
+		Owner:         spec.Owner,
+		Description:   spec.Description,
+	}
+}
+
+func apiSchedule(s Schedule) api.Schedule {
+	out := api.Schedule{
+		ScheduleID: s.ID,
+		Name:       s.Name,
+		Cron:       s.Cron,
+		Policy:     s.Policy,
+		Enabled:    s.Enabled,
+		Job:        specRequest(s.Spec),
+		CreatedAt:  s.CreatedAt,
+		History:    make([]api.ScheduleRun, 0, len(s.History)),
+	}
+	if !s.NextRun.IsZero() {
+		next := s.NextRun
+		out.NextRun = &next
+	}
+	for _, r := range s.History {
+		out.History = append(out.History, api.ScheduleRun{At: r.At, JobID: r.JobID, Status: r.Status, Error: r.Error})
+	}
+	return out
+}
+
+func (a *ScheduleAdapter) CreateSchedule(req api.ScheduleRequest) (api.Schedule, int, error) {
+	s, code, err := a.S.Create(scheduleSpec(req))
+	if err != nil {
+		return api.Schedule{}, code, apiError(err, "job.")
+	}
+	return apiSchedule(s), code, nil
+}
+
+func (a *ScheduleAdapter) ListSchedules() (api.ListSchedulesResponse, int, error) {
+	out := api.ListSchedulesResponse{Schedules: []api.Schedule{}}
+	for _, s := range a.S.List() {
+		out.Schedules = append(out.Schedules, apiSchedule(s))
+	}
+	return out, 200, nil
+}
+
+func (a *ScheduleAdapter) GetSchedule(id string) (api.Schedule, int, error) {
+	s, code, err := a.S.Get(id)
+	if err != nil {
+		return api.Schedule{}, code, err
+	}
+	return apiSchedule(s), code, nil
+}
+
+func (a *ScheduleAdapter) UpdateSchedule(id string, req api.ScheduleRequest) (api.Schedule, int, error) {
+	s, code, err := a.S.Update(id, scheduleSpec(req))
+	if err != nil {
+		return api.Schedule{}, code, apiError(err, "job.")
+	}
+	return apiSchedule(s), code, nil
+}
+
+func (a *ScheduleAdapter) DeleteSchedule(id string) (int, error) {
+	return a.S.Delete(id)
+}
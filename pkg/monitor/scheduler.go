@@ -0,0 +1,260 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scheduler starts jobs on cron schedules through the Supervisor, so
+// scheduled runs go through the same concurrency gate, queue and port
+// checks as API requests. Schedules are evaluated in the agent's local time.
+type Scheduler struct {
+	sup   *Supervisor
+	store ScheduleStore // optional; nil keeps schedules in memory only
+
+	mu        sync.Mutex
+	schedules map[string]*Schedule
+}
+
+func NewScheduler(sup *Supervisor, st ScheduleStore) *Scheduler {
+	return &Scheduler{sup: sup, store: st, schedules: make(map[string]*Schedule)}
+}
+
+// Load restores persisted schedules. Runs missed while the agent was down
+// are not caught up; each schedule resumes at its next fire time.
+func (sc *Scheduler) Load() error {
+	if sc.store == nil {
+		return nil
+	}
+	recs, err := sc.store.ListSchedules()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for _, rec := range recs {
+		expr, err := parseCron(rec.Cron)
+		if err != nil {
+			log.Printf("schedule %s: dropping unparsable cron: %v", rec.ID, err)
+			continue
+		}
+		s := rec
+		s.expr = expr
+		s.NextRun = time.Time{}
+		if s.Enabled {
+			s.NextRun = expr.next(now)
+		}
+		sc.schedules[s.ID] = &s
+	}
+	return nil
+}
+
+// persist saves s; callers hold sc.mu.
+func (sc *Scheduler) persist(s *Schedule) {
+	if sc.store == nil {
+		return
+	}
+	if err := sc.store.SaveSchedule(*s); err != nil {
+		log.Printf("schedule %s: persist failed: %v", s.ID, err)
+	}
+}
+
+// validate checks spec and returns its parsed cron expression.
+func (sc *Scheduler) validate(spec *ScheduleSpec) (*cronExpr, error) {
+	expr, err := parseCron(spec.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	switch spec.Policy {
+	case "":
+		spec.Policy = PolicySkip
+	case PolicySkip, PolicyQueue:
+	default:
+		return nil, fmt.Errorf("%w: policy must be %q or %q", ErrInvalidSchedule, PolicySkip, PolicyQueue)
+	}
+	if spec.Spec.Duration <= 0 {
+		return nil, fmt.Errorf("%w: job duration must be positive", ErrInvalidSchedule)
+	}
+	if ps := sc.sup.problems(spec.Spec); len(ps) > 0 {
+		return nil, &InvalidJobError{Problems: ps}
+	}
+	return expr, nil
+}
+
+func (sc *Scheduler) Create(spec ScheduleSpec) (Schedule, int, error) {
+	expr, err := sc.validate(&spec)
+	if err != nil {
+		return Schedule{}, 400, err
+	}
+	now := time.Now()
+	s := &Schedule{
+		ID:        uuid.NewString(),
+		Name:      spec.Name,
+		Cron:      spec.Cron,
+		Spec:      spec.Spec,
+		Policy:    spec.Policy,
+		Enabled:   spec.Enabled,
+		CreatedAt: now,
+		expr:      expr,
+	}
+	if s.Enabled {
+		s.NextRun = expr.next(now)
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.schedules[s.ID] = s
+	sc.persist(s)
+	return s.snapshot(), 201, nil
+}
+
+// Update replaces a schedule's definition; its run history is kept.
+func (sc *Scheduler) Update(id string, spec ScheduleSpec) (Schedule, int, error) {
+	expr, err := sc.validate(&spec)
+	if err != nil {
+		return Schedule{}, 400, err
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	s, ok := sc.schedules[id]
+	if !ok {
+		return Schedule{}, 404, ErrScheduleNotFound
+	}
+	s.Name, s.Cron, s.Spec, s.Policy, s.Enabled = spec.Name, spec.Cron, spec.Spec, spec.Policy, spec.Enabled
+	s.expr = expr
+	s.NextRun = time.Time{}
+	if s.Enabled {
+		s.NextRun = expr.next(time.Now())
+	}
+	sc.persist(s)
+	return s.snapshot(), 200, nil
+}
+
+// Delete removes a schedule. Jobs it already started are left running.
+func (sc *Scheduler) Delete(id string) (int, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if _, ok := sc.schedules[id]; !ok {
+		return 404, ErrScheduleNotFound
+	}
+	delete(sc.schedules, id)
+	if sc.store != nil {
+		if err := sc.store.DeleteSchedule(id); err != nil {
+			log.Printf("schedule %s: delete failed: %v", id, err)
+		}
+	}
+	return 204, nil
+}
+
+func (sc *Scheduler) Get(id string) (Schedule, int, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	s, ok := sc.schedules[id]
+	if !ok {
+		return Schedule{}, 404, ErrScheduleNotFound
+	}
+	return s.snapshot(), 200, nil
+}
+
+// List returns every schedule, oldest first.
+func (sc *Scheduler) List() []Schedule {
+	sc.mu.Lock()
+	out := make([]Schedule, 0, len(sc.schedules))
+	for _, s := range sc.schedules {
+		out = append(out, s.snapshot())
+	}
+	sc.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// snapshot copies s so callers can read it without holding sc.mu.
+func (s *Schedule) snapshot() Schedule {
+	out := *s
+	out.History = append([]ScheduleRun(nil), s.History...)
+	out.expr = nil
+	return out
+}
+
+// Run fires due schedules at the top of every minute until ctx is done.
+func (sc *Scheduler) Run(ctx context.Context) {
+	for {
+		now := time.Now()
+		t := time.NewTimer(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case now := <-t.C:
+			sc.tick(now)
+		}
+	}
+}
+
+// tick starts a job for every enabled schedule due at now and returns how
+// many fired.
+func (sc *Scheduler) tick(now time.Time) int {
+	type due struct {
+		id     string
+		spec   JobSpec
+		policy string
+	}
+	var fire []due
+	sc.mu.Lock()
+	for _, s := range sc.schedules {
+		if !s.Enabled || s.NextRun.IsZero() || now.Before(s.NextRun) {
+			continue
+		}
+		fire = append(fire, due{s.ID, s.Spec, s.Policy})
+		s.NextRun = s.expr.next(now)
+	}
+	sc.mu.Unlock()
+
+	for _, d := range fire {
+		run := sc.fire(d.spec, d.policy)
+		run.At = now
+
+		sc.mu.Lock()
+		if s, ok := sc.schedules[d.id]; ok {
+			s.History = append(s.History, run)
+			if n := len(s.History); n > maxScheduleHistory {
+				s.History = append([]ScheduleRun(nil), s.History[n-maxScheduleHistory:]...)
+			}
+			sc.persist(s)
+		}
+		sc.mu.Unlock()
+	}
+	return len(fire)
+}
+
+// fire submits one run; busy slots and port conflicts count as skipped.
+func (sc *Scheduler) fire(spec JobSpec, policy string) ScheduleRun {
+	resp, _, err := sc.sup.submit(spec, policy == PolicyQueue)
+	if err != nil {
+		status := RunFailed
+		if errors.Is(err, ErrConcurrencyLimit) || errors.Is(err, ErrQueueFull) || errors.Is(err, ErrPortConflict) {
+			status = RunSkipped
+		}
+		return ScheduleRun{Status: status, Error: err.Error()}
+	}
+	m := resp.(map[string]interface{})
+	run := ScheduleRun{JobID: m["job_id"].(string), Status: RunStarted}
+	if m["status"] == string(JobQueued) {
+		run.Status = RunQueued
+	}
+	return run
+}
@@ -0,0 +1,169 @@
+//go:build linux
+
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func hourlySpec(port string) ScheduleSpec {
+	return ScheduleSpec{
+		Name:    "baseline " + port,
+		Cron:    "0 * * * *",
+		Spec:    JobSpec{Port: port, Duration: time.Minute},
+		Enabled: true,
+	}
+}
+
+// dueAt moves a schedule's next run so tick(now) fires it.
+func dueAt(sc *Scheduler, id string, now time.Time) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.schedules[id].NextRun = now
+}
+
+func TestScheduler_TickStartsJobsAndRecordsHistory(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)
+	sc := NewScheduler(sup, nil)
+
+	s, code, err := sc.Create(hourlySpec("Ethernet16"))
+	if err != nil || code != 201 {
+		t.Fatalf("Create err=%v code=%d", err, code)
+	}
+	if s.Policy != PolicySkip || s.NextRun.Minute() != 0 || !s.NextRun.After(time.Now()) {
+		t.Fatalf("unexpected schedule: %+v", s)
+	}
+
+	// not due yet
+	if n := sc.tick(time.Now()); n != 0 {
+		t.Fatalf("tick fired %d schedules before they were due", n)
+	}
+
+	now := time.Now()
+	dueAt(sc, s.ID, now)
+	if n := sc.tick(now); n != 1 {
+		t.Fatalf("expected one schedule to fire, got %d", n)
+	}
+	got, _, _ := sc.Get(s.ID)
+	if len(got.History) != 1 || got.History[0].Status != RunStarted || got.History[0].JobID == "" {
+		t.Fatalf("run not recorded: %+v", got.History)
+	}
+	if !got.NextRun.After(now) {
+		t.Fatalf("next run should advance past %s, got %s", now, got.NextRun)
+	}
+	if st := jobField(t, sup, got.History[0].JobID, "port"); st != "Ethernet16" {
+		t.Fatalf("scheduled job has port %v", st)
+	}
+}
+
+func TestScheduler_SkipsWhenSlotsBusy(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1,
+		WithQueue(4, time.Minute))
+	sc := NewScheduler(sup, nil)
+	startJob(t, sup, JobSpec{Port: "Ethernet0", Duration: time.Minute})
+
+	skip, _, _ := sc.Create(hourlySpec("Ethernet4"))
+	queueSpec := hourlySpec("Ethernet8")
+	queueSpec.Policy = PolicyQueue
+	queue, _, _ := sc.Create(queueSpec)
+
+	now := time.Now()
+	dueAt(sc, skip.ID, now)
+	dueAt(sc, queue.ID, now)
+	if n := sc.tick(now); n != 2 {
+		t.Fatalf("expected two schedules to fire, got %d", n)
+	}
+
+	got, _, _ := sc.Get(skip.ID)
+	if r := got.History[0]; r.Status != RunSkipped || r.JobID != "" || r.Error == "" {
+		t.Fatalf("skip policy should not start or queue a job: %+v", r)
+	}
+	got, _, _ = sc.Get(queue.ID)
+	r := got.History[0]
+	if r.Status != RunQueued || r.JobID == "" {
+		t.Fatalf("queue policy should queue a job: %+v", r)
+	}
+	if st := jobField(t, sup, r.JobID, "status"); st != string(JobQueued) {
+		t.Fatalf("queued run has status %v", st)
+	}
+}
+
+func TestScheduler_Validation(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1,
+		WithMaxDuration(10*time.Minute))
+	sc := NewScheduler(sup, nil)
+
+	bad := []func(*ScheduleSpec){
+		func(s *ScheduleSpec) { s.Cron = "every hour" },
+		func(s *ScheduleSpec) { s.Policy = "later" },
+		func(s *ScheduleSpec) { s.Spec.Port = "" },
+		func(s *ScheduleSpec) { s.Spec.Duration = 0 },
+		func(s *ScheduleSpec) { s.Spec.Duration = time.Hour },
+		func(s *ScheduleSpec) { s.Spec.Ports = []string{"Ethernet4"} },
+	}
+	for i, mutate := range bad {
+		spec := hourlySpec("Ethernet0")
+		mutate(&spec)
+		if _, code, err := sc.Create(spec); code != 400 || err == nil {
+			t.Errorf("case %d: expected 400, got code=%d err=%v", i, code, err)
+		}
+	}
+	ports := hourlySpec("")
+	ports.Spec.Ports = []string{"Ethernet0", "Ethernet4"}
+	if _, code, err := sc.Create(ports); code != 201 || err != nil {
+		t.Fatalf("ports-only schedule: code=%d err=%v", code, err)
+	}
+	if _, code, err := sc.Get("nope"); code != 404 || !errors.Is(err, ErrScheduleNotFound) {
+		t.Fatalf("Get unknown: code=%d err=%v", code, err)
+	}
+	if code, err := sc.Delete("nope"); code != 404 || !errors.Is(err, ErrScheduleNotFound) {
+		t.Fatalf("Delete unknown: code=%d err=%v", code, err)
+	}
+}
+
+func TestScheduler_PersistUpdateDelete(t *testing.T) {
+	st, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)
+	sc := NewScheduler(sup, st)
+	s, _, _ := sc.Create(hourlySpec("Ethernet16"))
+	now := time.Now()
+	dueAt(sc, s.ID, now)
+	sc.tick(now)
+
+	// disabling keeps history but stops firing
+	upd := hourlySpec("Ethernet16")
+	upd.Cron = "@daily"
+	upd.Enabled = false
+	got, code, err := sc.Update(s.ID, upd)
+	if err != nil || code != 200 {
+		t.Fatalf("Update err=%v code=%d", err, code)
+	}
+	if got.Cron != "@daily" || !got.NextRun.IsZero() || len(got.History) != 1 {
+		t.Fatalf("unexpected update result: %+v", got)
+	}
+
+	// a restarted agent sees the same schedule and history
+	sc2 := NewScheduler(sup, st)
+	if err := sc2.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	list := sc2.List()
+	if len(list) != 1 || list[0].ID != s.ID || list[0].Enabled || len(list[0].History) != 1 {
+		t.Fatalf("schedule not restored: %+v", list)
+	}
+	if n := sc2.tick(time.Now().Add(48 * time.Hour)); n != 0 {
+		t.Fatalf("disabled schedule fired")
+	}
+
+	if code, err := sc2.Delete(s.ID); err != nil || code != 204 {
+		t.Fatalf("Delete err=%v code=%d", err, code)
+	}
+	if recs, _ := st.ListSchedules(); len(recs) != 0 {
+		t.Fatalf("schedule still persisted: %+v", recs)
+	}
+}
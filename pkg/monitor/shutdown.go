@@ -0,0 +1,61 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// Shutdown stops admitting jobs, fails the queued ones, stops every active
+// job and waits until their mirrors and tc attachments are torn down or ctx
+// expires. Jobs end as done with stop reason "shutdown". Webhooks get one
+// more attempt but are no longer retried. Event subscriptions are closed
+// on return.
+func (s *Supervisor) Shutdown(ctx context.Context) error {
+	defer s.events.Close()
+	s.stopNotify()
+	s.mu.Lock()
+	s.closing = true
+	for _, j := range s.queue {
+		if j.queueTimer != nil {
+			j.queueTimer.Stop()
+		}
+		j.FailureReason = "agent shut down before the job started"
+		_ = s.transition(j, JobFailed)
+	}
+	s.queue = nil
+
+	var cancels []func()
+	for _, j := range s.jobs {
+		switch j.State {
+		case JobStarting, JobRunning:
+			j.StopReason = StopShutdown
+			_ = s.transition(j, JobStopping)
+			// a job still provisioning has no cancel yet; start() sees
+			// it stopping and cancels itself
+			if j.cancel != nil {
+				cancels = append(cancels, j.cancel)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	log.Printf("shutdown: stopping %d active jobs", len(cancels))
+	for _, c := range cancels {
+		c()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("jobs still tearing down: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,76 @@
+//go:build linux
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSupervisor_Shutdown_DrainsJobs(t *testing.T) {
+	mir := &fakeMirror{ifname: "mirror0"}
+	att := &fakeAttach{}
+	sup := NewSupervisor(mir, att, &fakeCollector{}, 1, WithQueue(1, time.Minute))
+	spec := JobSpec{Port: "Eth0", Duration: time.Minute}
+
+	running := startJob(t, sup, spec)
+	waitState(t, sup, running, JobRunning)
+	queued, code, err := sup.TryStartJob(startReq{spec})
+	if err != nil || code != 202 {
+		t.Fatalf("expected queued job, got code=%d err=%v", code, err)
+	}
+	qid := queued.(map[string]interface{})["job_id"].(string)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := sup.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	// Shutdown only returns once teardown has run
+	if got := jobField(t, sup, running, "status"); got != string(JobDone) {
+		t.Fatalf("running job should be done, got %v", got)
+	}
+	if got := jobField(t, sup, running, "stop_reason"); got != StopShutdown {
+		t.Fatalf("stop_reason = %v, want %q", got, StopShutdown)
+	}
+	if n := atomic.LoadInt32(&att.cleanups); n != 1 {
+		t.Fatalf("tc cleanup ran %d times, want 1", n)
+	}
+	if got := jobField(t, sup, qid, "status"); got != string(JobFailed) {
+		t.Fatalf("queued job should be failed, got %v", got)
+	}
+	// the freed slot must not start the queued job
+	if n := atomic.LoadInt32(&mir.calls); n != 1 {
+		t.Fatalf("mirror created %d times, want 1", n)
+	}
+
+	if _, code, err := sup.TryStartJob(startReq{spec}); code != 503 || !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("expected 503 ErrShuttingDown, got code=%d err=%v", code, err)
+	}
+}
+
+type stuckCleanupAttach struct{ release chan struct{} }
+
+func (a stuckCleanupAttach) Attach(ifname string, spec JobSpec) (func() error, error) {
+	return func() error { <-a.release; return nil }, nil
+}
+
+func TestSupervisor_Shutdown_Timeout(t *testing.T) {
+	att := stuckCleanupAttach{release: make(chan struct{})}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, att, &fakeCollector{}, 1)
+	id := startJob(t, sup, JobSpec{Port: "Eth0", Duration: time.Minute})
+	waitState(t, sup, id, JobRunning)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := sup.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	close(att.release)
+	waitState(t, sup, id, JobDone)
+}
@@ -0,0 +1,162 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JobStore persists job records so job history and the resources a job
+// provisioned survive agent restarts.
+type JobStore interface {
+	Save(rec JobRecord) error
+	Delete(id string) error
+	List() ([]JobRecord, error)
+}
+
+// JobRecord is the persisted form of a Job.
+type JobRecord struct {
+	ID        string    `json:"id"`
+	Spec      JobSpec   `json:"spec"`
+	State     JobState  `json:"state"`
+	CreatedAt time.Time `json:"created_at"`
+	QueuedAt  time.Time `json:"queued_at,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	EndedAt   time.Time `json:"ended_at,omitempty"`
+	IfName    string    `json:"interface"`
+
+	FailureReason string            `json:"failure_reason,omitempty"`
+	StepErrors    map[string]string `json:"step_errors,omitempty"`
+	StopReason    string            `json:"stop_reason,omitempty"`
+	PreemptedBy   string            `json:"preempted_by,omitempty"`
+	Members       []JobMember       `json:"members,omitempty"`
+}
+
+// FileStore keeps one JSON file per job under Dir, and one per schedule
+// under Dir/schedules.
+type FileStore struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// NewFileStore creates dir if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create job store dir: %w", err)
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+// Save writes the record atomically (temp file + rename).
+func (f *FileStore) Save(rec JobRecord) error {
+	return f.write(f.Dir, rec.ID, rec)
+}
+
+func (f *FileStore) Delete(id string) error {
+	return f.remove(f.Dir, id)
+}
+
+// List returns every readable record; unreadable files are skipped so one
+// corrupt record cannot block startup.
+func (f *FileStore) List() ([]JobRecord, error) {
+	var out []JobRecord
+	err := f.each(f.Dir, func(b []byte) {
+		var rec JobRecord
+		if err := json.Unmarshal(b, &rec); err == nil && rec.ID != "" {
+			out = append(out, rec)
+		}
+	})
+	return out, err
+}
+
+// Schedules live in a subdirectory so List never mistakes them for jobs.
+func (f *FileStore) scheduleDir() string {
+	return filepath.Join(f.Dir, "schedules")
+}
+
+func (f *FileStore) SaveSchedule(sc Schedule) error {
+	return f.write(f.scheduleDir(), sc.ID, sc)
+}
+
+func (f *FileStore) DeleteSchedule(id string) error {
+	return f.remove(f.scheduleDir(), id)
+}
+
+func (f *FileStore) ListSchedules() ([]Schedule, error) {
+	var out []Schedule
+	err := f.each(f.scheduleDir(), func(b []byte) {
+		var sc Schedule
+		if err := json.Unmarshal(b, &sc); err == nil && sc.ID != "" {
+			out = append(out, sc)
+		}
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return out, err
+}
+
+// write stores v as dir/id.json atomically (temp file + rename).
+func (f *FileStore) write(dir, id string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, id+".json"))
+}
+
+func (f *FileStore) remove(dir, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.Remove(filepath.Join(dir, id+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// each calls fn with the contents of every readable .json file in dir.
+func (f *FileStore) each(dir string, fn func([]byte)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		fn(b)
+	}
+	return nil
+}
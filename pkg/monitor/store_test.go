@@ -0,0 +1,86 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStore_SaveListDelete(t *testing.T) {
+	fs, err := NewFileStore(filepath.Join(t.TempDir(), "jobs"))
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	rec := JobRecord{
+		ID:        "j1",
+		Spec:      JobSpec{Port: "Ethernet0", Duration: time.Minute},
+		State:     JobRunning,
+		StartedAt: time.Now().UTC().Truncate(time.Second),
+		IfName:    "erspan0",
+	}
+	if err := fs.Save(rec); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	rec.State = JobDone
+	if err := fs.Save(rec); err != nil {
+		t.Fatalf("Save (overwrite): %v", err)
+	}
+
+	// a corrupt file must not break List
+	if err := os.WriteFile(filepath.Join(fs.Dir, "bad.json"), []byte("{"), 0o644); err != nil {
+		t.Fatalf("write corrupt record: %v", err)
+	}
+
+	recs, err := fs.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(recs) != 1 || recs[0].State != JobDone || recs[0].Spec.Duration != time.Minute || recs[0].IfName != "erspan0" {
+		t.Fatalf("unexpected records: %+v", recs)
+	}
+
+	if err := fs.Delete("j1"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := fs.Delete("j1"); err != nil {
+		t.Fatalf("Delete should be idempotent: %v", err)
+	}
+	if recs, _ := fs.List(); len(recs) != 0 {
+		t.Fatalf("expected empty store, got %+v", recs)
+	}
+}
+
+func TestFileStore_SchedulesKeptApartFromJobs(t *testing.T) {
+	fs, err := NewFileStore(filepath.Join(t.TempDir(), "jobs"))
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	if got, err := fs.ListSchedules(); err != nil || len(got) != 0 {
+		t.Fatalf("empty store: got=%v err=%v", got, err)
+	}
+	sc := Schedule{ID: "s1", Cron: "@hourly", Spec: JobSpec{Port: "Ethernet16"}, Enabled: true,
+		History: []ScheduleRun{{JobID: "j1", Status: RunStarted}}}
+	if err := fs.SaveSchedule(sc); err != nil {
+		t.Fatalf("SaveSchedule: %v", err)
+	}
+	if err := fs.Save(JobRecord{ID: "j1", State: JobDone}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	scheds, err := fs.ListSchedules()
+	if err != nil || len(scheds) != 1 || scheds[0].Cron != "@hourly" || scheds[0].History[0].JobID != "j1" {
+		t.Fatalf("ListSchedules: got=%+v err=%v", scheds, err)
+	}
+	jobs, err := fs.List()
+	if err != nil || len(jobs) != 1 || jobs[0].ID != "j1" {
+		t.Fatalf("List should only return jobs: got=%+v err=%v", jobs, err)
+	}
+
+	if err := fs.DeleteSchedule("s1"); err != nil {
+		t.Fatalf("DeleteSchedule: %v", err)
+	}
+	if scheds, _ := fs.ListSchedules(); len(scheds) != 0 {
+		t.Fatalf("schedule not deleted: %+v", scheds)
+	}
+}
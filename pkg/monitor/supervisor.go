@@ -4,6 +4,9 @@ package monitor
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,12 +22,41 @@ type AttachProvider interface {
 	Attach(ifname string, spec JobSpec) (cleanup func() error, err error)
 }
 
-type Collector interface {
-	Run(ctx context.Context, jobID string, spec JobSpec) (ResultsProvider, error)
+// Providers may also implement Teardown(ifname) / Detach(ifname) so the
+// Supervisor can remove resources left behind by a crashed agent.
+type mirrorTeardown interface {
+	Teardown(ifname string) error
+}
+
+type attachDetach interface {
+	Detach(ifname string) error
+}
+
+// mirrorNamer is implemented by mirror providers that name a member's
+// mirror after the job and port alone, so Reconcile can find mirrors a
+// crash left behind before the job recorded them.
+type mirrorNamer interface {
+	MirrorName(spec JobSpec) string
+}
+
+// attachUpdate is implemented by providers that can change a live job's
+// sample rate and filters in place.
+type attachUpdate interface {
+	Update(ifname string, spec JobSpec) error
 }
 
-type ResultsProvider interface {
-	Summary() interface{}
+// earlyStopper is implemented by results providers that end a job before
+// its deadline, e.g. once it exceeds a budget. The channel is closed when
+// the job should stop and StopReason says why.
+type earlyStopper interface {
+	StopC() <-chan struct{}
+	StopReason() string
+}
+
+// Collector counts a job's traffic on the interfaces provisioned for it,
+// one per member port unless members share a mirror.
+type Collector interface {
+	Run(ctx context.Context, jobID string, ifnames []string, spec JobSpec) (ResultsProvider, error)
 }
 
 // Supervisor implements Core interface for API handlers
@@ -36,18 +68,260 @@ type Supervisor struct {
 	maxConcurrent int32
 	activeJobs    int32
 
+	store JobStore // optional; nil keeps jobs in memory only
+
+	// FIFO admission queue (disabled when maxQueue is 0); guarded by mu
+	maxQueue int
+	queueTTL time.Duration
+	queue    []*Job
+
 	mu   sync.RWMutex // protects jobs map and fields of *Job
 	jobs map[string]*Job
+
+	leaseMu sync.Mutex // taken before mu, never after
+	leases  map[string]*ifLease
+
+	retention time.Duration // terminal jobs older than this are evicted; 0 keeps them
+
+	maxDuration time.Duration // upper bound for a job's duration; 0 is unbounded
+
+	events *EventBus // job state changes, for /v1/events
+
+	notifier Notifier // optional; delivers results of jobs with a notify block
+
+	// notifyCtx is handed to the notifier and cancelled by Shutdown, so
+	// pending retries do not hold up the drain
+	notifyCtx  context.Context
+	stopNotify context.CancelFunc
+
+	expand func(port string) ([]string, error) // PortChannel -> member ports
+
+	closing bool           // set by Shutdown; guarded by mu
+	wg      sync.WaitGroup // one per provisioned job or pending notification
 }
 
-func NewSupervisor(m MirrorProvider, a AttachProvider, c Collector, max int) *Supervisor {
-	return &Supervisor{
+// Notifier is handed every job with a notify block once it reaches done or
+// failed. It runs on its own goroutine and may block while it retries,
+// but should give up once ctx is done.
+type Notifier interface {
+	JobFinished(ctx context.Context, jobID string, state JobState, n Notify)
+}
+
+// Option configures optional Supervisor features.
+type Option func(*Supervisor)
+
+// WithStore persists every job state change to st.
+func WithStore(st JobStore) Option {
+	return func(s *Supervisor) { s.store = st }
+}
+
+// WithQueue lets up to size jobs wait for a free slot instead of being
+// rejected. Queued jobs that have not started within ttl expire as failed.
+func WithQueue(size int, ttl time.Duration) Option {
+	return func(s *Supervisor) {
+		s.maxQueue = size
+		s.queueTTL = ttl
+	}
+}
+
+// WithRetention lets RunGC evict finished jobs once they have been done or
+// failed for longer than d.
+func WithRetention(d time.Duration) Option {
+	return func(s *Supervisor) { s.retention = d }
+}
+
+// WithMaxDuration caps how long a job may run, both when it is started and
+// when it is extended.
+func WithMaxDuration(d time.Duration) Option {
+	return func(s *Supervisor) { s.maxDuration = d }
+}
+
+// WithNotifier delivers the final results of jobs that ask for it through n.
+func WithNotifier(n Notifier) Option {
+	return func(s *Supervisor) { s.notifier = n }
+}
+
+func NewSupervisor(m MirrorProvider, a AttachProvider, c Collector, max int, opts ...Option) *Supervisor {
+	s := &Supervisor{
 		mir: m, att: a, col: c,
 		maxConcurrent: int32(max),
 		jobs:          make(map[string]*Job),
+		leases:        make(map[string]*ifLease),
+		events:        NewEventBus(),
+		expand:        expandPort,
+	}
+	s.notifyCtx, s.stopNotify = context.WithCancel(context.Background())
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// persist saves j to the store; callers hold s.mu.
+func (s *Supervisor) persist(j *Job) {
+	if s.store == nil {
+		return
+	}
+	if err := s.store.Save(j.record()); err != nil {
+		log.Printf("job %s: persist failed: %v", j.ID, err)
+	}
+}
+
+// transition moves j to state to if the lifecycle allows it, then persists
+// the job; callers hold s.mu.
+func (s *Supervisor) transition(j *Job, to JobState) error {
+	if !j.State.canTransition(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, j.State, to)
+	}
+	j.State = to
+	if to.terminal() {
+		j.EndedAt = time.Now()
+		if s.notifier != nil && j.Spec.Notify != nil {
+			// Shutdown waits for deliveries too
+			s.wg.Add(1)
+			go func(id string, n Notify) {
+				defer s.wg.Done()
+				s.notifier.JobFinished(s.notifyCtx, id, to, n)
+			}(j.ID, *j.Spec.Notify)
+		}
+	}
+	s.persist(j)
+	s.publish(j)
+	return nil
+}
+
+// publish announces j's current state; callers hold s.mu.
+func (s *Supervisor) publish(j *Job) {
+	e := Event{JobID: j.ID, State: j.State}
+	switch j.State {
+	case JobFailed:
+		e.Reason = j.FailureReason
+	case JobStopping, JobDone:
+		e.Reason = j.StopReason
+	}
+	s.events.Publish(e)
+}
+
+// Events returns the bus job state changes are published on.
+func (s *Supervisor) Events() *EventBus { return s.events }
+
+// fail records err against step and moves j to failed; callers hold s.mu.
+func (s *Supervisor) fail(j *Job, step string, err error) {
+	j.stepError(step, err)
+	if terr := s.transition(j, JobFailed); terr != nil {
+		log.Printf("job %s: %v", j.ID, terr)
 	}
 }
 
+// Reconcile reloads persisted jobs at startup. Jobs that were still active
+// when the previous agent process died are marked failed, and the erspan
+// links and tc filters they provisioned are torn down.
+func (s *Supervisor) Reconcile() error {
+	if s.store == nil {
+		return nil
+	}
+	recs, err := s.store.List()
+	if err != nil {
+		return err
+	}
+
+	var orphaned []string
+	seen := map[string]bool{}
+	s.mu.Lock()
+	for _, rec := range recs {
+		j := &Job{
+			ID:        rec.ID,
+			Spec:      rec.Spec,
+			State:     rec.State,
+			CreatedAt: rec.CreatedAt,
+			QueuedAt:  rec.QueuedAt,
+			StartedAt: rec.StartedAt,
+			ExpiresAt: rec.ExpiresAt,
+			EndedAt:   rec.EndedAt,
+			IfName:    rec.IfName,
+
+			FailureReason: rec.FailureReason,
+			StepErrors:    rec.StepErrors,
+			StopReason:    rec.StopReason,
+			PreemptedBy:   rec.PreemptedBy,
+			Members:       rec.Members,
+		}
+		if !j.State.terminal() {
+			j.FailureReason = "interrupted by agent restart"
+			if err := s.transition(j, JobFailed); err != nil {
+				log.Printf("reconcile: job %s: %v", j.ID, err)
+			}
+			for _, ifname := range s.leftovers(j) {
+				if ifname != "" && !seen[ifname] {
+					seen[ifname] = true
+					orphaned = append(orphaned, ifname)
+				}
+			}
+		}
+		s.jobs[j.ID] = j
+	}
+	s.mu.Unlock()
+
+	for _, ifname := range orphaned {
+		if d, ok := s.att.(attachDetach); ok {
+			if err := d.Detach(ifname); err != nil {
+				log.Printf("reconcile: detach tc from %s: %v", ifname, err)
+			}
+		}
+		if t, ok := s.mir.(mirrorTeardown); ok {
+			if err := t.Teardown(ifname); err != nil {
+				log.Printf("reconcile: teardown mirror %s: %v", ifname, err)
+			}
+		}
+	}
+	return nil
+}
+
+// leftovers lists the interfaces an interrupted job may have left behind:
+// those it recorded and, for members provisioned after the job was last
+// saved, the names the mirror provider gives them.
+func (s *Supervisor) leftovers(j *Job) []string {
+	out := append(j.interfaces(), j.IfName)
+	namer, ok := s.mir.(mirrorNamer)
+	if !ok {
+		return out
+	}
+	ports := j.Spec.requestedPorts()
+	if len(j.Members) > 0 {
+		ports = nil
+		for _, m := range j.Members {
+			if m.IfName == "" {
+				ports = append(ports, m.Port)
+			}
+		}
+	}
+	for _, p := range ports {
+		out = append(out, namer.MirrorName(j.Spec.forMember(j.ID, p)))
+	}
+	return out
+}
+
+// concurrencyLimit is ErrConcurrencyLimit with the configured number of slots.
+func (s *Supervisor) concurrencyLimit() error {
+	return fmt.Errorf("%w: at most %d jobs can run at once", ErrConcurrencyLimit, s.maxConcurrent)
+}
+
+// JobCounts reports the jobs holding a slot, the jobs waiting in the
+// queue and the number of slots.
+func (s *Supervisor) JobCounts() (active, queued, slots int) {
+	s.mu.RLock()
+	queued = len(s.queue)
+	s.mu.RUnlock()
+	return int(atomic.LoadInt32(&s.activeJobs)), queued, int(s.maxConcurrent)
+}
+
+// Closing reports whether Shutdown has begun.
+func (s *Supervisor) Closing() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.closing
+}
+
 func (s *Supervisor) tryReserve() bool {
 	for {
 		n := atomic.LoadInt32(&s.activeJobs)
@@ -59,113 +333,385 @@ func (s *Supervisor) tryReserve() bool {
 		}
 	}
 }
-func (s *Supervisor) release() { atomic.AddInt32(&s.activeJobs, -1) }
+
+// release frees a job slot. If jobs are queued, the slot is handed straight
+// to the oldest one instead of being returned to the pool.
+func (s *Supervisor) release() {
+	s.mu.Lock()
+	next := s.dequeue()
+	if next == nil {
+		atomic.AddInt32(&s.activeJobs, -1)
+	}
+	s.mu.Unlock()
+	if next != nil {
+		go s.startQueued(next)
+	}
+}
 
 // API/Core methods
 func (s *Supervisor) TryStartJob(req interface{}) (interface{}, int, error) {
 	spec := req.(interface{ ToSpec() JobSpec }).ToSpec()
-	if !s.tryReserve() {
-		return nil, 429, ErrConcurrencyLimit
+	return s.submit(spec, true)
+}
+
+// problems checks spec on its own and against what the collector can
+// measure. Unlike the mirror and attach preflights this looks at nothing
+// on the system, so submit runs it too.
+func (s *Supervisor) problems(spec JobSpec) []Problem {
+	ps := spec.problems(s.maxDuration)
+	if pf, ok := s.col.(preflighter); ok {
+		ps = append(ps, pf.Preflight(spec)...)
+	}
+	return ps
+}
+
+// submit admits a job for spec. When every slot is busy the job is queued
+// if allowQueue is set and the queue is enabled, and rejected otherwise.
+func (s *Supervisor) submit(spec JobSpec, allowQueue bool) (interface{}, int, error) {
+	if ps := s.problems(spec); len(ps) > 0 {
+		return nil, 400, &InvalidJobError{Problems: ps}
+	}
+	members, err := s.resolvePorts(spec)
+	if err != nil {
+		return nil, 400, err
 	}
 	id := uuid.NewString()
+	j := &Job{ID: id, Spec: spec, CreatedAt: time.Now(), Members: members}
 
-	j := &Job{
-		ID:        id,
-		Spec:      spec,
-		State:     JobStarting,
-		StartedAt: time.Now(),
-		ExpiresAt: time.Now().Add(spec.Duration),
+	// Reservation and enqueueing happen under s.mu so a concurrent release
+	// cannot miss a job that is about to be queued.
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return nil, 503, ErrShuttingDown
 	}
+	if !s.tryReserve() {
+		if victim := s.preemptible(spec.Priority); victim != nil {
+			pos, cancel := s.preempt(j, victim)
+			s.mu.Unlock()
+			if cancel != nil {
+				cancel()
+			}
+			return map[string]interface{}{
+				"job_id": id, "status": string(JobQueued), "queue_position": pos,
+				"preempted_job_id": victim.ID,
+			}, 202, nil
+		}
+		if s.maxQueue == 0 || !allowQueue {
+			s.mu.Unlock()
+			return nil, 429, s.concurrencyLimit()
+		}
+		pos, err := s.enqueue(j)
+		s.mu.Unlock()
+		if err != nil {
+			return nil, 429, err
+		}
+		return map[string]interface{}{"job_id": id, "status": string(JobQueued), "queue_position": pos}, 202, nil
+	}
+	j.State = JobStarting
+	if err := s.portConflict(j); err != nil {
+		// the queue is empty whenever a reservation succeeds, so the slot
+		// goes straight back to the pool
+		atomic.AddInt32(&s.activeJobs, -1)
+		s.mu.Unlock()
+		return nil, 409, err
+	}
+	s.jobs[id] = j
+	s.persist(j)
+	s.publish(j)
+	s.mu.Unlock()
 
-	ifname, mirCleanup, err := s.mir.Create(spec)
-	if err != nil {
+	if err := s.start(j); err != nil {
 		s.release()
+		switch {
+		case errors.Is(err, ErrShuttingDown):
+			return nil, 503, err
+		case errors.Is(err, ErrPortConflict):
+			return nil, 409, err
+		}
 		return nil, 500, err
 	}
-	j.IfName = ifname
+	s.mu.RLock()
+	ifname := j.IfName
+	s.mu.RUnlock()
+	return map[string]interface{}{"job_id": id, "status": string(JobStarting), "interface": ifname}, 201, nil
+}
 
-	attCleanup, err := s.att.Attach(ifname, spec)
+// start provisions the mirror and tc attachment for j, which is in the
+// starting state and holds a reserved slot, then launches the job goroutine.
+// On error j is failed and the caller releases the slot.
+func (s *Supervisor) start(j *Job) error {
+	now := time.Now()
+	// The deadline is a timer rather than a context deadline so UpdateJob
+	// can move it.
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s.mu.Lock()
+	if s.closing {
+		cancel()
+		j.FailureReason = "agent shut down before the job started"
+		_ = s.transition(j, JobFailed)
+		s.mu.Unlock()
+		return ErrShuttingDown
+	}
+	// Shutdown waits for every job that got this far
+	s.wg.Add(1)
+	spec, members := j.Spec, j.Members
+	j.StartedAt = now
+	j.ExpiresAt = now.Add(spec.Duration)
+	j.cancel = cancel
+	j.deadline = time.AfterFunc(spec.Duration, cancel)
+	if j.State == JobStopping {
+		// stopped between dequeue and provisioning; run() tears down at once
+		cancel()
+	}
+	s.persist(j)
+	s.mu.Unlock()
+
+	provisioned, step, err := s.provision(j, spec, members)
 	if err != nil {
-		_ = mirCleanup()
-		s.release()
-		return nil, 500, err
+		cancel()
+		s.mu.Lock()
+		j.deadline.Stop()
+		s.fail(j, step, err)
+		s.mu.Unlock()
+		s.wg.Done()
+		return err
 	}
 
-	ctx, cancel := context.WithDeadline(context.Background(), j.ExpiresAt)
-	j.cancel = cancel
+	s.mu.Lock()
+	j.Members = provisioned
+	j.IfName = provisioned[0].IfName
+	s.persist(j)
+	s.mu.Unlock()
+
+	go s.run(ctx, j)
+	return nil
+}
+
+// run drives a provisioned job through running and stopping to done, or to
+// failed if collection or teardown went wrong.
+func (s *Supervisor) run(ctx context.Context, j *Job) {
+	defer s.wg.Done()
+	defer s.release()
 
-	// store the job under lock
 	s.mu.Lock()
-	s.jobs[id] = j
+	// a stop that arrived while provisioning leaves the job stopping
+	if j.State == JobStarting {
+		_ = s.transition(j, JobRunning)
+	}
+	// UpdateJob may replace j.Spec while the collector starts
+	ifnames, members, spec := j.interfaces(), j.Members, j.Spec
 	s.mu.Unlock()
 
-	go func() {
-		defer s.release()
-		defer attCleanup()
-		defer mirCleanup()
+	rp, err := s.col.Run(ctx, j.ID, ifnames, spec)
+	s.mu.Lock()
+	j.results = rp
+	if err != nil {
+		j.stepError(StepCollect, err)
+		j.cancel()
+	}
+	s.mu.Unlock()
 
-		// mark running under lock
+	var early <-chan struct{}
+	es, _ := rp.(earlyStopper)
+	if es != nil {
+		early = es.StopC()
+	}
+	select {
+	case <-ctx.Done():
+	case <-early:
 		s.mu.Lock()
-		if jj, ok := s.jobs[id]; ok {
-			jj.State = JobRunning
+		if j.StopReason == "" {
+			j.StopReason = es.StopReason()
 		}
 		s.mu.Unlock()
+		j.cancel()
+	}
 
-		// run collector (ignore returned provider for now)
-		_, _ = s.col.Run(ctx, id, spec)
+	s.mu.Lock()
+	j.deadline.Stop()
+	if j.StopReason == "" && len(j.StepErrors) == 0 {
+		j.StopReason = StopExpired
+	}
+	if j.State == JobRunning {
+		_ = s.transition(j, JobStopping)
+	}
+	s.mu.Unlock()
 
-		<-ctx.Done()
+	// stop snapshot must be taken before the mirror/tc teardown
+	if f, ok := rp.(interface{ Finalize() }); ok {
+		f.Finalize()
+	}
 
-		// mark done under lock
-		s.mu.Lock()
-		if jj, ok := s.jobs[id]; ok {
-			jj.State = JobDone
-		}
-		s.mu.Unlock()
-	}()
+	s.unprovision(j, members)
 
-	return map[string]interface{}{"job_id": id, "status": "starting", "interface": ifname}, 201, nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	final := JobDone
+	if len(j.StepErrors) > 0 {
+		final = JobFailed
+	}
+	if err := s.transition(j, final); err != nil {
+		log.Printf("job %s: %v", j.ID, err)
+	}
 }
 
 func (s *Supervisor) GetJob(id string) (interface{}, int, error) {
 	s.mu.RLock()
+	defer s.mu.RUnlock()
 	j, ok := s.jobs[id]
 	if !ok {
-		s.mu.RUnlock()
 		return nil, 404, ErrJobNotFound
 	}
-	// copy out the fields we need while holding the read lock
+	return s.jobStatus(j), 200, nil
+}
+
+// jobStatus copies out the fields the API reports; callers hold s.mu.
+func (s *Supervisor) jobStatus(j *Job) map[string]interface{} {
 	resp := map[string]interface{}{
 		"job_id":     j.ID,
-		"status":     j.State,
+		"status":     string(j.State),
+		"created_at": j.CreatedAt,
 		"started_at": j.StartedAt,
 		"expires_at": j.ExpiresAt,
 		"port":       j.Spec.Port,
 		"interface":  j.IfName,
 	}
-	s.mu.RUnlock()
-	return resp, 200, nil
+	if len(j.Spec.Ports) > 0 {
+		resp["ports"] = append([]string(nil), j.Spec.Ports...)
+	}
+	if j.expanded() {
+		resp["members"] = append([]JobMember(nil), j.Members...)
+	}
+	if j.Spec.SampleRate > 0 {
+		resp["sample_rate"] = j.Spec.SampleRate
+	}
+	if len(j.Spec.Filters) > 0 {
+		resp["filters"] = j.Spec.Filters
+	}
+	if len(j.Spec.Labels) > 0 {
+		labels := make(map[string]string, len(j.Spec.Labels))
+		for k, v := range j.Spec.Labels {
+			labels[k] = v
+		}
+		resp["labels"] = labels
+	}
+	if j.Spec.Owner != "" {
+		resp["owner"] = j.Spec.Owner
+	}
+	if j.Spec.Description != "" {
+		resp["description"] = j.Spec.Description
+	}
+	if j.Spec.Priority > 0 {
+		resp["priority"] = j.Spec.Priority
+	}
+	if j.PreemptedBy != "" {
+		resp["preempted_by"] = j.PreemptedBy
+	}
+	if j.State == JobQueued {
+		resp["queue_position"] = s.queuePosition(j.ID)
+	}
+	if !j.EndedAt.IsZero() {
+		resp["ended_at"] = j.EndedAt
+	}
+	if j.FailureReason != "" {
+		resp["failure_reason"] = j.FailureReason
+	}
+	if j.StopReason != "" {
+		resp["stop_reason"] = j.StopReason
+	}
+	if len(j.StepErrors) > 0 {
+		errs := make(map[string]string, len(j.StepErrors))
+		for k, v := range j.StepErrors {
+			errs[k] = v
+		}
+		resp["step_errors"] = errs
+	}
+	return resp
 }
 
+// StopJob asks an active job to stop. Teardown happens asynchronously; the
+// job reports "stopping" until it reaches done or failed.
 func (s *Supervisor) StopJob(id string) (interface{}, int, error) {
-	s.mu.RLock()
+	s.mu.Lock()
 	j, ok := s.jobs[id]
-	s.mu.RUnlock()
 	if !ok {
+		s.mu.Unlock()
 		return nil, 404, ErrJobNotFound
 	}
-	if j.cancel != nil {
-		j.cancel()
+	switch j.State {
+	case JobQueued:
+		// never provisioned; cancelling it is the whole stop
+		s.removeQueued(j)
+		j.StopReason = StopRequested
+		_ = s.transition(j, JobDone)
+	case JobStarting, JobRunning:
+		j.StopReason = StopRequested
+		_ = s.transition(j, JobStopping)
+	case JobStopping:
+		// already on its way down
+	default:
+		state := j.State
+		s.mu.Unlock()
+		return nil, 409, fmt.Errorf("%w (state %s)", ErrJobNotActive, state)
+	}
+	cancel, state := j.cancel, j.State
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
 	}
-	return map[string]interface{}{"job_id": j.ID, "status": "stopped"}, 200, nil
+	return map[string]interface{}{"job_id": id, "status": string(state)}, 200, nil
 }
 
 func (s *Supervisor) GetResults(id string) (interface{}, int, error) {
-	// TODO: wire collector summary
-	return map[string]interface{}{
-		"window_sec": 0, "packets_total": 0, "bytes_total": 0,
-		"errors": map[string]uint64{}, "top_flows": []interface{}{},
-		"latency_histogram_ns": map[string]interface{}{"bounds": []uint64{}, "counts": []uint64{}},
-		"otel_export":          map[string]interface{}{"exported": true, "endpoint": ""},
-	}, 200, nil
+	s.mu.RLock()
+	j, ok := s.jobs[id]
+	if !ok {
+		s.mu.RUnlock()
+		return nil, 404, ErrJobNotFound
+	}
+	rp, startedAt, endedAt, otlp, reason := j.results, j.StartedAt, j.EndedAt, j.Spec.OTLPExport, j.StopReason
+	var members []JobMember
+	if j.expanded() {
+		members = append(members, j.Members...)
+	}
+	s.mu.RUnlock()
+
+	resp := map[string]interface{}{
+		"window_sec": 0, "packets_total": uint64(0), "bytes_total": uint64(0),
+		"errors": map[string]uint64{}, "top_flows": []FlowSummary{},
+		"otlp_export": otlp,
+	}
+	if reason != "" {
+		resp["stop_reason"] = reason
+	}
+	if rp == nil {
+		// collector has not reported yet; a queued job has no window and
+		// one that failed before collecting stopped counting when it ended
+		if !startedAt.IsZero() {
+			until := time.Now()
+			if !endedAt.IsZero() {
+				until = endedAt
+			}
+			resp["window_sec"] = int(until.Sub(startedAt).Seconds())
+		}
+		return resp, 200, nil
+	}
+	// Summary may read BPF maps; keep it outside the lock.
+	if sum, ok := rp.Summary().(JobSummary); ok {
+		resp["window_sec"] = int(sum.Window.Seconds())
+		resp["packets_total"] = sum.Packets
+		resp["bytes_total"] = sum.Bytes
+		resp["errors"] = sum.Errors
+		resp["top_flows"] = sum.TopFlows
+		if sum.CPU > 0 {
+			resp["cpu_seconds"] = sum.CPU.Seconds()
+		}
+		if len(members) > 0 {
+			resp["members"] = memberResults(members, sum.Interfaces)
+		}
+	}
+	return resp, 200, nil
 }
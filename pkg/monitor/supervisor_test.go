@@ -5,6 +5,9 @@ package monitor
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -12,10 +15,15 @@ import (
 
 /* ---------- fakes ---------- */
 
+// fakeMirror hands out one interface per source port and direction, named
+// after ifname, so jobs mirroring the same traffic meet on the same lease.
 type fakeMirror struct {
 	ifname string
 	err    error
 	calls  int32
+
+	mu       sync.Mutex
+	tornDown []string
 }
 
 func (f *fakeMirror) Create(spec JobSpec) (string, func() error, error) {
@@ -23,12 +31,38 @@ func (f *fakeMirror) Create(spec JobSpec) (string, func() error, error) {
 	if f.err != nil {
 		return "", func() error { return nil }, f.err
 	}
-	return f.ifname, func() error { return nil }, nil
+	name := f.ifname + "-" + spec.Port
+	if spec.Direction != "" {
+		name += "-" + spec.Direction
+	}
+	return name, func() error { return nil }, nil
+}
+
+func (f *fakeMirror) MirrorName(spec JobSpec) string {
+	return f.ifname + "-" + spec.JobID + "-" + spec.Port
+}
+
+func (f *fakeMirror) Teardown(ifname string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tornDown = append(f.tornDown, ifname)
+	return nil
 }
 
 type fakeAttach struct {
-	err   error
-	calls int32
+	err      error
+	calls    int32
+	cleanups int32
+
+	mu       sync.Mutex
+	detached []string
+}
+
+func (f *fakeAttach) Detach(ifname string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.detached = append(f.detached, ifname)
+	return nil
 }
 
 func (f *fakeAttach) Attach(ifname string, spec JobSpec) (func() error, error) {
@@ -36,26 +70,81 @@ func (f *fakeAttach) Attach(ifname string, spec JobSpec) (func() error, error) {
 	if f.err != nil {
 		return func() error { return nil }, f.err
 	}
-	return func() error { return nil }, nil
+	return func() error { atomic.AddInt32(&f.cleanups, 1); return nil }, nil
 }
 
 type fakeCollector struct {
-	runErr error
-	calls  int32
+	runErr  error
+	calls   int32
+	results ResultsProvider
 }
 
-func (f *fakeCollector) Run(ctx context.Context, jobID string, spec JobSpec) (ResultsProvider, error) {
+func (f *fakeCollector) Run(ctx context.Context, jobID string, ifnames []string, spec JobSpec) (ResultsProvider, error) {
 	atomic.AddInt32(&f.calls, 1)
 	if f.runErr != nil {
 		return nil, f.runErr
 	}
+	if f.results != nil {
+		return f.results, nil
+	}
 	return fakeResults{}, nil
 }
 
+type memStore struct {
+	mu   sync.Mutex
+	recs map[string]JobRecord
+}
+
+func newMemStore(recs ...JobRecord) *memStore {
+	m := &memStore{recs: map[string]JobRecord{}}
+	for _, r := range recs {
+		m.recs[r.ID] = r
+	}
+	return m
+}
+
+func (m *memStore) Save(rec JobRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recs[rec.ID] = rec
+	return nil
+}
+
+func (m *memStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.recs, id)
+	return nil
+}
+
+func (m *memStore) List() ([]JobRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]JobRecord, 0, len(m.recs))
+	for _, r := range m.recs {
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func (m *memStore) get(id string) JobRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.recs[id]
+}
+
 type fakeResults struct{}
 
 func (fakeResults) Summary() interface{} { return map[string]any{} }
 
+type summaryResults struct {
+	sum       JobSummary
+	finalized int32
+}
+
+func (r *summaryResults) Summary() interface{} { return r.sum }
+func (r *summaryResults) Finalize()            { atomic.AddInt32(&r.finalized, 1) }
+
 /* ---------- helpers ---------- */
 
 type startReq struct {
@@ -87,8 +176,8 @@ func TestSupervisor_TryStartGetStop_Success(t *testing.T) {
 		t.Fatalf("GetJob err=%v code=%d", err, code)
 	}
 	jm := jres.(map[string]interface{})
-	if jm["interface"] != "mirror0" {
-		t.Fatalf("expected interface mirror0, got %v", jm["interface"])
+	if jm["interface"] != "mirror0-Ethernet0" {
+		t.Fatalf("expected interface mirror0-Ethernet0, got %v", jm["interface"])
 	}
 
 	// Stop the job explicitly (should cancel the context)
@@ -96,9 +185,10 @@ func TestSupervisor_TryStartGetStop_Success(t *testing.T) {
 	if err != nil || code != 200 {
 		t.Fatalf("StopJob err=%v code=%d", err, code)
 	}
-	if sresp.(map[string]interface{})["status"] != "stopped" {
-		t.Fatalf("expected stopped status")
+	if sresp.(map[string]interface{})["status"] != "stopping" {
+		t.Fatalf("expected stopping status, got %v", sresp)
 	}
+	waitState(t, sup, jobID, JobDone)
 }
 
 func TestSupervisor_ConcurrencyLimit(t *testing.T) {
@@ -122,6 +212,22 @@ func TestSupervisor_ConcurrencyLimit(t *testing.T) {
 	}
 }
 
+func TestSupervisor_ConcurrencyLimitNamesConfiguredSlots(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 3)
+	for _, port := range []string{"Eth0", "Eth1", "Eth2"} {
+		if _, code, err := sup.TryStartJob(startReq{JobSpec{Port: port, Duration: time.Minute}}); code != 201 {
+			t.Fatalf("start %s: code=%d err=%v", port, code, err)
+		}
+	}
+	_, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth3", Duration: time.Minute}})
+	if code != 429 || !errors.Is(err, ErrConcurrencyLimit) {
+		t.Fatalf("expected concurrency limit (429), got code=%d err=%v", code, err)
+	}
+	if !strings.Contains(err.Error(), "at most 3 jobs") {
+		t.Fatalf("error should name the configured limit, got %q", err)
+	}
+}
+
 func TestSupervisor_GetJob_NotFound(t *testing.T) {
 	mir := &fakeMirror{ifname: "mirror0"}
 	att := &fakeAttach{}
@@ -133,3 +239,555 @@ func TestSupervisor_GetJob_NotFound(t *testing.T) {
 		t.Fatalf("expected 404 ErrJobNotFound, got code=%d err=%v", code, err)
 	}
 }
+
+func TestSupervisor_GetResults_FromCollector(t *testing.T) {
+	rp := &summaryResults{sum: JobSummary{
+		Window:   3 * time.Second,
+		Packets:  42,
+		Bytes:    4200,
+		Errors:   map[string]uint64{"flow_read": 1},
+		TopFlows: []FlowSummary{{FiveTuple: "10.0.0.1:443->10.0.0.2:5000/TCP", Packets: 40, Bytes: 4000}},
+	}}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{results: rp}, 2)
+
+	resp, _, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth0", Duration: 100 * time.Millisecond, OTLPExport: true}})
+	if err != nil {
+		t.Fatalf("TryStartJob: %v", err)
+	}
+	id := resp.(map[string]interface{})["job_id"].(string)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(&rp.finalized) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("results were not finalized at job stop")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	res, code, err := sup.GetResults(id)
+	if err != nil || code != 200 {
+		t.Fatalf("GetResults err=%v code=%d", err, code)
+	}
+	m := res.(map[string]interface{})
+	if m["window_sec"] != 3 || m["packets_total"] != uint64(42) || m["bytes_total"] != uint64(4200) {
+		t.Fatalf("unexpected results: %+v", m)
+	}
+	if flows := m["top_flows"].([]FlowSummary); len(flows) != 1 || flows[0].Packets != 40 {
+		t.Fatalf("unexpected top flows: %+v", m["top_flows"])
+	}
+}
+
+func TestSupervisor_GetResults_NotFound(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)
+	if _, code, err := sup.GetResults("nope"); code != 404 || !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected 404 ErrJobNotFound, got code=%d err=%v", code, err)
+	}
+}
+
+func TestSupervisor_PersistsStateChanges(t *testing.T) {
+	st := newMemStore()
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2, WithStore(st))
+
+	resp, _, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth0", Duration: 50 * time.Millisecond}})
+	if err != nil {
+		t.Fatalf("TryStartJob: %v", err)
+	}
+	id := resp.(map[string]interface{})["job_id"].(string)
+	if rec := st.get(id); rec.ID != id || rec.IfName != "mirror0-Eth0" {
+		t.Fatalf("job not persisted at start: %+v", rec)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for st.get(id).State != JobDone {
+		if time.Now().After(deadline) {
+			t.Fatalf("final state not persisted: %+v", st.get(id))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSupervisor_Reconcile_FailsInterruptedAndTearsDown(t *testing.T) {
+	now := time.Now()
+	st := newMemStore(
+		JobRecord{ID: "running", State: JobRunning, IfName: "erspan0", StartedAt: now},
+		JobRecord{ID: "starting", State: JobStarting, IfName: "erspan0", StartedAt: now},
+		JobRecord{ID: "finished", State: JobDone, IfName: "erspan1", StartedAt: now},
+	)
+	mir := &fakeMirror{ifname: "mirror0"}
+	att := &fakeAttach{}
+	sup := NewSupervisor(mir, att, &fakeCollector{}, 2, WithStore(st))
+
+	if err := sup.Reconcile(); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	for _, id := range []string{"running", "starting"} {
+		if got := st.get(id).State; got != JobFailed {
+			t.Fatalf("%s: persisted state=%s want failed", id, got)
+		}
+		jm, code, err := sup.GetJob(id)
+		if err != nil || code != 200 || jm.(map[string]interface{})["status"] != string(JobFailed) {
+			t.Fatalf("%s: GetJob=%v code=%d err=%v", id, jm, code, err)
+		}
+	}
+	if got := st.get("finished").State; got != JobDone {
+		t.Fatalf("terminal job should be untouched, got %s", got)
+	}
+	// erspan0 is shared by both interrupted jobs but must be torn down once;
+	// erspan1 belongs to a finished job and must be left alone.
+	if len(att.detached) != 1 || att.detached[0] != "erspan0" {
+		t.Fatalf("unexpected tc detaches: %v", att.detached)
+	}
+	if len(mir.tornDown) != 1 || mir.tornDown[0] != "erspan0" {
+		t.Fatalf("unexpected mirror teardowns: %v", mir.tornDown)
+	}
+}
+
+func TestSupervisor_Reconcile_TearsDownUnrecordedMirrors(t *testing.T) {
+	// the agent crashed after Ethernet0's mirror was recorded but while
+	// Ethernet4's was being created
+	st := newMemStore(JobRecord{ID: "j1", State: JobStarting, IfName: "erspan0",
+		Spec:    JobSpec{Ports: []string{"Ethernet0", "Ethernet4"}},
+		Members: []JobMember{{Port: "Ethernet0", IfName: "erspan0"}, {Port: "Ethernet4"}}})
+	mir := &fakeMirror{ifname: "mirror0"}
+	att := &fakeAttach{}
+	sup := NewSupervisor(mir, att, &fakeCollector{}, 2, WithStore(st))
+
+	if err := sup.Reconcile(); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	want := []string{"erspan0", "mirror0-j1-Ethernet4"}
+	if fmt.Sprint(mir.tornDown) != fmt.Sprint(want) || fmt.Sprint(att.detached) != fmt.Sprint(want) {
+		t.Fatalf("torn down %v, detached %v; want %v", mir.tornDown, att.detached, want)
+	}
+}
+
+func waitState(t *testing.T, sup *Supervisor, id string, want JobState) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		jm, _, err := sup.GetJob(id)
+		if err == nil && jm.(map[string]interface{})["status"] == string(want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s never reached %s (last=%v err=%v)", id, want, jm, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSupervisor_Queue_StartsWhenSlotFrees(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1, WithQueue(2, time.Minute))
+	spec := JobSpec{Port: "Eth0", Duration: time.Minute}
+
+	first, code, err := sup.TryStartJob(startReq{spec})
+	if err != nil || code != 201 {
+		t.Fatalf("TryStartJob #1 err=%v code=%d", err, code)
+	}
+	second, code, err := sup.TryStartJob(startReq{spec})
+	if err != nil || code != 202 {
+		t.Fatalf("TryStartJob #2 should queue: err=%v code=%d", err, code)
+	}
+	third, code, err := sup.TryStartJob(startReq{spec})
+	if err != nil || code != 202 {
+		t.Fatalf("TryStartJob #3 should queue: err=%v code=%d", err, code)
+	}
+	if pos := third.(map[string]interface{})["queue_position"]; pos != 2 {
+		t.Fatalf("expected queue_position 2, got %v", pos)
+	}
+	if _, code, err := sup.TryStartJob(startReq{spec}); code != 429 || !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected 429 ErrQueueFull, got code=%d err=%v", code, err)
+	}
+
+	secondID := second.(map[string]interface{})["job_id"].(string)
+	thirdID := third.(map[string]interface{})["job_id"].(string)
+	jm, _, _ := sup.GetJob(thirdID)
+	if jm.(map[string]interface{})["queue_position"] != 2 {
+		t.Fatalf("GetJob should report queue position: %v", jm)
+	}
+	if res, _, _ := sup.GetResults(thirdID); res.(map[string]interface{})["window_sec"] != 0 {
+		t.Fatalf("a queued job has no window yet: %v", res)
+	}
+
+	_, _, _ = sup.StopJob(first.(map[string]interface{})["job_id"].(string))
+	waitState(t, sup, secondID, JobRunning)
+
+	jm, _, _ = sup.GetJob(thirdID)
+	if jm.(map[string]interface{})["queue_position"] != 1 {
+		t.Fatalf("third job should have moved up: %v", jm)
+	}
+	_, _, _ = sup.StopJob(secondID)
+	_, _, _ = sup.StopJob(thirdID)
+}
+
+func TestSupervisor_Queue_TTLExpiry(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1, WithQueue(1, 50*time.Millisecond))
+	spec := JobSpec{Port: "Eth0", Duration: time.Minute}
+
+	first, _, _ := sup.TryStartJob(startReq{spec})
+	queued, code, err := sup.TryStartJob(startReq{spec})
+	if err != nil || code != 202 {
+		t.Fatalf("expected queued job, got code=%d err=%v", code, err)
+	}
+	qid := queued.(map[string]interface{})["job_id"].(string)
+	waitState(t, sup, qid, JobFailed)
+
+	// the expired entry must not be started when the slot frees up
+	_, _, _ = sup.StopJob(first.(map[string]interface{})["job_id"].(string))
+	time.Sleep(50 * time.Millisecond)
+	waitState(t, sup, qid, JobFailed)
+}
+
+func TestSupervisor_Queue_StopWhileQueued(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1, WithQueue(1, time.Minute))
+	spec := JobSpec{Port: "Eth0", Duration: time.Minute}
+
+	first, _, _ := sup.TryStartJob(startReq{spec})
+	queued, _, _ := sup.TryStartJob(startReq{spec})
+	qid := queued.(map[string]interface{})["job_id"].(string)
+
+	if _, code, err := sup.StopJob(qid); err != nil || code != 200 {
+		t.Fatalf("StopJob queued err=%v code=%d", err, code)
+	}
+	waitState(t, sup, qid, JobDone)
+
+	// queue slot is free again
+	if _, code, err := sup.TryStartJob(startReq{spec}); err != nil || code != 202 {
+		t.Fatalf("expected re-queue after stop, got code=%d err=%v", code, err)
+	}
+	_, _, _ = sup.StopJob(first.(map[string]interface{})["job_id"].(string))
+}
+
+func TestJobState_Transitions(t *testing.T) {
+	legal := [][2]JobState{
+		{JobQueued, JobStarting}, {JobQueued, JobDone}, {JobQueued, JobFailed},
+		{JobStarting, JobRunning}, {JobStarting, JobStopping}, {JobStarting, JobFailed},
+		{JobRunning, JobStopping}, {JobRunning, JobFailed},
+		{JobStopping, JobDone}, {JobStopping, JobFailed},
+	}
+	for _, tr := range legal {
+		if !tr[0].canTransition(tr[1]) {
+			t.Fatalf("%s -> %s should be legal", tr[0], tr[1])
+		}
+	}
+	illegal := [][2]JobState{
+		{JobRunning, JobDone}, {JobStarting, JobDone}, {JobDone, JobRunning},
+		{JobFailed, JobStopping}, {JobDone, JobFailed}, {JobStopping, JobRunning},
+	}
+	for _, tr := range illegal {
+		if tr[0].canTransition(tr[1]) {
+			t.Fatalf("%s -> %s should be illegal", tr[0], tr[1])
+		}
+	}
+}
+
+func jobField(t *testing.T, sup *Supervisor, id, key string) interface{} {
+	t.Helper()
+	jm, _, err := sup.GetJob(id)
+	if err != nil {
+		t.Fatalf("GetJob %s: %v", id, err)
+	}
+	return jm.(map[string]interface{})[key]
+}
+
+func TestSupervisor_MirrorFailure_RecordsStepError(t *testing.T) {
+	st := newMemStore()
+	sup := NewSupervisor(&fakeMirror{err: errors.New("no erspan")}, &fakeAttach{}, &fakeCollector{}, 1, WithStore(st))
+
+	_, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth0", Duration: time.Minute}})
+	if code != 500 || err == nil {
+		t.Fatalf("expected 500, got code=%d err=%v", code, err)
+	}
+	var id string
+	for _, rec := range st.recs {
+		id = rec.ID
+	}
+	if got := jobField(t, sup, id, "status"); got != string(JobFailed) {
+		t.Fatalf("status=%v want failed", got)
+	}
+	if got := jobField(t, sup, id, "step_errors").(map[string]string); got[StepMirror] != "no erspan" {
+		t.Fatalf("unexpected step errors: %v", got)
+	}
+	if got := jobField(t, sup, id, "failure_reason"); got != "mirror failed: no erspan" {
+		t.Fatalf("unexpected failure reason: %v", got)
+	}
+	// the results window stops growing once the job has ended
+	sup.mu.Lock()
+	j := sup.jobs[id]
+	j.StartedAt, j.EndedAt = time.Now().Add(-time.Hour), time.Now().Add(-time.Hour+5*time.Second)
+	sup.mu.Unlock()
+	if res, _, _ := sup.GetResults(id); res.(map[string]interface{})["window_sec"] != 5 {
+		t.Fatalf("window of a failed job = %v, want 5", res.(map[string]interface{})["window_sec"])
+	}
+	// the slot must have been released
+	if _, code, _ := sup.TryStartJob(startReq{JobSpec{Port: "Eth0", Duration: time.Minute}}); code != 500 {
+		t.Fatalf("slot leaked: code=%d", code)
+	}
+}
+
+func TestSupervisor_CollectFailure_FailsJob(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{runErr: errors.New("maps gone")}, 1)
+
+	resp, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth0", Duration: time.Minute}})
+	if err != nil || code != 201 {
+		t.Fatalf("TryStartJob err=%v code=%d", err, code)
+	}
+	id := resp.(map[string]interface{})["job_id"].(string)
+	waitState(t, sup, id, JobFailed)
+	if got := jobField(t, sup, id, "step_errors").(map[string]string); got[StepCollect] != "maps gone" {
+		t.Fatalf("unexpected step errors: %v", got)
+	}
+	if jobField(t, sup, id, "ended_at") == nil {
+		t.Fatalf("ended_at should be set on a finished job")
+	}
+}
+
+type failingCleanupAttach struct{}
+
+func (failingCleanupAttach) Attach(ifname string, spec JobSpec) (func() error, error) {
+	return func() error { return errors.New("filter busy") }, nil
+}
+
+func TestSupervisor_TeardownFailure_FailsJob(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, failingCleanupAttach{}, &fakeCollector{}, 1)
+
+	resp, _, err := sup.TryStartJob(startReq{JobSpec{Port: "Eth0", Duration: 20 * time.Millisecond}})
+	if err != nil {
+		t.Fatalf("TryStartJob: %v", err)
+	}
+	id := resp.(map[string]interface{})["job_id"].(string)
+	waitState(t, sup, id, JobFailed)
+	if got := jobField(t, sup, id, "step_errors").(map[string]string); got[StepTeardown] != "detach: filter busy" {
+		t.Fatalf("unexpected step errors: %v", got)
+	}
+}
+
+func TestSupervisor_StopFinishedJob_Conflict(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1)
+
+	resp, _, _ := sup.TryStartJob(startReq{JobSpec{Port: "Eth0", Duration: 20 * time.Millisecond}})
+	id := resp.(map[string]interface{})["job_id"].(string)
+	waitState(t, sup, id, JobDone)
+
+	if _, code, err := sup.StopJob(id); code != 409 || !errors.Is(err, ErrJobNotActive) {
+		t.Fatalf("expected 409 ErrJobNotActive, got code=%d err=%v", code, err)
+	}
+}
+
+func startJob(t *testing.T, sup *Supervisor, spec JobSpec) string {
+	t.Helper()
+	resp, code, err := sup.TryStartJob(startReq{spec})
+	if err != nil || code != 201 {
+		t.Fatalf("TryStartJob(%+v) err=%v code=%d", spec, err, code)
+	}
+	return resp.(map[string]interface{})["job_id"].(string)
+}
+
+func TestSupervisor_PortConflict(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 4)
+
+	first := startJob(t, sup, JobSpec{Port: "Ethernet0", Direction: "ingress", Duration: time.Minute})
+
+	for _, dir := range []string{"ingress", "both", ""} {
+		_, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Ethernet0", Direction: dir, Duration: time.Minute}})
+		if code != 409 || !errors.Is(err, ErrPortConflict) {
+			t.Fatalf("direction %q: expected 409 ErrPortConflict, got code=%d err=%v", dir, code, err)
+		}
+	}
+	// non-overlapping direction and other ports are fine
+	egress := startJob(t, sup, JobSpec{Port: "Ethernet0", Direction: "egress", Duration: time.Minute})
+	other := startJob(t, sup, JobSpec{Port: "Ethernet4", Direction: "ingress", Duration: time.Minute})
+
+	// rejected requests must not leak slots: 3 active of 4
+	last := startJob(t, sup, JobSpec{Port: "Ethernet8", Duration: time.Minute})
+	for _, id := range []string{first, egress, other, last} {
+		_, _, _ = sup.StopJob(id)
+	}
+}
+
+func TestSupervisor_PortConflict_ExplicitSharing(t *testing.T) {
+	att := &fakeAttach{}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, att, &fakeCollector{}, 2)
+
+	a := startJob(t, sup, JobSpec{Port: "Ethernet0", Direction: "ingress", AllowShared: true, Duration: time.Minute})
+	// sharing must be requested by both jobs
+	if _, code, _ := sup.TryStartJob(startReq{JobSpec{Port: "Ethernet0", Direction: "ingress", Duration: time.Minute}}); code != 409 {
+		t.Fatalf("expected 409 without allow_shared, got %d", code)
+	}
+	b := startJob(t, sup, JobSpec{Port: "Ethernet0", Direction: "ingress", AllowShared: true, Duration: time.Minute})
+	_, _, _ = sup.StopJob(a)
+	_, _, _ = sup.StopJob(b)
+	waitState(t, sup, a, JobDone)
+	waitState(t, sup, b, JobDone)
+}
+
+func TestSupervisor_SharedInterface_TornDownByLastJob(t *testing.T) {
+	att := &fakeAttach{}
+	sup := NewSupervisor(&fakeMirror{ifname: "erspan0"}, att, &fakeCollector{}, 2)
+
+	a := startJob(t, sup, JobSpec{Port: "Ethernet0", AllowShared: true, Duration: time.Minute})
+	b := startJob(t, sup, JobSpec{Port: "Ethernet0", AllowShared: true, Duration: time.Minute})
+	if n := atomic.LoadInt32(&att.calls); n != 1 {
+		t.Fatalf("tc should be attached once to the shared interface, got %d", n)
+	}
+
+	_, _, _ = sup.StopJob(a)
+	waitState(t, sup, a, JobDone)
+	if n := atomic.LoadInt32(&att.cleanups); n != 0 {
+		t.Fatalf("first job's teardown removed the shared tc filter (%d cleanups)", n)
+	}
+
+	_, _, _ = sup.StopJob(b)
+	waitState(t, sup, b, JobDone)
+	if n := atomic.LoadInt32(&att.cleanups); n != 1 {
+		t.Fatalf("last job should tear the interface down once, got %d", n)
+	}
+}
+
+// fixedMirror hands every job the same interface, whatever it mirrors.
+type fixedMirror struct{ ifname string }
+
+func (m fixedMirror) Create(JobSpec) (string, func() error, error) {
+	return m.ifname, func() error { return nil }, nil
+}
+
+func TestSupervisor_SharedInterface_Conflicts(t *testing.T) {
+	att := &fakeAttach{}
+	sup := NewSupervisor(fixedMirror{"erspan0"}, att, &fakeCollector{}, 4)
+
+	a := startJob(t, sup, JobSpec{Port: "Ethernet0", Direction: "ingress", SampleRate: 10, AllowShared: true,
+		Filters: map[string]interface{}{"l4_dport": 443.0}, Duration: time.Minute})
+
+	for name, spec := range map[string]JobSpec{
+		"other port":   {Port: "Ethernet8", Direction: "ingress", SampleRate: 10, AllowShared: true, Filters: map[string]interface{}{"l4_dport": 443.0}},
+		"not shared":   {Port: "Ethernet0", Direction: "egress", SampleRate: 10, Filters: map[string]interface{}{"l4_dport": 443.0}},
+		"direction":    {Port: "Ethernet0", Direction: "egress", SampleRate: 10, AllowShared: true, Filters: map[string]interface{}{"l4_dport": 443.0}},
+		"sample rate":  {Port: "Ethernet0", Direction: "ingress", SampleRate: 20, AllowShared: true, Filters: map[string]interface{}{"l4_dport": 443.0}},
+		"filters":      {Port: "Ethernet0", Direction: "ingress", SampleRate: 10, AllowShared: true},
+		"other filter": {Port: "Ethernet0", Direction: "ingress", SampleRate: 10, AllowShared: true, Filters: map[string]interface{}{"l4_dport": 80.0}},
+	} {
+		spec.Duration = time.Minute
+		if _, code, err := sup.TryStartJob(startReq{spec}); code != 409 || !errors.Is(err, ErrPortConflict) {
+			t.Fatalf("%s: expected 409 ErrPortConflict, got code=%d err=%v", name, code, err)
+		}
+	}
+	if n := atomic.LoadInt32(&att.calls); n != 1 {
+		t.Fatalf("conflicting jobs must not attach, got %d attaches", n)
+	}
+
+	// the same traffic under the same settings may be shared
+	b := startJob(t, sup, JobSpec{Port: "Ethernet0", Direction: "ingress", SampleRate: 10, AllowShared: true,
+		Filters: map[string]interface{}{"l4_dport": 443.0}, Duration: time.Minute})
+	for _, id := range []string{a, b} {
+		_, _, _ = sup.StopJob(id)
+		waitState(t, sup, id, JobDone)
+	}
+	if active, _, _ := sup.JobCounts(); active != 0 {
+		t.Fatalf("rejected jobs leaked %d slots", active)
+	}
+}
+
+// budgetResults stops its job as soon as trip is closed.
+type budgetResults struct {
+	summaryResults
+	trip chan struct{}
+}
+
+func (r *budgetResults) StopC() <-chan struct{} { return r.trip }
+func (r *budgetResults) StopReason() string     { return StopBudgetExceeded }
+
+func TestSupervisor_StopReasons(t *testing.T) {
+	rp := &budgetResults{trip: make(chan struct{})}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{results: rp}, 3)
+
+	budget := startJob(t, sup, JobSpec{Port: "Ethernet0", Duration: time.Minute, MaxPackets: 10})
+	waitState(t, sup, budget, JobRunning)
+	close(rp.trip)
+	waitState(t, sup, budget, JobDone)
+	if r := jobField(t, sup, budget, "stop_reason"); r != StopBudgetExceeded {
+		t.Fatalf("budget stop: stop_reason=%v", r)
+	}
+	res, _, _ := sup.GetResults(budget)
+	if r := res.(map[string]interface{})["stop_reason"]; r != StopBudgetExceeded {
+		t.Fatalf("results should carry the stop reason, got %v", r)
+	}
+
+	sup2 := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 3)
+	expired := startJob(t, sup2, JobSpec{Port: "Ethernet4", Duration: 50 * time.Millisecond})
+	stopped := startJob(t, sup2, JobSpec{Port: "Ethernet8", Duration: time.Minute})
+	if _, code, _ := sup2.StopJob(stopped); code != 200 {
+		t.Fatalf("StopJob code=%d", code)
+	}
+	waitState(t, sup2, expired, JobDone)
+	waitState(t, sup2, stopped, JobDone)
+	if r := jobField(t, sup2, expired, "stop_reason"); r != StopExpired {
+		t.Fatalf("expired job: stop_reason=%v", r)
+	}
+	if r := jobField(t, sup2, stopped, "stop_reason"); r != StopRequested {
+		t.Fatalf("stopped job: stop_reason=%v", r)
+	}
+}
+
+func TestSupervisor_PublishesLifecycleEvents(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1, WithQueue(1, time.Minute))
+	_, ch, cancel := sup.Events().Subscribe("", 0)
+	defer cancel()
+
+	spec := JobSpec{Port: "Eth0", Duration: time.Minute}
+	first := startJob(t, sup, spec)
+	queued, _, _ := sup.TryStartJob(startReq{spec})
+	second := queued.(map[string]interface{})["job_id"].(string)
+	waitState(t, sup, first, JobRunning)
+	_, _, _ = sup.StopJob(first)
+	waitState(t, sup, second, JobRunning)
+	_, _, _ = sup.StopJob(second)
+	waitState(t, sup, second, JobDone)
+
+	got := map[string][]string{}
+	for len(got[second]) < 5 {
+		e := <-ch
+		s := string(e.State)
+		if e.Reason != "" {
+			s += ":" + e.Reason
+		}
+		got[e.JobID] = append(got[e.JobID], s)
+	}
+	want := "starting running stopping:stopped done:stopped"
+	if s := strings.Join(got[first], " "); s != want {
+		t.Fatalf("first job events = %q, want %q", s, want)
+	}
+	if s := strings.Join(got[second], " "); s != "queued "+want {
+		t.Fatalf("queued job events = %q", s)
+	}
+}
+
+func TestSupervisor_JobMetadata(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)
+
+	id := startJob(t, sup, JobSpec{Port: "Eth0", Duration: time.Minute, Owner: "alice",
+		Description: "INC-1 drops", Labels: map[string]string{"team": "netops", "example.com/site": "dc1"}})
+	if got := jobField(t, sup, id, "owner"); got != "alice" {
+		t.Fatalf("owner = %v", got)
+	}
+	if got := jobField(t, sup, id, "description"); got != "INC-1 drops" {
+		t.Fatalf("description = %v", got)
+	}
+	if got := jobField(t, sup, id, "labels").(map[string]string); got["team"] != "netops" || got["example.com/site"] != "dc1" {
+		t.Fatalf("labels = %v", got)
+	}
+	_, _, _ = sup.StopJob(id)
+
+	for _, spec := range []JobSpec{
+		{Labels: map[string]string{"bad key": "x"}},
+		{Labels: map[string]string{"-lead": "x"}},
+		{Labels: map[string]string{"team": strings.Repeat("x", maxLabelValue+1)}},
+		{Owner: strings.Repeat("o", maxOwner+1)},
+		{Description: strings.Repeat("d", maxDescription+1)},
+	} {
+		spec.Port, spec.Duration = "Eth4", time.Minute
+		if _, code, err := sup.TryStartJob(startReq{spec}); code != 400 || !errors.Is(err, ErrInvalidMetadata) {
+			t.Fatalf("%+v: expected 400 ErrInvalidMetadata, got code=%d err=%v", spec, code, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+//go:build linux
+
+package monitor
+
+import (
+	"fmt"
+	"time"
+)
+
+// UpdateJob changes a queued or running job in place. A running job keeps
+// its mirror session: its deadline timer is moved and a new sample rate or
+// filters are pushed to the data plane through the attach provider.
+func (s *Supervisor) UpdateJob(id string, req interface{}) (interface{}, int, error) {
+	u := req.(interface{ ToUpdate() JobUpdate }).ToUpdate()
+	if u.Duration < 0 || u.SampleRate < 0 {
+		return nil, 400, fmt.Errorf("%w: duration and sample_rate must not be negative", ErrInvalidUpdate)
+	}
+	if s.maxDuration > 0 && u.Duration > s.maxDuration {
+		return nil, 400, fmt.Errorf("%w (%s)", ErrDurationTooLong, s.maxDuration)
+	}
+
+	// leaseMu keeps other jobs from joining or releasing the interface
+	// while its data plane is reprogrammed, and this job's own teardown
+	// from removing it. s.mu is only held around the job's fields, not
+	// while the kernel is being talked to.
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return nil, 404, ErrJobNotFound
+	}
+	if j.State != JobQueued && j.State != JobRunning {
+		s.mu.Unlock()
+		return nil, 409, fmt.Errorf("%w (state %s)", ErrJobNotUpdatable, j.State)
+	}
+
+	state, spec := j.State, j.Spec
+	if u.Duration > 0 {
+		spec.Duration = u.Duration
+	}
+	if u.SampleRate > 0 {
+		spec.SampleRate = u.SampleRate
+	}
+	if u.Filters != nil {
+		spec.Filters = u.Filters
+	}
+	ac, err := attachConfigOf(spec)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, 400, fmt.Errorf("%w: %v", ErrInvalidUpdate, err)
+	}
+
+	var ifnames []string
+	if j.State == JobRunning && (spec.SampleRate != j.Spec.SampleRate || u.Filters != nil) {
+		ifnames = j.interfaces()
+		for _, ifname := range ifnames {
+			// members of this job may share the interface, other jobs may not
+			if l := s.leases[ifname]; l != nil && l.refs > j.leasesOn(ifname) {
+				s.mu.Unlock()
+				return nil, 409, fmt.Errorf("%w: sample_rate and filters of %s cannot be changed", ErrSharedInterface, ifname)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if len(ifnames) > 0 {
+		up, ok := s.att.(attachUpdate)
+		if !ok {
+			return nil, 501, fmt.Errorf("%w: the data plane does not support live updates", ErrInvalidUpdate)
+		}
+		for _, ifname := range ifnames {
+			if err := up.Update(ifname, spec); err != nil {
+				return nil, 500, err
+			}
+			if l := s.leases[ifname]; l != nil {
+				l.attach.cfg = ac.cfg
+			}
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j.State != state {
+		// started or stopped meanwhile, with the spec it had
+		return nil, 409, fmt.Errorf("%w (state %s)", ErrJobNotUpdatable, j.State)
+	}
+	if j.State == JobRunning && spec.Duration != j.Spec.Duration {
+		j.ExpiresAt = j.StartedAt.Add(spec.Duration)
+		// an expiry already in the past stops the job right away
+		j.deadline.Reset(time.Until(j.ExpiresAt))
+	}
+	j.Spec = spec
+	s.persist(j)
+	return s.jobStatus(j), 200, nil
+}
@@ -0,0 +1,218 @@
+//go:build linux
+
+package monitor
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// updatingAttach is a fakeAttach whose data plane can be reprogrammed live.
+type updatingAttach struct {
+	fakeAttach
+
+	mu      sync.Mutex
+	updates []JobSpec
+}
+
+func (u *updatingAttach) Update(ifname string, spec JobSpec) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.updates = append(u.updates, spec)
+	return nil
+}
+
+type updateReq JobUpdate
+
+func (u updateReq) ToUpdate() JobUpdate { return JobUpdate(u) }
+
+func TestSupervisor_UpdateJob_MovesDeadline(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2)
+	id := startJob(t, sup, JobSpec{Port: "Ethernet0", Duration: 200 * time.Millisecond})
+	waitState(t, sup, id, JobRunning)
+	before := jobField(t, sup, id, "expires_at").(time.Time)
+
+	resp, code, err := sup.UpdateJob(id, updateReq{Duration: time.Minute})
+	if err != nil || code != 200 {
+		t.Fatalf("UpdateJob err=%v code=%d", err, code)
+	}
+	after := resp.(map[string]interface{})["expires_at"].(time.Time)
+	if got := after.Sub(before); got < 59*time.Second {
+		t.Fatalf("expires_at moved by %s, want ~1m", got)
+	}
+
+	// the original deadline must no longer fire
+	time.Sleep(400 * time.Millisecond)
+	if st := jobField(t, sup, id, "status"); st != string(JobRunning) {
+		t.Fatalf("job should still be running after its old deadline, got %v", st)
+	}
+
+	// shortening below the elapsed time stops the job right away
+	if _, code, err := sup.UpdateJob(id, updateReq{Duration: time.Millisecond}); err != nil || code != 200 {
+		t.Fatalf("UpdateJob err=%v code=%d", err, code)
+	}
+	waitState(t, sup, id, JobDone)
+}
+
+func TestSupervisor_UpdateJob_PushesDataPlane(t *testing.T) {
+	att := &updatingAttach{}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, att, &fakeCollector{}, 2)
+	id := startJob(t, sup, JobSpec{Port: "Ethernet0", SampleRate: 100, Duration: time.Minute})
+	waitState(t, sup, id, JobRunning)
+
+	filters := map[string]interface{}{"ip_proto": []interface{}{"tcp"}}
+	resp, code, err := sup.UpdateJob(id, updateReq{SampleRate: 10, Filters: filters})
+	if err != nil || code != 200 {
+		t.Fatalf("UpdateJob err=%v code=%d", err, code)
+	}
+	if sr := resp.(map[string]interface{})["sample_rate"]; sr != 10 {
+		t.Fatalf("status should report the new sample rate, got %v", sr)
+	}
+	att.mu.Lock()
+	if len(att.updates) != 1 || att.updates[0].SampleRate != 10 || att.updates[0].Filters == nil {
+		t.Fatalf("expected one data-plane update with the new spec, got %+v", att.updates)
+	}
+	att.mu.Unlock()
+	if n := atomic.LoadInt32(&att.calls); n != 1 {
+		t.Fatalf("update must not re-attach, Attach called %d times", n)
+	}
+	// jobs joining the interface later are checked against the new settings
+	sup.leaseMu.Lock()
+	cfg := sup.leases["mirror0-Ethernet0"].attach.cfg
+	sup.leaseMu.Unlock()
+	if cfg.SampleRate != 10 || cfg.ProtoMask != protoBitTCP {
+		t.Fatalf("lease still holds the old settings: %+v", cfg)
+	}
+
+	// a duration-only change never touches the data plane
+	if _, code, _ := sup.UpdateJob(id, updateReq{Duration: 2 * time.Minute}); code != 200 {
+		t.Fatalf("duration update code=%d", code)
+	}
+	att.mu.Lock()
+	defer att.mu.Unlock()
+	if len(att.updates) != 1 {
+		t.Fatalf("duration update should not push to the data plane")
+	}
+}
+
+func TestSupervisor_UpdateJob_Rejects(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 2,
+		WithMaxDuration(10*time.Minute))
+	id := startJob(t, sup, JobSpec{Port: "Ethernet0", Duration: time.Minute})
+	waitState(t, sup, id, JobRunning)
+
+	if _, code, err := sup.UpdateJob("nope", updateReq{Duration: time.Minute}); code != 404 || !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("unknown job: code=%d err=%v", code, err)
+	}
+	if _, code, err := sup.UpdateJob(id, updateReq{Duration: time.Hour}); code != 400 || !errors.Is(err, ErrDurationTooLong) {
+		t.Fatalf("over max: code=%d err=%v", code, err)
+	}
+	bad := map[string]interface{}{"l4_dport": []interface{}{70000.0}}
+	if _, code, err := sup.UpdateJob(id, updateReq{Filters: bad}); code != 400 || !errors.Is(err, ErrInvalidUpdate) {
+		t.Fatalf("bad filters: code=%d err=%v", code, err)
+	}
+	// fakeAttach cannot reprogram a live job
+	if _, code, _ := sup.UpdateJob(id, updateReq{SampleRate: 5}); code != 501 {
+		t.Fatalf("expected 501 without live update support, got %d", code)
+	}
+
+	if _, code, _ := sup.StopJob(id); code != 200 {
+		t.Fatalf("StopJob code=%d", code)
+	}
+	waitState(t, sup, id, JobDone)
+	if _, code, err := sup.UpdateJob(id, updateReq{Duration: time.Minute}); code != 409 || !errors.Is(err, ErrJobNotUpdatable) {
+		t.Fatalf("finished job: code=%d err=%v", code, err)
+	}
+
+	// starting over the cap is refused as well
+	if _, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Ethernet4", Duration: time.Hour}}); code != 400 || !errors.Is(err, ErrDurationTooLong) {
+		t.Fatalf("start over max: code=%d err=%v", code, err)
+	}
+}
+
+func TestSupervisor_UpdateJob_SharedInterface(t *testing.T) {
+	att := &updatingAttach{}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, att, &fakeCollector{}, 2)
+	spec := JobSpec{Port: "Ethernet0", Duration: time.Minute, AllowShared: true}
+	a := startJob(t, sup, spec)
+	b := startJob(t, sup, spec)
+	waitState(t, sup, a, JobRunning)
+	waitState(t, sup, b, JobRunning)
+
+	if _, code, err := sup.UpdateJob(a, updateReq{SampleRate: 1}); code != 409 || !errors.Is(err, ErrSharedInterface) {
+		t.Fatalf("expected 409 ErrSharedInterface, got code=%d err=%v", code, err)
+	}
+	// extending one sharer is still fine
+	if _, code, err := sup.UpdateJob(a, updateReq{Duration: 2 * time.Minute}); code != 200 {
+		t.Fatalf("extend shared job: code=%d err=%v", code, err)
+	}
+}
+
+func TestSupervisor_UpdateJob_Queued(t *testing.T) {
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, &fakeAttach{}, &fakeCollector{}, 1,
+		WithQueue(1, time.Minute))
+	startJob(t, sup, JobSpec{Port: "Ethernet0", Duration: time.Minute})
+	resp, code, err := sup.TryStartJob(startReq{JobSpec{Port: "Ethernet4", Duration: time.Minute}})
+	if err != nil || code != 202 {
+		t.Fatalf("expected queued job, got code=%d err=%v", code, err)
+	}
+	id := resp.(map[string]interface{})["job_id"].(string)
+
+	if _, code, err := sup.UpdateJob(id, updateReq{Duration: 5 * time.Minute, SampleRate: 7}); err != nil || code != 200 {
+		t.Fatalf("UpdateJob err=%v code=%d", err, code)
+	}
+	sup.mu.RLock()
+	defer sup.mu.RUnlock()
+	if sp := sup.jobs[id].Spec; sp.Duration != 5*time.Minute || sp.SampleRate != 7 {
+		t.Fatalf("queued spec not updated: %+v", sp)
+	}
+}
+
+// slowUpdateAttach holds Update until release is closed.
+type slowUpdateAttach struct {
+	fakeAttach
+	entered, release chan struct{}
+}
+
+func (a *slowUpdateAttach) Update(ifname string, spec JobSpec) error {
+	close(a.entered)
+	<-a.release
+	return nil
+}
+
+func TestSupervisor_UpdateJob_DataPlaneOutsideJobLock(t *testing.T) {
+	att := &slowUpdateAttach{entered: make(chan struct{}), release: make(chan struct{})}
+	sup := NewSupervisor(&fakeMirror{ifname: "mirror0"}, att, &fakeCollector{}, 2)
+	id := startJob(t, sup, JobSpec{Port: "Ethernet0", SampleRate: 100, Duration: time.Minute})
+	waitState(t, sup, id, JobRunning)
+
+	done := make(chan int)
+	go func() {
+		_, code, _ := sup.UpdateJob(id, updateReq{SampleRate: 10})
+		done <- code
+	}()
+	<-att.entered
+	// reads of the job must not wait for the kernel
+	got := make(chan struct{})
+	go func() {
+		_, _, _ = sup.GetJob(id)
+		close(got)
+	}()
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("GetJob blocked while the data plane was being updated")
+	}
+	close(att.release)
+	if code := <-done; code != 200 {
+		t.Fatalf("UpdateJob code=%d", code)
+	}
+	if sr := jobField(t, sup, id, "sample_rate"); sr != 10 {
+		t.Fatalf("sample_rate = %v", sr)
+	}
+	_, _, _ = sup.StopJob(id)
+	waitState(t, sup, id, JobDone)
+}
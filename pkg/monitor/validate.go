@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+)
+
+// Problem is one reason a job request would be rejected, or would not
+// monitor what it asks for. Field names the request field at fault, using
+// the API's JSON names, and is empty for problems with the agent itself.
+type Problem struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	err error // what submit returns for it
+}
+
+// Problem codes.
+const (
+	ProblemRequired     = "required"              // a mandatory field is missing
+	ProblemInvalid      = "invalid"               // a field has a value the agent does not accept
+	ProblemPortNotFound = "port_not_found"        // no such interface on this switch
+	ProblemMirrorConfig = "mirror_not_configured" // ERSPAN settings are missing
+	ProblemBPFObject    = "bpf_object_missing"    // the tc program cannot be loaded
+	ProblemToolMissing  = "tool_missing"          // ip or tc is not installed
+	ProblemPortConflict = "port_conflict"         // another job is monitoring the port
+	ProblemNoCapacity   = "no_capacity"           // every slot and queue place is taken
+	ProblemShuttingDown = "shutting_down"         // the agent no longer accepts jobs
+)
+
+// preflighter is implemented by providers that can tell, without creating
+// anything, why Create or Attach would fail for spec.
+type preflighter interface {
+	Preflight(spec JobSpec) []Problem
+}
+
+// InvalidJobError is what submit returns for a spec that fails its own
+// checks. It lists every problem found and unwraps to the first one's
+// error.
+type InvalidJobError struct {
+	Problems []Problem
+}
+
+func (e *InvalidJobError) Error() string { return e.Problems[0].err.Error() }
+func (e *InvalidJobError) Unwrap() error { return e.Problems[0].err }
+
+func newProblem(field, code string, err error) Problem {
+	return Problem{Field: field, Code: code, Message: err.Error(), err: err}
+}
+
+// missingTool reports a command a provider shells out to but cannot find.
+func missingTool(name string) Problem {
+	return newProblem("", ProblemToolMissing, fmt.Errorf("%s is not installed or not on PATH", name))
+}
+
+// problems runs every check that needs nothing but the spec itself, in the
+// order submit applies them.
+func (s JobSpec) problems(maxDuration time.Duration) []Problem {
+	var ps []Problem
+	switch {
+	case s.Port == "" && len(s.Ports) == 0:
+		ps = append(ps, newProblem("port", ProblemRequired, fmt.Errorf("%w: port or ports is required", ErrInvalidJob)))
+	case s.Port != "" && len(s.Ports) > 0:
+		ps = append(ps, newProblem("ports", ProblemInvalid, fmt.Errorf("%w: set either port or ports, not both", ErrInvalidJob)))
+	case len(s.Ports) > maxJobPorts:
+		ps = append(ps, newProblem("ports", ProblemInvalid, fmt.Errorf("%w: at most %d ports are allowed", ErrInvalidJob, maxJobPorts)))
+	}
+	seen := map[string]bool{}
+	for _, p := range s.Ports {
+		if p == "" || seen[p] {
+			ps = append(ps, newProblem("ports", ProblemInvalid, fmt.Errorf("%w: ports must be distinct and non-empty", ErrInvalidJob)))
+			break
+		}
+		seen[p] = true
+	}
+	switch s.Direction {
+	case "", "ingress", "egress", "both":
+	default:
+		ps = append(ps, newProblem("direction", ProblemInvalid,
+			fmt.Errorf("%w: direction must be ingress, egress or both", ErrInvalidJob)))
+	}
+	switch s.SpanMethod {
+	case "", "span", "erspan":
+	default:
+		ps = append(ps, newProblem("span_method", ProblemInvalid,
+			fmt.Errorf("%w: span_method must be span or erspan", ErrInvalidJob)))
+	}
+	switch {
+	case s.Duration <= 0:
+		ps = append(ps, newProblem("duration_sec", ProblemRequired, fmt.Errorf("%w: duration must be positive", ErrInvalidJob)))
+	case maxDuration > 0 && s.Duration > maxDuration:
+		ps = append(ps, newProblem("duration_sec", ProblemInvalid, fmt.Errorf("%w (%s)", ErrDurationTooLong, maxDuration)))
+	}
+	if s.SampleRate < 0 {
+		ps = append(ps, newProblem("sample_rate", ProblemInvalid, fmt.Errorf("%w: sample_rate must not be negative", ErrInvalidJob)))
+	}
+	if _, err := ifConfigFromSpec(s); err != nil {
+		ps = append(ps, newProblem("filters", ProblemInvalid, fmt.Errorf("%w: %v", ErrInvalidJob, err)))
+	}
+	if err := s.Notify.validate(); err != nil {
+		ps = append(ps, newProblem("notify.webhook_url", ProblemInvalid, err))
+	}
+	if field, err := s.validateMetadata(); err != nil {
+		ps = append(ps, newProblem(field, ProblemInvalid, err))
+	}
+	if s.Priority < 0 || s.Priority > MaxPriority {
+		ps = append(ps, newProblem("priority", ProblemInvalid,
+			fmt.Errorf("%w: priority must be 0-%d", ErrInvalidPriority, MaxPriority)))
+	}
+	return ps
+}